@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// catalog is a parsed .po or .mo file
+type catalog struct {
+	path   string
+	domain *gotext.Domain
+}
+
+// findCatalogs returns the .po and .mo files in paths, descending into directories.
+// Hidden directories are skipped.
+func findCatalogs(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && d.Name()[0] == '.' {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if isCatalog(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func isCatalog(path string) bool {
+	switch filepath.Ext(path) {
+	case ".po", ".mo":
+		return true
+	}
+	return false
+}
+
+// loadCatalog parses the .po or .mo file at path. Along with an error, the catalog holds the entries
+// that could be parsed, if any.
+func loadCatalog(path string) (catalog, error) {
+	c := catalog{path: path, domain: gotext.NewDomain()}
+	tr, err := gotext.ParseCatalogFile(path)
+	if tr != nil {
+		c.domain = tr.GetDomain()
+	}
+	if err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// lintFinding is a finding of a catalog as printed in JSON
+type lintFinding struct {
+	File string `json:"file"`
+	gotext.LintFinding
+}
+
+// runLint lints every catalog found in args and exits with status 1 if a finding reaches the -fail-on severity.
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	failOn := flags.String("fail-on", "error", "lowest severity making the command fail: info, warning, error or none")
+	minSeverity := flags.String("severity", "info", "lowest severity reported: info, warning or error")
+	disable := flags.String("disable", "", "comma separated list of rules not to report")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: gotext lint [flags] [paths]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Checks the .po and .mo files in paths, or in the current directory, for translation mistakes.")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "gotext lint: unknown format %q\n", *format)
+		return 2
+	}
+	report, err := gotext.ParseSeverity(*minSeverity)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gotext lint: %v\n", err)
+		return 2
+	}
+	failSeverity := gotext.SeverityError + 1
+	if *failOn != "none" {
+		if failSeverity, err = gotext.ParseSeverity(*failOn); err != nil {
+			fmt.Fprintf(os.Stderr, "gotext lint: %v\n", err)
+			return 2
+		}
+	}
+	disabled := make(map[string]bool)
+	for _, rule := range strings.Split(*disable, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			disabled[rule] = true
+		}
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := findCatalogs(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	status := 0
+	findings := []lintFinding{}
+	for _, f := range files {
+		// Catalogs that can't be parsed fail whatever -fail-on says, the entries parsed are still checked
+		c, err := loadCatalog(f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gotext lint: %v\n", err)
+			status = 1
+		}
+		for _, finding := range c.domain.Lint() {
+			if disabled[finding.Rule] || finding.Severity < report {
+				continue
+			}
+			if finding.Severity >= failSeverity {
+				status = 1
+			}
+			findings = append(findings, lintFinding{File: f, LintFinding: finding})
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return status
+	}
+
+	for _, f := range findings {
+		if f.Line > 0 {
+			fmt.Printf("%s:%s\n", f.File, f.LintFinding)
+		} else {
+			fmt.Printf("%s: %s\n", f.File, f.LintFinding)
+		}
+	}
+	return status
+}
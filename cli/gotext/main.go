@@ -0,0 +1,64 @@
+// Command gotext provides maintenance tools for translation catalogs.
+//
+// Usage:
+//
+//	gotext <command> [flags] [paths]
+//
+// Commands:
+//
+//	lint       check .po and .mo files for translation mistakes
+//	plurals    check the Plural-Forms headers of .po and .mo files
+//	stats      print how complete the catalogs of a library directory are
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// command is a gotext subcommand. run returns the process exit status.
+type command struct {
+	name  string
+	short string
+	run   func(args []string) int
+}
+
+var commands = []command{
+	{"lint", "check .po and .mo files for translation mistakes", runLint},
+	{"plurals", "check the Plural-Forms headers of .po and .mo files", runPlurals},
+	{"stats", "print how complete the catalogs of a library directory are", runStats},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: gotext <command> [flags] [paths]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.short)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'gotext <command> -h' for the flags of a command.")
+}
+
+func main() {
+	// Init logger
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			os.Exit(c.run(os.Args[2:]))
+		}
+	}
+
+	if os.Args[1] != "-h" && os.Args[1] != "help" {
+		fmt.Fprintf(os.Stderr, "gotext: unknown command %q\n\n", os.Args[1])
+	}
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/leonelquinteros/gotext/plurals"
+)
+
+// runPlurals checks the Plural-Forms header of every catalog found in args.
+// Errors make the header unusable, or its expression produce wrong forms; warnings don't affect the exit status.
+func runPlurals(args []string) int {
+	flags := flag.NewFlagSet("plurals", flag.ExitOnError)
+	verbose := flags.Bool("v", false, "print the normalized rule of every catalog")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: gotext plurals [-v] [paths]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Checks the Plural-Forms headers of the .po and .mo files in paths, or in the current directory.")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := findCatalogs(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	status := 0
+	for _, f := range files {
+		c, err := loadCatalog(f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gotext plurals: %v\n", err)
+			status = 1
+			continue
+		}
+		if !checkPlurals(c, *verbose) {
+			status = 1
+		}
+	}
+	return status
+}
+
+// checkPlurals reports the problems of the Plural-Forms header of c, and returns false if there are errors
+func checkPlurals(c catalog, verbose bool) bool {
+	lang := c.domain.Language
+	standard, hasStandard := plurals.ForLanguage(lang)
+
+	if c.domain.PluralForms == "" {
+		if hasStandard {
+			fmt.Printf("%s: warning: no Plural-Forms header, the built-in rule for %q is used: %s\n", c.path, lang, standard)
+			return true
+		}
+		if lang == "" {
+			fmt.Printf("%s: warning: no Plural-Forms or Language header, English plurals are used\n", c.path)
+			return true
+		}
+		fmt.Printf("%s: warning: no Plural-Forms header and no built-in rule for language %q, English plurals are used\n", c.path, lang)
+		return true
+	}
+
+	rule, err := plurals.ParseRule(c.domain.PluralForms)
+	if err != nil {
+		fmt.Printf("%s: error: %v\n", c.path, err)
+		return false
+	}
+	expr, err := rule.Compile()
+	if err != nil {
+		fmt.Printf("%s: error: %v\n", c.path, err)
+		return false
+	}
+	if verbose {
+		fmt.Printf("%s: nplurals=%d; plural=%s;\n", c.path, rule.NPlurals, expr)
+	}
+
+	ok := true
+	if err := plurals.Validate(expr, rule.NPlurals); err != nil {
+		var ferr *plurals.FormsError
+		if errors.As(err, &ferr) && len(ferr.OutOfRange) == 0 {
+			// Unused forms are only wasted work for translators
+			fmt.Printf("%s: warning: %v\n", c.path, err)
+		} else {
+			fmt.Printf("%s: error: %v\n", c.path, err)
+			ok = false
+		}
+	}
+
+	if hasStandard {
+		want, err := standard.Compile()
+		if err == nil && standard.NPlurals == rule.NPlurals {
+			if same, n := plurals.Equivalent(expr, want); !same {
+				fmt.Printf("%s: warning: form %d for n = %d differs from the built-in rule for %q: %s\n", c.path, expr.Eval(n), n, lang, standard)
+			}
+		} else if err == nil {
+			fmt.Printf("%s: warning: nplurals=%d differs from the built-in rule for %q: %s\n", c.path, rule.NPlurals, lang, standard)
+		}
+	}
+	return ok
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// languageStats are the stats of a language as printed in JSON
+type languageStats struct {
+	Language   string                  `json:"language"`
+	Domains    map[string]gotext.Stats `json:"domains"`
+	Total      gotext.Stats            `json:"total"`
+	Completion float64                 `json:"completion"`
+}
+
+// runStats prints the stats of every catalog in a library directory, and exits with status 1
+// if a catalog can't be parsed or a language is less translated than -min.
+func runStats(args []string) int {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	minCompletion := flags.Float64("min", 0, "lowest percentage of translated entries accepted for each language")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: gotext stats [flags] [library]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Prints how complete the catalogs of a library directory, or the current one, are.")
+		fmt.Fprintln(os.Stderr, "Catalogs are found as lib/<lang>/LC_MESSAGES/<domain>.{po,mo} or lib/<lang>/<domain>.{po,mo}.")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "gotext stats: unknown format %q\n", *format)
+		return 2
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+	lib := "."
+	if flags.NArg() == 1 {
+		lib = flags.Arg(0)
+	}
+
+	// Catalogs that can't be parsed fail whatever -min says, the entries parsed are still counted
+	status := 0
+	stats, err := gotext.LibraryStats(lib)
+	if stats == nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err != nil {
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "gotext stats: %v\n", err)
+		}
+		status = 1
+	}
+
+	languages := make([]languageStats, 0, len(stats))
+	for lang, domains := range stats {
+		ls := languageStats{Language: lang, Domains: domains}
+		for _, s := range domains {
+			ls.Total.Add(s)
+		}
+		ls.Completion = ls.Total.Completion()
+		languages = append(languages, ls)
+	}
+	sort.Slice(languages, func(i, j int) bool { return languages[i].Language < languages[j].Language })
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(languages); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else {
+		printStats(languages)
+	}
+
+	for _, ls := range languages {
+		if ls.Completion < *minCompletion {
+			fmt.Fprintf(os.Stderr, "gotext stats: %s is %.1f%% translated, below %.1f%%\n", ls.Language, ls.Completion, *minCompletion)
+			status = 1
+		}
+	}
+	return status
+}
+
+// printStats prints a table with a row per language and domain, and the language total when it has several domains
+func printStats(languages []languageStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LANGUAGE\tDOMAIN\tTRANSLATED\tFUZZY\tUNTRANSLATED\tOBSOLETE\tWORDS\tCOMPLETE")
+
+	row := func(lang, dom string, s gotext.Stats) {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d/%d\t%.1f%%\n", lang, dom,
+			s.Translated.Entries, s.Fuzzy.Entries, s.Untranslated.Entries, s.Obsolete.Entries,
+			s.Translated.Words, s.Total().Words, s.Completion())
+	}
+
+	for _, ls := range languages {
+		domains := make([]string, 0, len(ls.Domains))
+		for dom := range ls.Domains {
+			domains = append(domains, dom)
+		}
+		sort.Strings(domains)
+
+		for _, dom := range domains {
+			row(ls.Language, dom, ls.Domains[dom])
+		}
+		if len(domains) > 1 {
+			row(ls.Language, "(total)", ls.Total)
+		}
+	}
+	w.Flush()
+}
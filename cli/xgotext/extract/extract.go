@@ -0,0 +1,134 @@
+// Package extract runs the extraction of xgotext from Go programs, like go generate steps and release tooling,
+// returning the translations found instead of writing them.
+//
+//	data, diagnostics, err := extract.Run(ctx, extract.Config{
+//		PkgTree:  "./cmd/app",
+//		Keywords: []string{"T", "i18n.TN:1,2"},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	for _, d := range diagnostics {
+//		log.Println(d)
+//	}
+//	return data.Save("locales")
+package extract
+
+import (
+	"context"
+	"errors"
+
+	"github.com/leonelquinteros/gotext/cli/xgotext/parser"
+	"github.com/leonelquinteros/gotext/cli/xgotext/parser/dir"
+	pkg_tree "github.com/leonelquinteros/gotext/cli/xgotext/parser/pkg-tree"
+)
+
+// Diagnostic is a problem found in the sources, which doesn't stop the extraction
+type Diagnostic = parser.Diagnostic
+
+// Config of an extraction. Keywords, patterns and keys are added to the ones registered with the functions
+// of the parser package, for the extraction only (see parser.DefaultSettings).
+type Config struct {
+	// Main package to parse, along with the packages it imports, as with xgotext -pkg-tree.
+	// Locations are relative to the working directory.
+	PkgTree string
+	// Directory to parse recursively, as with xgotext -in, if PkgTree is empty
+	Dir string
+	// Directories of Dir to skip, with their subdirectories: names, matched at any depth, or paths relative to Dir
+	Exclude []string
+
+	// Name of the default domain, "default" if empty
+	DefaultDomain string
+	// Options of the POT files written with the DomainMap returned
+	POT parser.POTOptions
+	// Fields of the header of the POT files written with the DomainMap returned,
+	// added to or replacing the default ones, like "Project-Id-Version"
+	Header map[string]string
+
+	// Functions and methods to extract (see parser.AddKeyword)
+	Keywords []string
+	// Glob patterns of template files (see parser.AddTemplatePattern)
+	TemplatePatterns []string
+	// Template functions and methods to extract (see parser.AddTemplateKeyword)
+	TemplateKeywords []string
+	// Keys of JSON and YAML files to extract (see parser.AddConfigKey)
+	ConfigKeys []string
+	// Build tags used to load packages
+	BuildTags []string
+
+	// Whether comments for translators are extracted, and the tag they must start with if any
+	// (see parser.ExtractComments)
+	AddComments bool
+	CommentTag  string
+
+	// Called, if not nil, with each directory or package before it's parsed
+	Progress func(path string)
+}
+
+// Run extracts the translations of the sources given by config. Problems in the sources, like calls
+// with arguments that aren't constant, are returned as diagnostics.
+// It stops with the error of ctx when ctx is done. Runs are independent, so they may run concurrently.
+func Run(ctx context.Context, config Config) (*parser.DomainMap, []Diagnostic, error) {
+	if config.PkgTree == "" && config.Dir == "" {
+		return nil, nil, errors.New("no input directory given")
+	}
+	if config.PkgTree != "" && config.Dir != "" {
+		return nil, nil, errors.New("specify either a package tree or a directory")
+	}
+
+	settings, err := newSettings(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := &parser.DomainMap{
+		Default:  config.DefaultDomain,
+		POT:      config.POT,
+		Header:   config.Header,
+		Settings: settings,
+	}
+	if data.Default == "" {
+		data.Default = "default"
+	}
+
+	if config.PkgTree != "" {
+		err = pkg_tree.ParsePkgTreeContext(ctx, config.PkgTree, data, config.Progress)
+	} else {
+		err = dir.ParseDirRecContext(ctx, config.Dir, config.Exclude, data, config.Progress)
+	}
+	if err != nil {
+		return nil, data.Diagnostics, err
+	}
+	return data, data.Diagnostics, nil
+}
+
+// newSettings returns the default settings of the parser package with the ones of config
+func newSettings(config Config) (*parser.Settings, error) {
+	settings := parser.DefaultSettings()
+	for _, spec := range config.Keywords {
+		if err := settings.AddKeyword(spec); err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range config.TemplatePatterns {
+		if err := settings.AddTemplatePattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	for _, spec := range config.TemplateKeywords {
+		if err := settings.AddTemplateKeyword(spec); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range config.ConfigKeys {
+		if err := settings.AddConfigKey(key); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.BuildTags) > 0 {
+		settings.SetBuildTags(config.BuildTags)
+	}
+	if config.AddComments {
+		settings.ExtractComments(config.CommentTag)
+	}
+	return settings, nil
+}
@@ -0,0 +1,136 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/leonelquinteros/gotext/cli/xgotext/parser"
+)
+
+func TestRun_PkgTree(t *testing.T) {
+	config := Config{
+		PkgTree:       filepath.Join("..", "fixtures", "keywords"),
+		DefaultDomain: "app",
+		Keywords:      []string{"keywords.T:1,1t", "keywords.TN:1,2"},
+		Header:        map[string]string{"Project-Id-Version": "app 1.0"},
+	}
+	data, _, err := Run(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := data.Domains["app"]
+	if d == nil || d.Translations["keyword call"] == nil || d.Translations["one keyword"] == nil {
+		t.Fatalf("Expected keyword calls in the app domain, got %v", data.Domains)
+	}
+	if data.Header["Project-Id-Version"] != "app 1.0" {
+		t.Errorf("Expected the header of the config, got %v", data.Header)
+	}
+
+	// Keywords only apply to the extraction
+	if parser.DefaultSettings().HasKeywords() {
+		t.Error("Keywords of the config were kept after the extraction")
+	}
+}
+
+func TestRun_Dir(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, text := range map[string]string{
+		"app.js":            "gettext(\"From a script\");\ngettext(name);\n",
+		"config.yml":        "title: From a config file\n",
+		"skip/other.js":     "gettext(\"Excluded\");\n",
+		"sub/broken.gohtml": "{{ .T.Get \"Unterminated\" ",
+	} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var progress []string
+	data, diagnostics, err := Run(context.Background(), Config{
+		Dir:        tmpDir,
+		Exclude:    []string{"skip"},
+		ConfigKeys: []string{"title"},
+		Progress:   func(path string) { progress = append(progress, path) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := data.Domains["default"]
+	for _, id := range []string{"From a script", "From a config file"} {
+		if d.Translations[id] == nil {
+			t.Errorf("Missing translation %q", id)
+		}
+	}
+	if d.Translations["Excluded"] != nil {
+		t.Error("Expected excluded directories to be skipped")
+	}
+	if len(progress) != 2 {
+		t.Errorf("Expected progress for 2 directories, got %v", progress)
+	}
+
+	want := map[string]bool{"app.js:2": true, filepath.Join("sub", "broken.gohtml"): true}
+	if len(diagnostics) != len(want) {
+		t.Errorf("Expected %d diagnostics, got %v", len(want), diagnostics)
+	}
+	for _, diag := range diagnostics {
+		if !want[diag.Pos] {
+			t.Errorf("Unexpected diagnostic %s", diag)
+		}
+	}
+}
+
+func TestRun_Concurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.yml"), []byte("title: A title\nlabel: A label\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each run only extracts the keys of its config
+	keys := []string{"title", "label"}
+	results := make([]*parser.DomainMap, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _, errs[i] = Run(context.Background(), Config{Dir: tmpDir, ConfigKeys: []string{key}})
+		}()
+	}
+	wg.Wait()
+
+	for i, want := range []string{"A title", "A label"} {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		translations := results[i].Domains["default"].Translations
+		if len(translations) != 1 || translations[want] == nil {
+			t.Errorf("Expected only %q for key %s, got %v", want, keys[i], translations)
+		}
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	if _, _, err := Run(context.Background(), Config{}); err == nil {
+		t.Error("Expected an error without input")
+	}
+	if _, _, err := Run(context.Background(), Config{Dir: ".", Keywords: []string{"T:0"}}); err == nil {
+		t.Error("Expected an error for an invalid keyword")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := Run(ctx, Config{Dir: "."}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Package keywords has wrappers of gotext, extracted with keyword specs
+package keywords
+
+import "github.com/leonelquinteros/gotext"
+
+// T translates s
+func T(s string, vars ...interface{}) string {
+	return gotext.Get(s, vars...)
+}
+
+// TN translates the plural s
+func TN(s, plural string, n int, vars ...interface{}) string {
+	return gotext.GetN(s, plural, n, vars...)
+}
+
+// TC translates s in the context ctx
+func TC(s, ctx string) string {
+	return gotext.GetC(s, ctx)
+}
+
+// Translator translates with a locale
+type Translator struct {
+	L *gotext.Locale
+}
+
+// TD translates the plural s in the domain dom
+func (t *Translator) TD(dom, s, plural string, n int) string {
+	return t.L.GetND(dom, s, plural, n)
+}
+
+// T translates s
+func (t *Translator) T(s string) string {
+	return t.L.Get(s)
+}
+
+// Other isn't a translator
+type Other struct{}
+
+// T isn't a translation
+func (Other) T(s string) string {
+	return s
+}
+
+func calls() {
+	T("keyword call")
+	T("keyword call with %d argument", 1)
+	TN("one keyword", "%d keywords", 2, 2)
+	TC("keyword with context", "kw context")
+
+	tr := &Translator{}
+	tr.TD("kwdomain", "domain keyword", "domain keywords", 2)
+	tr.T("method keyword")
+
+	Other{}.T("not a keyword")
+}
@@ -90,6 +90,9 @@ EOL`))
 	l.GetDC("domain2", "string", "ctx")
 	l.GetNDC("translations", "ndc", "ndcs", 7, "NDC-CTX")
 
+	// Get ordinal translations
+	l.GetOD("translations", "%d. place", 3, 3)
+
 	// try fake structs
 	f := Fake{}
 	_ = f.Get(3)
@@ -0,0 +1,14 @@
+package templates
+
+import (
+	"embed"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+//go:embed views/*
+var views embed.FS
+
+func Title() string {
+	return gotext.Get("templates package")
+}
@@ -1,13 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
+	"os"
 	"strings"
 
+	"github.com/leonelquinteros/gotext/cli/xgotext/extract"
 	"github.com/leonelquinteros/gotext/cli/xgotext/parser"
-	"github.com/leonelquinteros/gotext/cli/xgotext/parser/dir"
-	pkg_tree "github.com/leonelquinteros/gotext/cli/xgotext/parser/pkg-tree"
 )
 
 var (
@@ -15,10 +17,72 @@ var (
 	dirName       = flag.String("in", "", "input dir: /path/to/go/pkg")
 	outputDir     = flag.String("out", "", "output dir: /path/to/i18n/files")
 	defaultDomain = flag.String("default", "default", "Name of default domain")
-	excludeDirs   = flag.String("exclude", ".git", "Comma separated list of directories to exclude")
+	excludeDirs   = flag.String("exclude", ".git,node_modules", "Comma separated list of directories to exclude, by name at any depth or by path")
+	check         = flag.Bool("check", false, "Only check that the POT files of the output dir are up to date, exiting with status 1 if not, without writing anything")
+	merge         = flag.Bool("merge", false, "Also update the existing <lang>/LC_MESSAGES/<domain>.po files of the output dir")
 	verbose       = flag.Bool("v", false, "print currently handled directory")
 )
 
+// extraction settings given by the flags
+var config extract.Config
+
+// commentsFlag extracts comments for translators, all of them when given without a value,
+// or the ones starting with the value
+type commentsFlag struct{}
+
+func (c *commentsFlag) IsBoolFlag() bool {
+	return true
+}
+
+func (c *commentsFlag) String() string {
+	return config.CommentTag
+}
+
+func (c *commentsFlag) Set(tag string) error {
+	switch tag {
+	case "false":
+		return nil
+	case "true":
+		tag = ""
+	}
+	config.AddComments, config.CommentTag = true, tag
+	return nil
+}
+
+// appendFunc returns a flag.Func function appending the values of a repeatable flag to list
+func appendFunc(list *[]string) func(string) error {
+	return func(value string) error {
+		*list = append(*list, value)
+		return nil
+	}
+}
+
+func init() {
+	flag.Var(&commentsFlag{}, "add-comments", "Extract the comments right before calls for translators, or with =TAG only the ones starting with TAG")
+	flag.Func("keyword", "Additional function to extract, as in xgettext: [pkg.]name[:args], e.g. T:1,2c (repeatable)", appendFunc(&config.Keywords))
+	flag.Func("template", "Glob pattern of additional template files to parse, besides *.tmpl and *.gohtml (repeatable)", appendFunc(&config.TemplatePatterns))
+	flag.Func("config-key", "Key of JSON and YAML files whose string values are extracted: name or dotted path, e.g. menu.*.label (repeatable)", appendFunc(&config.ConfigKeys))
+	flag.Func("template-keyword", "Template function or method to extract, as in -keyword: name[:args] or field.method[:args], empty to drop the T.Get defaults (repeatable)", appendFunc(&config.TemplateKeywords))
+	flag.StringVar(&config.POT.PackageName, "package-name", "", "Package name of the Project-Id-Version header field")
+	flag.StringVar(&config.POT.PackageVersion, "package-version", "", "Package version of the Project-Id-Version header field")
+	flag.StringVar(&config.POT.BugsAddress, "msgid-bugs-address", "", "Address to report msgid bugs to, for the Report-Msgid-Bugs-To header field")
+	flag.StringVar(&config.POT.CopyrightHolder, "copyright-holder", "", "Copyright holder written in the header comment")
+	flag.BoolVar(&config.POT.OmitHeader, "omit-header", false, "Don't write the header entry")
+	flag.Func("add-location", "How source locations are written: full (file:line, default), file or never", func(s string) error {
+		mode, err := parser.ParseLocationMode(s)
+		config.POT.Locations = mode
+		return err
+	})
+	flag.BoolFunc("no-location", "Don't write source locations, as -add-location=never", func(string) error {
+		config.POT.Locations = parser.LocationNever
+		return nil
+	})
+	flag.Func("tags", "Comma separated list of build tags used to load packages", func(tags string) error {
+		config.BuildTags = strings.Split(tags, ",")
+		return nil
+	})
+}
+
 func main() {
 	flag.Parse()
 
@@ -35,24 +99,60 @@ func main() {
 		log.Fatal("No output directory given")
 	}
 
-	data := &parser.DomainMap{
-		Default: *defaultDomain,
+	config.PkgTree = *pkgTree
+	config.Dir = *dirName
+	config.Exclude = strings.Split(*excludeDirs, ",")
+	config.DefaultDomain = *defaultDomain
+	if *verbose {
+		config.Progress = func(path string) { log.Print(path) }
+	}
+
+	data, diagnostics, err := extract.Run(context.Background(), config)
+	for _, d := range diagnostics {
+		log.Printf("ERR: %s", d)
+	}
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	if *pkgTree != "" {
-		err := pkg_tree.ParsePkgTree(*pkgTree, data, *verbose)
+	if *check {
+		diffs, err := data.Check(*outputDir)
 		if err != nil {
 			log.Fatal(err)
 		}
-	} else {
-		err := dir.ParseDirRec(*dirName, strings.Split(*excludeDirs, ","), data, *verbose)
-		if err != nil {
-			log.Fatal(err)
+		stale := 0
+		for _, diff := range diffs {
+			fmt.Print(diff)
+			if diff.Stale {
+				stale++
+			}
+		}
+		if stale > 0 {
+			log.Printf("POT files without domain: %d, remove them", stale)
+		}
+		if len(diffs) > stale {
+			log.Printf("POT files out of date: %d, run xgotext without -check to update them", len(diffs)-stale)
 		}
+		if len(diffs) > 0 {
+			os.Exit(1)
+		}
+		return
 	}
 
-	err := data.Save(*outputDir)
+	err = data.Save(*outputDir)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	if *merge {
+		updated, err := data.Merge(*outputDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *verbose {
+			for _, path := range updated {
+				log.Println("updated", path)
+			}
+		}
+	}
 }
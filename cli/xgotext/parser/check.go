@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// Difference between the POT file of a domain and the file Save would write
+type Difference struct {
+	Path string
+	// Whether the file doesn't exist
+	Missing bool
+	// Whether no domain was extracted for the file, which Save leaves as is, so it must be removed
+	Stale bool
+	// Entries of the extraction the file doesn't have, and entries of the file the extraction doesn't have
+	Added   []string
+	Removed []string
+	// Whether the files only differ otherwise, like by source locations, flags or header fields
+	Changed bool
+}
+
+func (d *Difference) String() string {
+	var b strings.Builder
+	b.WriteString(d.Path)
+	if d.Missing {
+		b.WriteString(" (missing)")
+	}
+	if d.Stale {
+		b.WriteString(" (no domain extracted)")
+	}
+	b.WriteString(":\n")
+	for _, entry := range d.Added {
+		b.WriteString("+ " + entry + "\n")
+	}
+	for _, entry := range d.Removed {
+		b.WriteString("- " + entry + "\n")
+	}
+	if d.Changed {
+		b.WriteString("~ source locations, comments, flags or header fields changed\n")
+	}
+	return b.String()
+}
+
+// potCreationDate matches the POT-Creation-Date field of a POT file
+var potCreationDate = regexp.MustCompile(`(?m)^"POT-Creation-Date: ([^"\\]*)(?:\\n)?"\n`)
+
+// Check compares the domains with their POT files in directory, without writing anything, and returns
+// the differences found, sorted by path. The creation dates of the files are ignored.
+// POT files of directory without domain are reported as stale, with their entries as removed.
+func (m *DomainMap) Check(directory string) ([]*Difference, error) {
+	var diffs []*Difference
+
+	files, err := os.ReadDir(directory)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read output dir: %v", err)
+	}
+	for _, file := range files {
+		name, ok := strings.CutSuffix(file.Name(), ".pot")
+		if !ok || file.IsDir() || m.Domains[name] != nil {
+			continue
+		}
+		path := filepath.Join(directory, file.Name())
+		diff, err := checkStale(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %v", path, err)
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, name := range sortedDomains(m.Domains) {
+		path := filepath.Join(directory, name+".pot")
+		diff, err := m.check(m.Domains[name], path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check domain %s: %v", name, err)
+		}
+		if diff != nil {
+			diffs = append(diffs, diff)
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs, nil
+}
+
+// checkStale returns the difference of the POT file at path, which has no domain
+func checkStale(path string) (*Difference, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	po := gotext.NewPo()
+	po.Parse(data)
+	entries := make(map[string]*mergeEntry)
+	addPoEntries(entries, po)
+
+	diff := &Difference{Path: path, Stale: true}
+	for _, e := range entries {
+		diff.Removed = append(diff.Removed, describeEntry(e))
+	}
+	sort.Strings(diff.Removed)
+	return diff, nil
+}
+
+// check compares the domain with the POT file at path, returning nil if they're the same
+func (m *DomainMap) check(d *Domain, path string) (*Difference, error) {
+	old, err := os.ReadFile(path)
+	missing := os.IsNotExist(err)
+	if err != nil && !missing {
+		return nil, err
+	}
+
+	// Written with the creation date of the file, so only the other changes count
+	opts := m.POT
+	if match := potCreationDate.FindSubmatch(old); match != nil {
+		if date, err := time.Parse("2006-01-02 15:04-0700", string(match[1])); err == nil {
+			opts.CreationDate = date
+		}
+	}
+	if opts.CreationDate.IsZero() {
+		opts.CreationDate = time.Unix(0, 0).UTC()
+	}
+	text, err := d.marshalText(&opts, m.Header)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(potCreationDate.ReplaceAll(text, nil), potCreationDate.ReplaceAll(old, nil)) {
+		return nil, nil
+	}
+
+	diff := &Difference{Path: path, Missing: missing}
+
+	po := gotext.NewPo()
+	po.Parse(old)
+	oldEntries := make(map[string]*mergeEntry)
+	addPoEntries(oldEntries, po)
+
+	newEntries := make(map[string]bool)
+	for _, t := range d.list() {
+		e := &mergeEntry{Context: t.Context, MsgID: t.MsgID}
+		newEntries[e.key()] = true
+		if oldEntries[e.key()] == nil {
+			diff.Added = append(diff.Added, describeEntry(e))
+		}
+	}
+	for key, e := range oldEntries {
+		if !newEntries[key] {
+			diff.Removed = append(diff.Removed, describeEntry(e))
+		}
+	}
+	sort.Strings(diff.Removed)
+	diff.Changed = len(diff.Added) == 0 && len(diff.Removed) == 0
+	return diff, nil
+}
+
+// describeEntry returns the msgid of an entry, with its context if any
+func describeEntry(e *mergeEntry) string {
+	if e.Context != "" {
+		return strconv.Quote(e.MsgID) + " (context " + strconv.Quote(e.Context) + ")"
+	}
+	return strconv.Quote(e.MsgID)
+}
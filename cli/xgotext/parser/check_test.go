@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func checkTestDomains() *DomainMap {
+	dm := &DomainMap{POT: POTOptions{CreationDate: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)}}
+	dm.AddTranslation("", &Translation{MsgID: "Hello", SourceLocations: []string{"main.go:5"}})
+	dm.AddTranslation("", &Translation{MsgID: "File", Context: "menu", SourceLocations: []string{"main.go:6"}})
+	dm.AddTranslation("errors", &Translation{MsgID: "Not found", SourceLocations: []string{"main.go:7"}})
+	return dm
+}
+
+func TestDomainMap_Check(t *testing.T) {
+	dir := t.TempDir()
+	if err := checkTestDomains().Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same extraction on another day
+	dm := checkTestDomains()
+	dm.POT.CreationDate = time.Now()
+	diffs, err := dm.Check(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no differences, got %v", diffs)
+	}
+
+	// New and removed entries
+	dm = checkTestDomains()
+	dm.AddTranslation("", &Translation{MsgID: "Bye", SourceLocations: []string{"main.go:8"}})
+	delete(dm.Domains["default"].ContextTranslations, "menu")
+	diffs, err = dm.Check(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Difference{
+		Path:    filepath.Join(dir, "default.pot"),
+		Added:   []string{`"Bye"`},
+		Removed: []string{`"File" (context "menu")`},
+	}
+	if len(diffs) != 1 || !reflect.DeepEqual(diffs[0], want) {
+		t.Errorf("Expected %+v, got %+v", want, diffs)
+	}
+
+	// Other changes
+	dm = checkTestDomains()
+	dm.Domains["errors"].Translations["Not found"].SourceLocations = []string{"main.go:70"}
+	diffs, err = dm.Check(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || !diffs[0].Changed || diffs[0].Path != filepath.Join(dir, "errors.pot") {
+		t.Errorf("Expected errors.pot to be changed, got %+v", diffs)
+	}
+}
+
+func TestDomainMap_Check_Missing(t *testing.T) {
+	dir := t.TempDir()
+	diffs, err := checkTestDomains().Check(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 2 || !diffs[0].Missing || len(diffs[0].Added) != 2 {
+		t.Errorf("Expected 2 missing files, got %+v", diffs)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected nothing to be written, got %v", entries)
+	}
+}
+
+func TestDomainMap_Check_Stale(t *testing.T) {
+	dir := t.TempDir()
+	if err := checkTestDomains().Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// The errors domain isn't extracted anymore
+	dm := checkTestDomains()
+	delete(dm.Domains, "errors")
+	diffs, err := dm.Check(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Difference{
+		Path:    filepath.Join(dir, "errors.pot"),
+		Stale:   true,
+		Removed: []string{`"Not found"`},
+	}
+	if len(diffs) != 1 || !reflect.DeepEqual(diffs[0], want) {
+		t.Errorf("Expected %+v, got %+v", want, diffs)
+	}
+	if got := want.String(); !strings.HasPrefix(got, want.Path+" (no domain extracted):\n") {
+		t.Errorf("Unexpected description %q", got)
+	}
+}
+
+func TestDifference_String(t *testing.T) {
+	d := &Difference{Path: "locales/default.pot", Added: []string{`"Bye"`}, Removed: []string{`"Hello"`}}
+	want := "locales/default.pot:\n+ \"Bye\"\n- \"Hello\"\n"
+	if got := d.String(); got != want {
+		t.Errorf("Expected\n%s\ngot:\n%s", want, got)
+	}
+}
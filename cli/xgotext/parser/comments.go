@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// callComments returns the lines of the comment for translators of the call n, nil if there's none
+func (g *GoFile) callComments(n *ast.CallExpr) []string {
+	settings := g.Data.ParserSettings()
+	if !settings.extractComments || g.File == nil {
+		return nil
+	}
+
+	// Index comments of the file by the line they end on
+	if g.commentLines == nil {
+		g.commentLines = make(map[int]*ast.CommentGroup, len(g.File.Comments))
+		for _, group := range g.File.Comments {
+			g.commentLines[g.FileSet.Position(group.End()).Line] = group
+		}
+	}
+
+	line := g.FileSet.Position(n.Pos()).Line
+	group := g.commentLines[line]
+	if group == nil || group.End() > n.Pos() {
+		group = g.commentLines[line-1]
+	}
+	if group == nil || group.End() > n.Pos() {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimSpace(group.Text()), "\n")
+	if tag := settings.commentTag; tag != "" {
+		start := -1
+		for i, l := range lines {
+			if strings.HasPrefix(strings.TrimSpace(l), tag) {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil
+		}
+		lines = lines[start:]
+	}
+
+	comments := make([]string, 0, len(lines))
+	for _, l := range lines {
+		comments = append(comments, strings.TrimSpace(l))
+	}
+	return comments
+}
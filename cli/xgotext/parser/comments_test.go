@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+const commentsSource = `package main
+
+func main() {
+	// TRANSLATORS: %s is a file name
+	T("Open %s")
+
+	// Not for translators
+	T("Close")
+
+	/* Shown in the title bar.
+	   TRANSLATORS: keep it short */
+	T("Editor")
+
+	// TRANSLATORS: same message
+	T("Open %s")
+
+	// TRANSLATORS: too far away
+
+	T("Save")
+
+	x := T("Quit") // TRANSLATORS: after the call
+	_ = x
+}
+`
+
+func extractTestComments(t *testing.T, tag string) *Domain {
+	t.Helper()
+
+	settings := &Settings{}
+	if err := settings.AddKeyword("T"); err != nil {
+		t.Fatal(err)
+	}
+	settings.ExtractComments(tag)
+
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "main.go", commentsSource, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := &DomainMap{Settings: settings}
+	g := &GoFile{
+		FilePath: "main.go",
+		BasePath: ".",
+		Data:     data,
+		File:     file,
+		FileSet:  fset,
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			g.InspectCallExpr(call)
+		}
+		return true
+	})
+	return data.Domains["default"]
+}
+
+func TestGoFile_Comments(t *testing.T) {
+	d := extractTestComments(t, "TRANSLATORS:")
+
+	tests := map[string][]string{
+		"Open %s": {"TRANSLATORS: %s is a file name", "TRANSLATORS: same message"},
+		"Close":   nil,
+		"Editor":  {"TRANSLATORS: keep it short"},
+		"Save":    nil,
+		"Quit":    nil,
+	}
+	for id, want := range tests {
+		if got := d.Translations[id].Comments; !reflect.DeepEqual(got, want) {
+			t.Errorf("Comments of %q = %q, want %q", id, got, want)
+		}
+	}
+
+	if dump := d.Translations["Open %s"].Dump(); !contains(dump, "#. TRANSLATORS: %s is a file name\n#. TRANSLATORS: same message\n#: main.go:5 main.go:15") {
+		t.Errorf("Unexpected dump:\n%s", dump)
+	}
+}
+
+func TestGoFile_Comments_NoTag(t *testing.T) {
+	d := extractTestComments(t, "")
+
+	tests := map[string][]string{
+		"Close":  {"Not for translators"},
+		"Editor": {"Shown in the title bar.", "TRANSLATORS: keep it short"},
+		"Save":   nil,
+	}
+	for id, want := range tests {
+		if got := d.Translations[id].Comments; !reflect.DeepEqual(got, want) {
+			t.Errorf("Comments of %q = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// isConfigKey reports whether the value at keyPath is extracted
+func (s *Settings) isConfigKey(keyPath []string) bool {
+	if len(keyPath) == 0 {
+		return false
+	}
+	for _, elems := range s.configKeys {
+		if len(elems) == 1 {
+			if ok, _ := path.Match(elems[0], keyPath[len(keyPath)-1]); ok {
+				return true
+			}
+			continue
+		}
+		if len(elems) != len(keyPath) {
+			continue
+		}
+		match := true
+		for i, elem := range elems {
+			if ok, _ := path.Match(elem, keyPath[i]); !ok {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigFile handles the parsing of one JSON or YAML file
+type ConfigFile struct {
+	FilePath string
+	BasePath string
+	Data     *DomainMap
+}
+
+// Parse parses the file text, as JSON if its name ends with ".json" and as YAML otherwise,
+// and adds the string values of the config keys to Data
+func (c *ConfigFile) Parse(text []byte) error {
+	if strings.EqualFold(filepath.Ext(c.FilePath), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(text))
+		return c.parseJSON(dec, text, nil)
+	}
+	return c.parseYAML(string(text))
+}
+
+// add adds msgid, found at line
+func (c *ConfigFile) add(msgid string, line int) {
+	if msgid == "" {
+		return
+	}
+	rel, _ := filepath.Rel(c.BasePath, c.FilePath)
+	c.Data.AddTranslation("", &Translation{
+		MsgID:           msgid,
+		SourceLocations: []string{fmt.Sprintf("%s:%d", rel, line)},
+	})
+}
+
+// parseJSON parses the next JSON value of dec, found at keyPath
+func (c *ConfigFile) parseJSON(dec *json.Decoder, text []byte, keyPath []string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		for dec.More() {
+			elemPath := keyPath
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				elemPath = append(keyPath[:len(keyPath):len(keyPath)], key.(string))
+			}
+			if err := c.parseJSON(dec, text, elemPath); err != nil {
+				return err
+			}
+		}
+		// Closing delimiter
+		_, err = dec.Token()
+		return err
+	case string:
+		if c.Data.ParserSettings().isConfigKey(keyPath) {
+			c.add(t, bytes.Count(text[:dec.InputOffset()], []byte("\n"))+1)
+		}
+	}
+	return nil
+}
+
+// yamlKey matches a "key: value" line of a YAML block mapping
+var yamlKey = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s"'#][^#]*?)\s*:(?:\s+(.*))?$`)
+
+// yamlLevel is a key of a YAML block mapping containing the lines below it
+type yamlLevel struct {
+	indent int
+	key    string
+}
+
+// parseYAML parses the block mappings and sequences of YAML documents. Flow collections and
+// multi-line flow scalars aren't supported.
+func (c *ConfigFile) parseYAML(src string) error {
+	lines := strings.Split(src, "\n")
+	var stack []yamlLevel
+
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimRight(lines[n], " \t\r")
+		content := strings.TrimLeft(line, " ")
+		if content == "" || content[0] == '#' || content == "---" || content == "..." {
+			continue
+		}
+
+		// Sequence items
+		item := false
+		for content == "-" || strings.HasPrefix(content, "- ") {
+			content = strings.TrimLeft(content[1:], " ")
+			item = true
+		}
+		indent := len(line) - len(content)
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if content == "" {
+			continue
+		}
+
+		keyPath := make([]string, 0, len(stack)+1)
+		for _, level := range stack {
+			keyPath = append(keyPath, level.key)
+		}
+
+		value := content
+		if m := yamlKey.FindStringSubmatch(content); m != nil {
+			key, err := yamlScalar(m[1])
+			if err != nil {
+				return fmt.Errorf("line %d: %v", n+1, err)
+			}
+			if m[2] == "" || m[2][0] == '#' {
+				stack = append(stack, yamlLevel{indent: indent, key: key})
+				continue
+			}
+			keyPath = append(keyPath, key)
+			value = m[2]
+		} else if !item {
+			// Continuation of a multi-line scalar
+			continue
+		}
+
+		if !c.Data.ParserSettings().isConfigKey(keyPath) {
+			continue
+		}
+		if value[0] == '|' || value[0] == '>' {
+			block, last := yamlBlock(lines, n, indent, value)
+			c.add(block, n+1)
+			n = last
+			continue
+		}
+		if str, err := yamlScalar(value); err != nil {
+			return fmt.Errorf("line %d: %v", n+1, err)
+		} else if value[0] == '"' || value[0] == '\'' || !yamlNonString(str) {
+			c.add(str, n+1)
+		}
+	}
+	return nil
+}
+
+// yamlScalar returns the value of a single line scalar
+func yamlScalar(s string) (string, error) {
+	switch s[0] {
+	case '"':
+		end := strings.LastIndexByte(s, '"')
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return strconv.Unquote(s[:end+1])
+	case '\'':
+		end := strings.LastIndexByte(s, '\'')
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return strings.ReplaceAll(s[1:end], "''", "'"), nil
+	}
+	// Plain scalar, without its comment
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// yamlNonString reports whether the plain scalar s isn't a string
+func yamlNonString(s string) bool {
+	switch s {
+	case "", "~", "null", "true", "false":
+		return true
+	}
+	if s[0] == '[' || s[0] == '{' || s[0] == '&' || s[0] == '*' || s[0] == '!' {
+		return true
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// yamlBlock returns the literal (|) or folded (>) block scalar starting at lines[n], whose key is at indent,
+// and the index of its last line
+func yamlBlock(lines []string, n, indent int, header string) (string, int) {
+	var body []string
+	blockIndent := -1
+	last := n
+	for i := n + 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t\r")
+		content := strings.TrimLeft(line, " ")
+		if content == "" {
+			body = append(body, "")
+			continue
+		}
+		lineIndent := len(line) - len(content)
+		if lineIndent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = lineIndent
+		}
+		body = append(body, line[min(blockIndent, lineIndent):])
+		last = i
+	}
+	// Trailing empty lines aren't part of the block
+	body = body[:max(0, last-n)]
+
+	sep := "\n"
+	if header[0] == '>' {
+		sep = " "
+	}
+	text := strings.Join(body, sep)
+	if !strings.Contains(header, "-") && text != "" {
+		text += "\n"
+	}
+	return text, last
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const configJSON = `{
+  "title": "Dashboard",
+  "version": 2,
+  "menu": [
+    {"label": "Home", "url": "/"},
+    {"label": "Settings", "url": "/settings"}
+  ],
+  "footer": {"title": "About"}
+}`
+
+const configYAML = `# Navigation
+title: Dashboard
+version: 2
+menu:
+  - label: Home
+    url: /
+  - label: 'It''s settings' # quoted
+    url: /settings
+footer:
+  title: "About\tus"
+  enabled: true
+help:
+  label: |
+    First line
+    second line
+
+empty:
+`
+
+func extractTestConfig(t *testing.T, path, text string, keys ...string) *Domain {
+	t.Helper()
+
+	settings := &Settings{}
+	for _, key := range keys {
+		if err := settings.AddConfigKey(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := &DomainMap{Settings: settings}
+	file := ConfigFile{FilePath: path, BasePath: ".", Data: data}
+	if err := file.Parse([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	return data.Domains["default"]
+}
+
+func configLocations(d *Domain) map[string][]string {
+	locations := make(map[string][]string)
+	if d != nil {
+		for id, tr := range d.Translations {
+			locations[id] = tr.SourceLocations
+		}
+	}
+	return locations
+}
+
+func TestConfigFile_ParseJSON(t *testing.T) {
+	d := extractTestConfig(t, "config.json", configJSON, "title", "menu.label", "version")
+	want := map[string][]string{
+		"Dashboard": {"config.json:2"},
+		"Home":      {"config.json:5"},
+		"Settings":  {"config.json:6"},
+		"About":     {"config.json:8"},
+	}
+	if got := configLocations(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("Extracted %v, want %v", got, want)
+	}
+}
+
+func TestConfigFile_ParseYAML(t *testing.T) {
+	d := extractTestConfig(t, "config.yml", configYAML, "*.title", "menu.label", "help.*", "version", "enabled", "empty")
+	want := map[string][]string{
+		"Home":                      {"config.yml:5"},
+		"It's settings":             {"config.yml:7"},
+		"About\tus":                 {"config.yml:10"},
+		"First line\nsecond line\n": {"config.yml:13"},
+	}
+	if got := configLocations(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("Extracted %q, want %q", got, want)
+	}
+}
+
+func TestSettings_AddConfigKey(t *testing.T) {
+	settings := &Settings{}
+	for _, key := range []string{"", "menu..label", "[a"} {
+		if err := settings.AddConfigKey(key); err == nil {
+			t.Errorf("Expected an error for %q", key)
+		}
+	}
+}
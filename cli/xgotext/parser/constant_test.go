@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const constantSource = `package main
+
+type Message string
+
+const (
+	greeting         = "Hello"
+	farewell Message = "Bye"
+	menu             = "menu"
+)
+
+func T(domain, ctx, msgid, plural string) string { return msgid }
+
+func N(domain, msgid, plural string) string { return msgid }
+
+func main() {
+	N("default", "Hello, " +
+		"world", "")
+	T("default", menu, greeting, "")
+	N("default", string(farewell), ("Byes"))
+	N("other" + "s", ` + "`raw`" + `, "")
+	N("default", "x" + main2(), "")
+}
+
+func main2() string { return "" }
+`
+
+func extractTestConstants(t *testing.T, typed bool) *DomainMap {
+	t.Helper()
+
+	settings := &Settings{}
+	for _, spec := range []string{"T:3,4,2c,1d", "N:2,3,1d"} {
+		if err := settings.AddKeyword(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "main.go", constantSource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := &packages.Package{Name: "main"}
+	if typed {
+		pkg.TypesInfo = &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Uses:  make(map[*ast.Ident]types.Object),
+		}
+		if _, err := new(types.Config).Check("main", fset, []*ast.File{file}, pkg.TypesInfo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := &DomainMap{Settings: settings}
+	g := &GoFile{
+		FilePath: "main.go",
+		BasePath: ".",
+		Data:     data,
+		File:     file,
+		FileSet:  fset,
+
+		ImportedPackages: map[string]*packages.Package{"main": pkg},
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			g.InspectCallExpr(call)
+		}
+		return true
+	})
+	return data
+}
+
+func TestGoFile_ConstantArgs(t *testing.T) {
+	data := extractTestConstants(t, true)
+
+	d := data.Domains["default"]
+	if d.Translations["Hello, world"] == nil {
+		t.Error("Expected concatenated literals to be extracted")
+	}
+	if d.ContextTranslations["menu"]["Hello"] == nil {
+		t.Error("Expected named constants to be extracted, for msgid and context")
+	}
+	if tr := d.Translations["Bye"]; tr == nil || tr.MsgIDPlural != "Byes" {
+		t.Errorf("Expected typed constants and parenthesized literals to be extracted, got %+v", tr)
+	}
+	if data.Domains["others"] == nil || data.Domains["others"].Translations["raw"] == nil {
+		t.Error("Expected constant domains to be extracted")
+	}
+	if len(d.Translations) != 2 {
+		t.Errorf("Expected non-constant msgids to be skipped, got %d translations", len(d.Translations))
+	}
+}
+
+func TestGoFile_ConstantArgs_Untyped(t *testing.T) {
+	data := extractTestConstants(t, false)
+
+	d := data.Domains["default"]
+	if d.Translations["Hello, world"] == nil {
+		t.Error("Expected concatenated literals to be extracted without type information")
+	}
+	if d.Translations["Bye"] != nil {
+		t.Error("Expected conversions not to be extracted without type information")
+	}
+	if data.Domains["others"] == nil {
+		t.Error("Expected concatenated domains to be extracted without type information")
+	}
+}
@@ -0,0 +1,122 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/leonelquinteros/gotext/cli/xgotext/parser"
+)
+
+// Extractor extracts translations from the files whose name matches one of its patterns
+type Extractor interface {
+	// Patterns returns the glob patterns, like "*.js", matched against file names,
+	// with the settings of the extraction
+	Patterns(settings *parser.Settings) []string
+	// Extract adds the translations of a file to data, with locations relative to basePath
+	Extract(filePath, basePath string, text []byte, data *parser.DomainMap) error
+}
+
+// ExtractFunc extracts the translations of one file
+type ExtractFunc func(filePath, basePath string, text []byte, data *parser.DomainMap) error
+
+// funcExtractor is an Extractor calling an ExtractFunc
+type funcExtractor struct {
+	patterns func(settings *parser.Settings) []string
+	extract  ExtractFunc
+}
+
+func (e *funcExtractor) Patterns(settings *parser.Settings) []string {
+	return e.patterns(settings)
+}
+
+func (e *funcExtractor) Extract(filePath, basePath string, text []byte, data *parser.DomainMap) error {
+	return e.extract(filePath, basePath, text, data)
+}
+
+// NewExtractor returns an Extractor calling extract for the files matching patterns
+func NewExtractor(patterns []string, extract ExtractFunc) Extractor {
+	return &funcExtractor{
+		patterns: func(*parser.Settings) []string { return patterns },
+		extract:  extract,
+	}
+}
+
+var knownExtractors []Extractor
+
+// AddExtractor to the known extractor list
+func AddExtractor(extractor Extractor) {
+	knownExtractors = append(knownExtractors, extractor)
+}
+
+// register the extractors of non-go files
+func init() {
+	AddExtractor(NewExtractor(
+		[]string{"*.js", "*.jsx", "*.mjs", "*.cjs", "*.ts", "*.tsx", "*.mts", "*.cts"},
+		func(filePath, basePath string, text []byte, data *parser.DomainMap) error {
+			file := parser.ScriptFile{FilePath: filePath, BasePath: basePath, Data: data}
+			return file.Parse(text)
+		},
+	))
+	AddExtractor(NewExtractor(
+		[]string{"*.vue"},
+		func(filePath, basePath string, text []byte, data *parser.DomainMap) error {
+			file := parser.VueFile{FilePath: filePath, BasePath: basePath, Data: data}
+			return file.Parse(text)
+		},
+	))
+	AddExtractor(&funcExtractor{
+		// Config files are only read when keys to extract were given
+		patterns: func(settings *parser.Settings) []string {
+			if !settings.HasConfigKeys() {
+				return nil
+			}
+			return []string{"*.json", "*.yaml", "*.yml"}
+		},
+		extract: func(filePath, basePath string, text []byte, data *parser.DomainMap) error {
+			file := parser.ConfigFile{FilePath: filePath, BasePath: basePath, Data: data}
+			return file.Parse(text)
+		},
+	})
+}
+
+// matches reports whether the file name matches one of the extractor patterns
+func matches(extractor Extractor, settings *parser.Settings, name string) bool {
+	for _, pattern := range extractor.Patterns(settings) {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// extractFiles calls the known extractors for each file of a directory
+func extractFiles(dirPath, basePath string, data *parser.DomainMap) error {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dirPath, entry.Name())
+
+		var text []byte
+		for _, extractor := range knownExtractors {
+			if !matches(extractor, data.ParserSettings(), entry.Name()) {
+				continue
+			}
+			if text == nil {
+				if text, err = os.ReadFile(path); err != nil {
+					return err
+				}
+			}
+			if err := extractor.Extract(path, basePath, text, data); err != nil {
+				rel, _ := filepath.Rel(basePath, path)
+				data.AddDiagnostic(rel, "failed to parse: %v", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leonelquinteros/gotext/cli/xgotext/parser"
+)
+
+func TestParseDir_Extractors(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, text := range map[string]string{
+		"app.js":    `gettext("From a script");`,
+		"App.vue":   `<template><p>{{ $gettext("From a component") }}</p></template>`,
+		"page.tmpl": `{{ .T.Get "From a template" }}`,
+		"notes.txt": `gettext("Not extracted")`,
+		"data.csv":  `Custom`,
+	} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only the extractors, without the go parser
+	defer func(saved []ParseDirFunc) { knownParser = saved }(knownParser)
+	defer func(saved []Extractor) { knownExtractors = saved }(knownExtractors)
+	knownParser = nil
+	var extracted []string
+	AddExtractor(NewExtractor([]string{"*.csv"}, func(filePath, basePath string, text []byte, data *parser.DomainMap) error {
+		extracted = append(extracted, filepath.Base(filePath))
+		data.AddTranslation("", &parser.Translation{MsgID: string(text)})
+		return nil
+	}))
+
+	dm := &parser.DomainMap{}
+	if err := ParseDir(tmpDir, tmpDir, dm); err != nil {
+		t.Fatal(err)
+	}
+
+	d := dm.Domains["default"]
+	for _, id := range []string{"From a script", "From a component", "From a template", "Custom"} {
+		if d == nil || d.Translations[id] == nil {
+			t.Errorf("Missing translation %q", id)
+		}
+	}
+	if d != nil && d.Translations["Not extracted"] != nil {
+		t.Error("Unexpected translation from a file without extractor")
+	}
+	if len(extracted) != 1 || extracted[0] != "data.csv" {
+		t.Errorf("Expected the added extractor to be called for data.csv, got %v", extracted)
+	}
+	if tr := d.Translations["From a script"]; tr != nil && tr.SourceLocations[0] != "app.js:1" {
+		t.Errorf("Expected location relative to the base path, got %v", tr.SourceLocations)
+	}
+}
+
+func TestParseDirRec_ExtractorsExclude(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, text := range map[string]string{
+		"app.js":                          `gettext("From the app");`,
+		"web/node_modules/lib/index.js":   `gettext("From a nested dependency");`,
+		"node_modules/lib/index.js":       `gettext("From a dependency");`,
+		"web/dist/bundle.js":              `gettext("From a build");`,
+		"web/src/dist/notes.js":           `gettext("From a source dir named dist");`,
+		"web/src/node_modules_list/ok.js": `gettext("From a similar name");`,
+	} {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defer func(saved []ParseDirFunc) { knownParser = saved }(knownParser)
+	knownParser = nil
+
+	dm := &parser.DomainMap{}
+	if err := ParseDirRec(tmpDir, []string{"node_modules", "web/dist"}, dm, false); err != nil {
+		t.Fatal(err)
+	}
+
+	d := dm.Domains["default"]
+	for _, id := range []string{"From the app", "From a source dir named dist", "From a similar name"} {
+		if d == nil || d.Translations[id] == nil {
+			t.Errorf("Missing translation %q", id)
+		}
+	}
+	for _, id := range []string{"From a nested dependency", "From a dependency", "From a build"} {
+		if d != nil && d.Translations[id] != nil {
+			t.Errorf("Unexpected translation %q from an excluded directory", id)
+		}
+	}
+}
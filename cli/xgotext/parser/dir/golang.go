@@ -3,7 +3,6 @@ package dir
 import (
 	"go/ast"
 	"go/token"
-	"log"
 	"strconv"
 
 	"golang.org/x/tools/go/packages"
@@ -26,15 +25,17 @@ func goParser(dirPath, basePath string, data *parser.DomainMap) error {
 			packages.NeedSyntax |
 			packages.NeedTypes |
 			packages.NeedTypesInfo,
-		Fset: fileSet,
-		Dir:  basePath,
+		Fset:       fileSet,
+		Dir:        basePath,
+		BuildFlags: data.ParserSettings().BuildFlags(),
 	}
 
 	// load package from path
 	pkgs, err := packages.Load(&packages.Config{
-		Mode: conf.Mode,
-		Fset: fileSet,
-		Dir:  dirPath,
+		Mode:       conf.Mode,
+		Fset:       fileSet,
+		Dir:        dirPath,
+		BuildFlags: conf.BuildFlags,
 	})
 	if err != nil || len(pkgs) == 0 {
 		// not a go package
@@ -48,6 +49,7 @@ func goParser(dirPath, basePath string, data *parser.DomainMap) error {
 				PkgConf:  &conf,
 				FilePath: fileSet.Position(node.Package).Filename,
 				BasePath: basePath,
+				File:     node,
 				Data:     data,
 				FileSet:  fileSet,
 
@@ -88,7 +90,7 @@ func (g *GoFile) InspectFile(n ast.Node) bool {
 
 		pkg, err := g.GetPackage(packageName)
 		if err != nil {
-			log.Printf("failed to load package %s: %s", packageName, err)
+			g.Data.AddDiagnostic(g.Position(x.Pos()), "failed to load package %s: %s", packageName, err)
 		} else {
 			if x.Name == nil {
 				g.ImportedPackages[pkg.Name] = pkg
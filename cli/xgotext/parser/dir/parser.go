@@ -1,6 +1,7 @@
 package dir
 
 import (
+	"context"
 	"log"
 	"os"
 	"path/filepath"
@@ -23,7 +24,7 @@ func AddParser(parser ParseDirFunc) {
 	}
 }
 
-// ParseDir calls all known parser for each directory
+// ParseDir calls all known parser for each directory, then the known extractors for its files
 func ParseDir(dirPath, basePath string, data *parser.DomainMap) error {
 	dirPath, _ = filepath.Abs(dirPath)
 	basePath, _ = filepath.Abs(basePath)
@@ -34,11 +35,21 @@ func ParseDir(dirPath, basePath string, data *parser.DomainMap) error {
 			return err
 		}
 	}
-	return nil
+	return extractFiles(dirPath, basePath, data)
 }
 
 // ParseDirRec calls all known parser for each directory
 func ParseDirRec(dirPath string, exclude []string, data *parser.DomainMap, verbose bool) error {
+	var progress func(string)
+	if verbose {
+		progress = func(path string) { log.Print(path) }
+	}
+	return ParseDirRecContext(context.Background(), dirPath, exclude, data, progress)
+}
+
+// ParseDirRecContext calls all known parser for each directory, stopping when ctx is done.
+// progress, if not nil, is called with the path of each directory before it's parsed.
+func ParseDirRecContext(ctx context.Context, dirPath string, exclude []string, data *parser.DomainMap, progress func(path string)) error {
 	dirPath, _ = filepath.Abs(dirPath)
 
 	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
@@ -47,15 +58,17 @@ func ParseDirRec(dirPath string, exclude []string, data *parser.DomainMap, verbo
 		}
 
 		if info.IsDir() {
-			// skip directory if in exclude list
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			// skip directory and its subdirectories if in exclude list
 			subDir, _ := filepath.Rel(dirPath, path)
-			for _, d := range exclude {
-				if strings.HasPrefix(subDir, d) {
-					return nil
-				}
+			if excluded(subDir, exclude) {
+				return filepath.SkipDir
 			}
-			if verbose {
-				log.Print(path)
+			if progress != nil {
+				progress(path)
 			}
 
 			err := ParseDir(path, dirPath, data)
@@ -67,3 +80,23 @@ func ParseDirRec(dirPath string, exclude []string, data *parser.DomainMap, verbo
 	})
 	return err
 }
+
+// excluded reports whether the directory at subDir, relative to the walked one, is excluded:
+// when its name is one of exclude, at any depth, or its path starts with one of exclude containing a separator.
+func excluded(subDir string, exclude []string) bool {
+	if subDir == "." {
+		return false
+	}
+	subDir = filepath.ToSlash(subDir)
+	name := filepath.Base(subDir)
+	for _, d := range exclude {
+		d = strings.Trim(filepath.ToSlash(strings.TrimSpace(d)), "/")
+		if d == "" {
+			continue
+		}
+		if d == name || subDir == d || strings.HasPrefix(subDir, d+"/") {
+			return true
+		}
+	}
+	return false
+}
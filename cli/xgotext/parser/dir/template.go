@@ -0,0 +1,16 @@
+package dir
+
+import (
+	"github.com/leonelquinteros/gotext/cli/xgotext/parser"
+)
+
+// register template extractor
+func init() {
+	AddExtractor(&funcExtractor{
+		patterns: (*parser.Settings).TemplatePatterns,
+		extract: func(filePath, basePath string, text []byte, data *parser.DomainMap) error {
+			file := parser.TemplateFile{FilePath: filePath, BasePath: basePath, Data: data}
+			return file.Parse(text)
+		},
+	})
+}
@@ -5,7 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/leonelquinteros/gotext"
 )
 
 // Translation for a text to translate
@@ -14,6 +17,10 @@ type Translation struct {
 	MsgIDPlural     string
 	Context         string
 	SourceLocations []string
+	Flags           []string
+
+	// Extracted comments for translators
+	Comments []string
 }
 
 // AddLocations to translation
@@ -25,75 +32,92 @@ func (t *Translation) AddLocations(locations []string) {
 	}
 }
 
-// Dump translation as string
-func (t *Translation) Dump() string {
-	data := make([]string, 0, len(t.SourceLocations)+5)
-
-	locations := t.SourceLocations
-	sort.Strings(locations)
-	for _, location := range locations {
-		data = append(data, "#: "+location)
-	}
-
-	if t.Context != "" {
-		data = append(data, "msgctxt "+t.Context)
-	}
-
-	data = append(data, toMsgIDString("msgid", t.MsgID))
-
-	if t.MsgIDPlural == "" {
-		data = append(data, "msgstr \"\"")
-	} else {
-		data = append(data, toMsgIDString("msgid_plural", t.MsgIDPlural))
-		data = append(data,
-			"msgstr[0] \"\"",
-			"msgstr[1] \"\"")
-	}
-
-	return strings.Join(data, "\n")
+// AddFlags to translation, skipping the ones already set
+func (t *Translation) AddFlags(flags []string) {
+	t.Flags = appendMissing(t.Flags, flags)
 }
 
-// formatMultiline formats a string as a PO-compatible multiline string.
-// Line breaks are escaped with `\n`.
-func formatMultiline(str string) string {
-	var builder strings.Builder
-	builder.Grow(len(str) * 2)
-
-	builder.WriteRune('"')
+// AddComments to translation, skipping the ones already set
+func (t *Translation) AddComments(comments []string) {
+	t.Comments = appendMissing(t.Comments, comments)
+}
 
-	for _, char := range str {
-		if char == '\n' {
-			builder.WriteString("\\n")
-			continue
+// appendMissing appends to list the values it doesn't have yet
+func appendMissing(list, values []string) []string {
+	for _, v := range values {
+		found := false
+		for _, l := range list {
+			if l == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
 		}
-		builder.WriteRune(char)
 	}
+	return list
+}
 
-	builder.WriteRune('"')
-
-	return builder.String()
+// Dump translation as string
+func (t *Translation) Dump() string {
+	return dumpEntries([]*Translation{t})
 }
 
-// fixSpecialChars escapes special characters (`"` and `\`) in a string.
-func fixSpecialChars(str string) string {
-	var builder strings.Builder
-	builder.Grow(len(str) * 2)
+// toGotext returns the translation as an entry of the gotext package, with sorted locations
+func (t *Translation) toGotext() *gotext.Translation {
+	trans := gotext.NewTranslation()
+	trans.ID = t.MsgID
+	trans.PluralID = t.MsgIDPlural
+	trans.Refs = sortLocations(t.SourceLocations)
+	trans.Flags = t.Flags
+	trans.ExtractedComments = t.Comments
+	return trans
+}
 
-	for _, char := range str {
-		if char == '"' || char == '\\' {
-			builder.WriteRune('\\')
+// sortLocations returns a sorted copy of "file:line" locations, by file and line number
+func sortLocations(locations []string) []string {
+	sorted := append([]string(nil), locations...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		fi, li := splitLocation(sorted[i])
+		fj, lj := splitLocation(sorted[j])
+		if fi != fj {
+			return fi < fj
 		}
-		builder.WriteRune(char)
-	}
+		return li < lj
+	})
+	return sorted
+}
 
-	return builder.String()
+func splitLocation(location string) (string, int) {
+	idx := strings.LastIndex(location, ":")
+	if idx < 0 {
+		return location, 0
+	}
+	line, err := strconv.Atoi(location[idx+1:])
+	if err != nil {
+		return location, 0
+	}
+	return location[:idx], line
 }
 
-// toMsgIDString returns the spec implementation of multi line support of po files by aligning msgid on it.
-func toMsgIDString(prefix, msgID string) string {
-	id := formatMultiline(fixSpecialChars(msgID))
+// newGotextDomain returns a gotext Domain with the translations, and the header entry if not empty
+func newGotextDomain(header string, translations []*Translation) *gotext.Domain {
+	po := gotext.NewPo()
+	if header != "" {
+		po.Parse([]byte(header))
+	}
+	d := po.GetDomain()
+	for _, t := range translations {
+		d.SetTranslation(t.Context, t.toGotext())
+	}
+	return d
+}
 
-	return fmt.Sprintf("%s %s", prefix, id)
+// dumpEntries returns the translations in PO format, without header
+func dumpEntries(translations []*Translation) string {
+	text, _ := newGotextDomain("", translations).MarshalText()
+	return strings.TrimPrefix(string(text), "msgid \"\"\nmsgstr \"\"\n\n")
 }
 
 // TranslationMap contains a map of translations with the ID as key
@@ -101,18 +125,22 @@ type TranslationMap map[string]*Translation
 
 // Dump the translation map as string
 func (m TranslationMap) Dump() string {
-	// sort by translation id for consistence output
+	return dumpEntries(m.list())
+}
+
+// list returns the translations sorted by ID
+func (m TranslationMap) list() []*Translation {
 	keys := make([]string, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 
-	data := make([]string, 0, len(m))
+	list := make([]*Translation, 0, len(m))
 	for _, key := range keys {
-		data = append(data, (m)[key].Dump())
+		list = append(list, m[key])
 	}
-	return strings.Join(data, "\n\n")
+	return list
 }
 
 // Domain holds all translations of one domain
@@ -131,6 +159,8 @@ func (d *Domain) AddTranslation(translation *Translation) {
 	if translation.Context == "" {
 		if t, ok := d.Translations[translation.MsgID]; ok {
 			t.AddLocations(translation.SourceLocations)
+			t.AddFlags(translation.Flags)
+			t.AddComments(translation.Comments)
 		} else {
 			d.Translations[translation.MsgID] = translation
 		}
@@ -141,18 +171,18 @@ func (d *Domain) AddTranslation(translation *Translation) {
 
 		if t, ok := d.ContextTranslations[translation.Context][translation.MsgID]; ok {
 			t.AddLocations(translation.SourceLocations)
+			t.AddFlags(translation.Flags)
+			t.AddComments(translation.Comments)
 		} else {
 			d.ContextTranslations[translation.Context][translation.MsgID] = translation
 		}
 	}
 }
 
-// Dump the domain as string
-func (d *Domain) Dump() string {
-	data := make([]string, 0, len(d.ContextTranslations)+1)
-	data = append(data, d.Translations.Dump())
+// list returns the translations of the domain, without context first, sorted by context and ID
+func (d *Domain) list() []*Translation {
+	list := d.Translations.list()
 
-	// sort context translations by context for consistence output
 	keys := make([]string, 0, len(d.ContextTranslations))
 	for k := range d.ContextTranslations {
 		keys = append(keys, k)
@@ -160,23 +190,53 @@ func (d *Domain) Dump() string {
 	sort.Strings(keys)
 
 	for _, key := range keys {
-		data = append(data, d.ContextTranslations[key].Dump())
+		list = append(list, d.ContextTranslations[key].list()...)
 	}
-	return strings.Join(data, "\n\n")
+	return list
 }
 
-// Save domain to file
-func (d *Domain) Save(path string) error {
-	file, err := os.Create(path)
+// Dump the domain as string
+func (d *Domain) Dump() string {
+	return dumpEntries(d.list())
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the domain as a POT file
+func (d *Domain) MarshalText() ([]byte, error) {
+	return d.marshalText(&POTOptions{}, nil)
+}
+
+// marshalText returns the domain as a POT file written with opts, with the fields of header added
+// to the default header
+func (d *Domain) marshalText(opts *POTOptions, header map[string]string) ([]byte, error) {
+	list := opts.locations(d.list())
+	if opts.OmitHeader {
+		entries := dumpEntries(list)
+		if entries != "" {
+			entries += "\n"
+		}
+		return []byte(entries), nil
+	}
+
+	text, fields, err := opts.header()
 	if err != nil {
-		return fmt.Errorf("failed to domain: %v", err)
+		return nil, err
 	}
-	defer func() {
-		_ = file.Close()
-	}()
+	po := newGotextDomain(text, list)
+	for key, value := range fields {
+		po.Headers.Set(key, value)
+	}
+	for key, value := range header {
+		po.Headers.Set(key, value)
+	}
+	pot, err := po.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return append(pot, '\n'), nil
+}
 
-	// write header
-	_, err = file.WriteString(`msgid ""
+// Header of the files written, also used by Merge for files without one
+const potHeader = `msgid ""
 msgstr ""
 "Plural-Forms: nplurals=2; plural=(n != 1);\n"
 "MIME-Version: 1.0\n"
@@ -185,20 +245,71 @@ msgstr ""
 "Language: \n"
 "X-Generator: xgotext\n"
 
-`)
+`
+
+// Save domain to file
+func (d *Domain) Save(path string) error {
+	return d.save(path, &POTOptions{}, nil)
+}
+
+// save writes the domain to the file at path, with opts and the fields of header added to the default header
+func (d *Domain) save(path string, opts *POTOptions, header map[string]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to domain: %v", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	text, err := d.marshalText(opts, header)
 	if err != nil {
 		return err
 	}
-
-	// write domain content
-	_, err = file.WriteString(d.Dump())
+	_, err = file.Write(text)
 	return err
 }
 
+// Diagnostic is a problem found in the sources, which doesn't stop the extraction
+type Diagnostic struct {
+	// Location of the problem, as "file:line" or the path of a file
+	Pos     string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return d.Pos + ": " + d.Message
+}
+
 // DomainMap contains multiple domains as map with name as key
 type DomainMap struct {
 	Domains map[string]*Domain
 	Default string
+
+	// Options of the POT files written by Save
+	POT POTOptions
+	// Fields of the header of the POT files written by Save, added to or replacing the default ones
+	Header map[string]string
+
+	// Settings of the parsers filling the map, the default settings if nil
+	Settings *Settings
+
+	// Problems found while parsing
+	Diagnostics []Diagnostic
+}
+
+// ParserSettings returns the settings of the parsers filling the map: Settings,
+// or the default settings, changed by the functions of the package, if nil
+func (m *DomainMap) ParserSettings() *Settings {
+	if m.Settings == nil {
+		return &defaultSettings
+	}
+	return m.Settings
+}
+
+// AddDiagnostic reports a problem found at pos
+func (m *DomainMap) AddDiagnostic(pos, format string, args ...any) {
+	m.Diagnostics = append(m.Diagnostics, Diagnostic{Pos: pos, Message: fmt.Sprintf(format, args...)})
 }
 
 // AddTranslation to domain map
@@ -233,7 +344,7 @@ func (m *DomainMap) Save(directory string) error {
 
 	// save each domain in a separate po file
 	for name, domain := range m.Domains {
-		err := domain.Save(filepath.Join(directory, name+".pot"))
+		err := domain.save(filepath.Join(directory, name+".pot"), &m.POT, m.Header)
 		if err != nil {
 			return fmt.Errorf("failed to save domain %s: %v", name, err)
 		}
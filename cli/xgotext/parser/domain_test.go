@@ -1,9 +1,15 @@
 package parser
 
 import (
+	"go/ast"
+	"go/token"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
 )
 
 func TestTranslation_AddLocations(t *testing.T) {
@@ -39,7 +45,7 @@ func TestTranslation_Dump(t *testing.T) {
 
 	tr.Context = "ctx"
 	dump = tr.Dump()
-	if !contains(dump, "msgctxt ctx") {
+	if !contains(dump, "msgctxt \"ctx\"") {
 		t.Error("Dump failed for context translation")
 	}
 }
@@ -124,6 +130,73 @@ func TestDomainMap_Save(t *testing.T) {
 	}
 }
 
+func TestDomainMap_SaveHeader(t *testing.T) {
+	dir := t.TempDir()
+	dm := &DomainMap{
+		POT:    POTOptions{CreationDate: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		Header: map[string]string{"Project-Id-Version": "app 1.0", "Language": "es"},
+	}
+	dm.AddTranslation("", &Translation{MsgID: "msg"})
+	if err := dm.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := os.ReadFile(filepath.Join(dir, "default.pot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "msgid \"\"\nmsgstr \"\"\n\"Project-Id-Version: app 1.0\\n\"\n\"POT-Creation-Date: 2024-03-01 10:30+0000\\n\"\n\"Language: es\\n\"\n"
+	if !strings.HasPrefix(string(text), want) {
+		t.Errorf("Expected header fields in order, got:\n%s", text)
+	}
+}
+
+func TestDomain_MarshalText(t *testing.T) {
+	data := &DomainMap{}
+	g := &GoFile{Data: data}
+
+	calls := [][]*ast.BasicLit{
+		{{Kind: token.STRING, Value: "`Raw \\d+ \"id\"`"}, {Kind: token.STRING, Value: "`raw \\ ctx`"}},
+		{{Kind: token.STRING, Value: `"Tab\tand\nnew line"`}, {Kind: token.STRING, Value: `"say \"hi\""`}},
+		{{Kind: token.STRING, Value: `"No context"`}, {Kind: token.STRING, Value: `""`}},
+	}
+	for i, args := range calls {
+		g.ParseGetter(gotextGetter["GetC"], args, "file.go:"+string(rune('1'+i)))
+	}
+	g.ParseGetter(gotextGetter["GetN"], []*ast.BasicLit{
+		{Kind: token.STRING, Value: `"One \"file\""`},
+		{Kind: token.STRING, Value: `"Many \"files\""`},
+	}, "file.go:9")
+
+	text, err := data.Domains["default"].MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"#: file.go:1\nmsgctxt \"raw \\\\ ctx\"\nmsgid \"Raw \\\\d+ \\\"id\\\"\"",
+		"#: file.go:2\nmsgctxt \"say \\\"hi\\\"\"\nmsgid \"\"\n\"Tab\\tand\\n\"\n\"new line\"",
+		"#: file.go:3\nmsgid \"No context\"",
+		"msgid_plural \"Many \\\"files\\\"\"\nmsgstr[0] \"\"\nmsgstr[1] \"\"",
+	} {
+		if !strings.Contains(string(text), want) {
+			t.Errorf("Expected\n%s\n\nin:\n%s", want, text)
+		}
+	}
+
+	// The output is valid PO
+	po := gotext.NewPo()
+	po.Parse(text)
+	if po.GetC("Raw \\d+ \"id\"", "raw \\ ctx") != "Raw \\d+ \"id\"" || po.GetDomain().GetCtxTranslations()["say \"hi\""]["Tab\tand\nnew line"] == nil {
+		t.Errorf("Contexts weren't parsed back from:\n%s", text)
+	}
+	if _, ok := po.GetDomain().GetTranslations()["Tab\tand\nnew line"]; ok {
+		t.Error("Expected the entry in its context")
+	}
+	if po.GetDomain().Headers.Get("Plural-Forms") == "" {
+		t.Errorf("Expected the Plural-Forms header in:\n%s", text)
+	}
+}
+
 func contains(s, substr string) bool {
 	return (len(s) >= len(substr)) && (s[0:len(substr)] == substr || contains(s[1:], substr))
 }
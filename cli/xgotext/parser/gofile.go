@@ -3,9 +3,9 @@ package parser
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/token"
 	"go/types"
-	"log"
 	"path/filepath"
 	"strconv"
 
@@ -35,6 +35,18 @@ var gotextGetter = map[string]GetterDef{
 	"GetNC":  {0, 1, 3, -1},
 	"GetDC":  {1, -1, 2, 0},
 	"GetNDC": {1, 2, 4, 0},
+	"GetO":   {0, -1, -1, -1},
+	"GetOD":  {1, -1, -1, 0},
+	"GetOC":  {0, -1, 2, -1},
+	"GetODC": {1, -1, 3, 0},
+}
+
+// getters of ordinal translations, which are stored as plural ones
+var ordinalGetter = map[string]bool{
+	"GetO":   true,
+	"GetOD":  true,
+	"GetOC":  true,
+	"GetODC": true,
 }
 
 // GoFile handles the parsing of one go file
@@ -43,10 +55,16 @@ type GoFile struct {
 	BasePath string
 	Data     *DomainMap
 
+	// Syntax tree of the file, for the comments of calls
+	File *ast.File
+
 	FileSet *token.FileSet
 	PkgConf *packages.Config
 
 	ImportedPackages map[string]*packages.Package
+
+	// Comments of File by the line they end on
+	commentLines map[int]*ast.CommentGroup
 }
 
 // GetType from ident object
@@ -104,8 +122,66 @@ func (g *GoFile) CheckType(rawType types.Type) bool {
 	return true
 }
 
+// callQualifiers returns the name of the function or method called by fun, and the qualifiers keywords
+// can use for it: the package path and name for functions, and the receiver type name alone and qualified
+// with the package name and path for methods
+func (g *GoFile) callQualifiers(fun ast.Expr) (string, []string) {
+	var obj types.Object
+	var name string
+	switch f := fun.(type) {
+	case *ast.Ident:
+		obj, name = g.GetType(f), f.Name
+	case *ast.SelectorExpr:
+		obj, name = g.GetType(f.Sel), f.Sel.Name
+
+		// Package functions can be found without type information
+		if obj == nil {
+			if id, ok := f.X.(*ast.Ident); ok && id.Obj == nil {
+				if pkg, ok := g.ImportedPackages[id.Name]; ok {
+					return name, []string{pkg.PkgPath, pkg.Name, id.Name}
+				}
+			}
+		}
+	default:
+		return "", nil
+	}
+
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return name, nil
+	}
+
+	sig, _ := fn.Type().(*types.Signature)
+	if sig == nil || sig.Recv() == nil {
+		return name, []string{fn.Pkg().Path(), fn.Pkg().Name()}
+	}
+
+	recv := sig.Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	named, ok := recv.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return name, nil
+	}
+	typeName := named.Obj().Name()
+	return name, []string{typeName, named.Obj().Pkg().Name() + "." + typeName, named.Obj().Pkg().Path() + "." + typeName}
+}
+
 // InspectCallExpr inspects the call expression
 func (g *GoFile) InspectCallExpr(n *ast.CallExpr) {
+	// calls of keywords
+	if settings := g.Data.ParserSettings(); settings.HasKeywords() {
+		if name, qualifiers := g.callQualifiers(n.Fun); name != "" {
+			if kw := findKeyword(settings.keywords, name, qualifiers); kw != nil {
+				if kw.Total == 0 || kw.Total == len(n.Args) {
+					g.parseGetter(kw.GetterDef, g.callArgs(n), g.callPosition(n), false, g.callComments(n))
+				}
+				return
+			}
+		}
+	}
+
 	// must be a selector expression otherwise it is a local function call
 	expr, ok := n.Fun.(*ast.SelectorExpr)
 	if !ok {
@@ -128,25 +204,87 @@ func (g *GoFile) InspectCallExpr(n *ast.CallExpr) {
 		return
 	}
 
-	// convert args
+	// handle getters
+	if def, ok := gotextGetter[expr.Sel.String()]; ok {
+		g.parseGetter(def, g.callArgs(n), g.callPosition(n), ordinalGetter[expr.Sel.String()], g.callComments(n))
+	}
+}
+
+// callArgs returns the arguments of a call that are literals, nil for the others.
+// Constant string expressions, like "a" + "b" or named constants, are given as string literals.
+func (g *GoFile) callArgs(n *ast.CallExpr) []*ast.BasicLit {
 	args := make([]*ast.BasicLit, len(n.Args))
 	for idx, arg := range n.Args {
-		args[idx], _ = arg.(*ast.BasicLit)
+		if lit, ok := arg.(*ast.BasicLit); ok {
+			args[idx] = lit
+			continue
+		}
+		if str, ok := g.constantString(arg); ok {
+			args[idx] = &ast.BasicLit{ValuePos: arg.Pos(), Kind: token.STRING, Value: strconv.Quote(str)}
+		}
 	}
+	return args
+}
 
-	// get position
-	path, _ := filepath.Rel(g.BasePath, g.FilePath)
-	position := fmt.Sprintf("%s:%d", path, g.FileSet.Position(n.Lparen).Line)
+// constantString returns the value of expr if it's a constant string
+func (g *GoFile) constantString(expr ast.Expr) (string, bool) {
+	for _, pkg := range g.ImportedPackages {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		if tv, ok := pkg.TypesInfo.Types[expr]; ok {
+			if tv.Value == nil || tv.Value.Kind() != constant.String {
+				return "", false
+			}
+			return constant.StringVal(tv.Value), true
+		}
+	}
 
-	// handle getters
-	if def, ok := gotextGetter[expr.Sel.String()]; ok {
-		g.ParseGetter(def, args, position)
-		return
+	// Without type information, literals only
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			str, err := strconv.Unquote(e.Value)
+			return str, err == nil
+		}
+	case *ast.ParenExpr:
+		return g.constantString(e.X)
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			x, ok := g.constantString(e.X)
+			if !ok {
+				return "", false
+			}
+			y, ok := g.constantString(e.Y)
+			return x + y, ok
+		}
 	}
+	return "", false
+}
+
+// callPosition returns the source location of a call, relative to BasePath
+func (g *GoFile) callPosition(n *ast.CallExpr) string {
+	return g.Position(n.Lparen)
+}
+
+// Position returns the source location of pos, relative to BasePath
+func (g *GoFile) Position(pos token.Pos) string {
+	path, _ := filepath.Rel(g.BasePath, g.FilePath)
+	return fmt.Sprintf("%s:%d", path, g.FileSet.Position(pos).Line)
 }
 
 // ParseGetter parses the getter function
 func (g *GoFile) ParseGetter(def GetterDef, args []*ast.BasicLit, pos string) {
+	g.parseGetter(def, args, pos, false, nil)
+}
+
+// ParseOrdinalGetter parses the getter function of an ordinal translation.
+// Its msgid is used as msgid_plural too, and it's flagged as ordinal for translators.
+func (g *GoFile) ParseOrdinalGetter(def GetterDef, args []*ast.BasicLit, pos string) {
+	g.parseGetter(def, args, pos, true, nil)
+}
+
+func (g *GoFile) parseGetter(def GetterDef, args []*ast.BasicLit, pos string, ordinal bool, comments []string) {
 	// check if enough arguments are given
 	if len(args) <= def.MaxArgIndex() {
 		return
@@ -155,12 +293,17 @@ func (g *GoFile) ParseGetter(def GetterDef, args []*ast.BasicLit, pos string) {
 	// get domain
 	var domain string
 	if def.Domain != -1 {
+		// Domain must be a string
+		if args[def.Domain] == nil || args[def.Domain].Kind != token.STRING {
+			g.Data.AddDiagnostic(pos, "unsupported call (Domain not a string)")
+			return
+		}
 		domain, _ = strconv.Unquote(args[def.Domain].Value)
 	}
 
 	// only handle function calls with strings as ID
 	if args[def.ID] == nil || args[def.ID].Kind != token.STRING {
-		log.Printf("ERR: Unsupported call at %s (ID not a string)", pos)
+		g.Data.AddDiagnostic(pos, "unsupported call (ID not a string)")
 		return
 	}
 
@@ -168,23 +311,28 @@ func (g *GoFile) ParseGetter(def GetterDef, args []*ast.BasicLit, pos string) {
 	trans := Translation{
 		MsgID:           msgID,
 		SourceLocations: []string{pos},
+		Comments:        comments,
 	}
-	if def.Plural > 0 {
+	if def.Plural >= 0 {
 		// plural ID must be a string
 		if args[def.Plural] == nil || args[def.Plural].Kind != token.STRING {
-			log.Printf("ERR: Unsupported call at %s (Plural not a string)", pos)
+			g.Data.AddDiagnostic(pos, "unsupported call (Plural not a string)")
 			return
 		}
 		msgIDPlural, _ := strconv.Unquote(args[def.Plural].Value)
 		trans.MsgIDPlural = msgIDPlural
 	}
-	if def.Context > 0 {
+	if ordinal {
+		trans.MsgIDPlural = msgID
+		trans.Flags = []string{"ordinal"}
+	}
+	if def.Context >= 0 {
 		// Context must be a string
 		if args[def.Context] == nil || args[def.Context].Kind != token.STRING {
-			log.Printf("ERR: Unsupported call at %s (Context not a string)", pos)
+			g.Data.AddDiagnostic(pos, "unsupported call (Context not a string)")
 			return
 		}
-		trans.Context = args[def.Context].Value
+		trans.Context, _ = strconv.Unquote(args[def.Context].Value)
 	}
 
 	g.Data.AddTranslation(domain, &trans)
@@ -96,3 +96,31 @@ func TestGoFile_ParseGetter_Errors(t *testing.T) {
 	g.ParseGetter(defGet, args2, "file.go:20")
 }
 
+
+func TestGoFile_ParseOrdinalGetter(t *testing.T) {
+	data := &DomainMap{}
+	g := &GoFile{
+		Data: data,
+	}
+
+	args := []*ast.BasicLit{
+		{Kind: token.STRING, Value: "\"%d. place\""},
+		{Kind: token.INT, Value: "3"},
+		{Kind: token.STRING, Value: "\"race\""},
+	}
+	g.ParseOrdinalGetter(gotextGetter["GetOC"], args, "file.go:10")
+
+	trans := data.Domains["default"].ContextTranslations["race"]["%d. place"]
+	if trans == nil {
+		t.Fatal("ParseOrdinalGetter failed for GetOC")
+	}
+	if trans.MsgIDPlural != "%d. place" {
+		t.Errorf("Expected the msgid to be used as msgid_plural, got %q", trans.MsgIDPlural)
+	}
+	if len(trans.Flags) != 1 || trans.Flags[0] != "ordinal" {
+		t.Errorf("Expected the ordinal flag, got %v", trans.Flags)
+	}
+	if !contains(trans.Dump(), "#, ordinal\nmsgctxt") {
+		t.Errorf("Expected the ordinal flag in the output, got:\n%s", trans.Dump())
+	}
+}
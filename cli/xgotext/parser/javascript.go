@@ -0,0 +1,372 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Functions of the gettext family extracted from scripts, as in gettext.js and Jed.
+// Names may be prefixed with "$", as the methods of vue-gettext.
+var scriptGetter = map[string]GetterDef{
+	"gettext":    {0, -1, -1, -1},
+	"ngettext":   {0, 1, -1, -1},
+	"pgettext":   {1, -1, 0, -1},
+	"npgettext":  {1, 2, 0, -1},
+	"dgettext":   {1, -1, -1, 0},
+	"dngettext":  {1, 2, -1, 0},
+	"dpgettext":  {2, -1, 1, 0},
+	"dnpgettext": {2, 3, 1, 0},
+}
+
+// ScriptFile handles the parsing of one JavaScript or TypeScript file
+type ScriptFile struct {
+	FilePath string
+	BasePath string
+	Data     *DomainMap
+}
+
+// scriptToken is a token of a script: an identifier, a string literal or a punctuation character
+type scriptToken struct {
+	kind byte // 'i' identifier or number, 's' string, 't' template literal with substitutions, 'p' punctuation
+	text string
+	line int
+}
+
+// Parse parses the script text and adds the calls of the gettext functions to Data
+func (s *ScriptFile) Parse(text []byte) error {
+	tokens, err := tokenizeScript(string(text))
+	if err != nil {
+		return err
+	}
+
+	path, _ := filepath.Rel(s.BasePath, s.FilePath)
+	g := &GoFile{Data: s.Data}
+	for i := 0; i+1 < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.kind != 'i' || tokens[i+1].text != "(" {
+			continue
+		}
+		def, ok := scriptGetter[strings.TrimPrefix(tok.text, "$")]
+		if !ok || i > 0 && tokens[i-1].text == "function" {
+			continue
+		}
+
+		args, end := scriptArgs(tokens, i+1)
+		g.parseGetter(def, args, fmt.Sprintf("%s:%d", path, tok.line), false, nil)
+		i = end
+	}
+	return nil
+}
+
+// scriptArgs returns the arguments of the call whose opening parenthesis is tokens[open], as string
+// literals, nil for the ones that aren't strings, and the index of the closing parenthesis
+func scriptArgs(tokens []scriptToken, open int) ([]*ast.BasicLit, int) {
+	var args []*ast.BasicLit
+	var str strings.Builder
+	isString, empty := true, true
+	depth := 0
+
+	i := open + 1
+	for ; i < len(tokens); i++ {
+		tok := tokens[i]
+		if depth == 0 && (tok.text == "," || tok.text == ")") && tok.kind == 'p' {
+			if empty {
+				// Trailing comma
+				if tok.text == ")" {
+					break
+				}
+			}
+			var lit *ast.BasicLit
+			if isString && !empty {
+				lit = &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(str.String())}
+			}
+			args = append(args, lit)
+			str.Reset()
+			isString, empty = true, true
+			if tok.text == ")" {
+				break
+			}
+			continue
+		}
+
+		switch {
+		case tok.kind == 'p' && strings.Contains("([{", tok.text):
+			depth++
+			isString = false
+		case tok.kind == 'p' && strings.Contains(")]}", tok.text):
+			depth--
+			isString = false
+		case tok.kind == 's' && depth == 0:
+			str.WriteString(tok.text)
+		case tok.kind == 'p' && tok.text == "+" && depth == 0:
+			// Concatenation
+		default:
+			isString = false
+		}
+		empty = false
+	}
+	return args, i
+}
+
+// tokenizeScript splits a script into tokens, skipping comments and regular expressions
+func tokenizeScript(src string) ([]scriptToken, error) {
+	var tokens []scriptToken
+	line := 1
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+
+		case c == '"' || c == '\'':
+			str, n, ok := scanScriptString(src[i:])
+			if !ok {
+				// Not a string, like an apostrophe in the text of JSX or Vue templates
+				tokens = append(tokens, scriptToken{kind: 'p', text: string(c), line: line})
+				i++
+				continue
+			}
+			tokens = append(tokens, scriptToken{kind: 's', text: str, line: line})
+			// Line continuations
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+
+		case c == '`':
+			str, n, subst, ok := scanTemplateLiteral(src[i:])
+			if !ok {
+				return nil, fmt.Errorf("line %d: unterminated template literal", line)
+			}
+			kind := byte('s')
+			if subst {
+				kind = 't'
+			}
+			tokens = append(tokens, scriptToken{kind: kind, text: str, line: line})
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+
+		case c == '/' && regexpAllowed(tokens):
+			n := scanRegexp(src[i:])
+			tokens = append(tokens, scriptToken{kind: 'i', text: src[i : i+n], line: line})
+			i += n
+
+		case c == '$' || c == '_' || c >= '0' && c <= '9' || c >= 0x80 || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if r != '$' && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			if i == start {
+				// Any other character
+				_, size := utf8.DecodeRuneInString(src[i:])
+				i += size
+				continue
+			}
+			tokens = append(tokens, scriptToken{kind: 'i', text: src[start:i], line: line})
+
+		default:
+			tokens = append(tokens, scriptToken{kind: 'p', text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// regexpAllowed reports whether a "/" after tokens starts a regular expression rather than a division
+func regexpAllowed(tokens []scriptToken) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	last := tokens[len(tokens)-1]
+	switch last.kind {
+	case 'p':
+		return last.text != ")" && last.text != "]" && last.text != "}"
+	case 'i':
+		switch last.text {
+		case "return", "typeof", "instanceof", "in", "of", "new", "delete", "void", "throw", "case", "do", "else", "yield", "await":
+			return true
+		}
+	}
+	return false
+}
+
+// scanRegexp returns the length of the regular expression literal at the start of src
+func scanRegexp(src string) int {
+	inClass := false
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return i
+		case '/':
+			if !inClass {
+				i++
+				// Flags
+				for i < len(src) && (src[i] >= 'a' && src[i] <= 'z' || src[i] >= 'A' && src[i] <= 'Z') {
+					i++
+				}
+				return i
+			}
+		}
+	}
+	return len(src)
+}
+
+// scanScriptString decodes the single or double quoted string at the start of src,
+// returning its value and length
+func scanScriptString(src string) (string, int, bool) {
+	quote := src[0]
+	var b strings.Builder
+	for i := 1; i < len(src); {
+		c := src[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, true
+		case c == '\n':
+			return "", 0, false
+		case c == '\\':
+			n := unescapeScript(&b, src[i:])
+			if n == 0 {
+				return "", 0, false
+			}
+			i += n
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, false
+}
+
+// scanTemplateLiteral decodes the template literal at the start of src, returning its value, its length
+// and whether it has substitutions, which make its value unknown
+func scanTemplateLiteral(src string) (string, int, bool, bool) {
+	var b strings.Builder
+	subst := false
+	for i := 1; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '`':
+			return b.String(), i + 1, subst, true
+		case c == '\\':
+			n := unescapeScript(&b, src[i:])
+			if n == 0 {
+				return "", 0, false, false
+			}
+			i += n
+		case strings.HasPrefix(src[i:], "${"):
+			subst = true
+			// Skip the expression, which may have nested braces
+			depth := 0
+			for ; i < len(src); i++ {
+				if src[i] == '{' {
+					depth++
+				} else if src[i] == '}' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, false, false
+}
+
+// unescapeScript writes the character of the escape sequence at the start of src to b,
+// returning the length of the sequence, 0 if it's invalid
+func unescapeScript(b *strings.Builder, src string) int {
+	if len(src) < 2 {
+		return 0
+	}
+	switch src[1] {
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'v':
+		b.WriteByte('\v')
+	case '0':
+		b.WriteByte(0)
+	case '\r':
+		// Line continuation
+		if len(src) > 2 && src[2] == '\n' {
+			return 3
+		}
+	case '\n':
+		// Line continuation
+	case 'x':
+		if len(src) < 4 {
+			return 0
+		}
+		n, err := strconv.ParseUint(src[2:4], 16, 8)
+		if err != nil {
+			return 0
+		}
+		b.WriteRune(rune(n))
+		return 4
+	case 'u':
+		hex, size := "", 0
+		if len(src) > 2 && src[2] == '{' {
+			end := strings.IndexByte(src, '}')
+			if end < 0 {
+				return 0
+			}
+			hex, size = src[3:end], end+1
+		} else if len(src) >= 6 {
+			hex, size = src[2:6], 6
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return 0
+		}
+		b.WriteRune(rune(n))
+		return size
+	default:
+		r, size := utf8.DecodeRuneInString(src[1:])
+		b.WriteRune(r)
+		return 1 + size
+	}
+	return 2
+}
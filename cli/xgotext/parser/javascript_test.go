@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const scriptSource = `import { gettext, ngettext } from "./i18n";
+
+// gettext("In a comment")
+/* pgettext("ctx", "In a block comment") */
+function gettext(msgid) { return msgid; }
+
+const re = /gettext\("In a regexp"\)/g;
+const half = total / 2 / gettext("Divided");
+
+export const title = gettext("Hello, " +
+	'world');
+const files = ngettext("%d file", "%d files", count);
+const open = i18n.pgettext("menu", "Open");
+const quote = this.$gettext("Say \"hi\"\n");
+const tpl = gettext(` + "`Template literal`" + `);
+const dynamic = gettext(` + "`Hi ${name}`" + `);
+const other = dgettext("errors", "Not found");
+const variable = gettext(name);
+const nested = gettext("Outer", f(gettext("Inner")));
+`
+
+func TestScriptFile_Parse(t *testing.T) {
+	data := &DomainMap{}
+	file := ScriptFile{FilePath: "web/app.ts", BasePath: ".", Data: data}
+	if err := file.Parse([]byte(scriptSource)); err != nil {
+		t.Fatal(err)
+	}
+
+	d := data.Domains["default"]
+	tests := map[string][]string{
+		"Divided":            {"web/app.ts:8"},
+		"Hello, world":       {"web/app.ts:10"},
+		"%d file":            {"web/app.ts:12"},
+		"Say \"hi\"\n":       {"web/app.ts:14"},
+		"Template literal":   {"web/app.ts:15"},
+		"Outer":              {"web/app.ts:19"},
+		"In a comment":       nil,
+		"In a block comment": nil,
+		"In a regexp":        nil,
+		"Hi ${name}":         nil,
+		"Hi ":                nil,
+	}
+	for id, want := range tests {
+		tr := d.Translations[id]
+		if want == nil {
+			if tr != nil {
+				t.Errorf("Unexpected translation %q", id)
+			}
+			continue
+		}
+		if tr == nil {
+			t.Errorf("Missing translation %q", id)
+			continue
+		}
+		if !reflect.DeepEqual(tr.SourceLocations, want) {
+			t.Errorf("Locations of %q = %v, want %v", id, tr.SourceLocations, want)
+		}
+	}
+
+	if tr := d.Translations["%d file"]; tr.MsgIDPlural != "%d files" {
+		t.Errorf("Expected plural, got %q", tr.MsgIDPlural)
+	}
+	if d.ContextTranslations["menu"]["Open"] == nil {
+		t.Error("Expected context translation")
+	}
+	if data.Domains["errors"] == nil || data.Domains["errors"].Translations["Not found"] == nil {
+		t.Error("Expected domain translation")
+	}
+	// Arguments of the calls are skipped with the call
+	if d.Translations["Inner"] != nil {
+		t.Error("Unexpected nested translation")
+	}
+}
+
+func TestTokenizeScript_Strings(t *testing.T) {
+	tokens, err := tokenizeScript(`'It\'s' "é\x41\u{1F600}" <p>Don't</p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var strs []string
+	for _, tok := range tokens {
+		if tok.kind == 's' {
+			strs = append(strs, tok.text)
+		}
+	}
+	if want := []string{"It's", "éA😀"}; !reflect.DeepEqual(strs, want) {
+		t.Errorf("Strings = %q, want %q", strs, want)
+	}
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Keyword is a function or method whose calls are extracted, defined by an xgettext keyword spec
+type Keyword struct {
+	// Package path or name for functions, receiver type for methods as in "Type", "pkg.Type" or
+	// "path/to/pkg.Type". Empty to match any function or method named Name.
+	Qualifier string
+	Name      string
+
+	// Arguments used, counting from 0, -1 when not used
+	GetterDef
+
+	// Number of arguments calls must have, 0 for any
+	Total int
+
+	// Whether calls are ordinal getters, like GetO, extracted as plural entries flagged "ordinal"
+	ordinal bool
+}
+
+// ParseKeyword parses a keyword spec in xgettext syntax, extended with the domain argument:
+//
+//	[qualifier.]name[:args]
+//
+// where args is a comma separated list of argument numbers, counting from 1: the msgid, then the
+// msgid_plural if any, the context with suffix "c", the domain with suffix "d" and the total number of
+// arguments calls must have with suffix "t". Without args, the msgid is the first argument.
+//
+// Examples: "T", "TN:1,2", "TC:1,2c", "TD:2,3,1d", "i18n.T:1,1t", "(*i18n.Translator).T:1".
+func ParseKeyword(spec string) (*Keyword, error) {
+	name, args, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	kw := &Keyword{
+		GetterDef: GetterDef{ID: -1, Plural: -1, Context: -1, Domain: -1},
+	}
+
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		kw.Qualifier, kw.Name = name[:idx], name[idx+1:]
+		// Accept receivers written as in method expressions
+		kw.Qualifier = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(kw.Qualifier, "("), "*"), ")")
+	} else {
+		kw.Name = name
+	}
+	if kw.Name == "" || kw.Qualifier == "" && strings.Contains(name, ".") {
+		return nil, fmt.Errorf("keyword %q: missing name", spec)
+	}
+
+	if args != "" {
+		for _, arg := range strings.Split(args, ",") {
+			arg = strings.TrimSpace(arg)
+			suffix := byte(0)
+			if arg != "" && strings.IndexByte("cdt", arg[len(arg)-1]) >= 0 {
+				suffix, arg = arg[len(arg)-1], arg[:len(arg)-1]
+			}
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("keyword %q: invalid argument number %q", spec, arg)
+			}
+
+			var field *int
+			switch suffix {
+			case 'c':
+				field = &kw.Context
+			case 'd':
+				field = &kw.Domain
+			case 't':
+				if kw.Total != 0 {
+					return nil, fmt.Errorf("keyword %q: more than one total argument count", spec)
+				}
+				kw.Total = n
+				continue
+			default:
+				field = &kw.ID
+				if kw.ID != -1 {
+					field = &kw.Plural
+				}
+			}
+			if *field != -1 {
+				return nil, fmt.Errorf("keyword %q: too many arguments", spec)
+			}
+			*field = n - 1
+		}
+	}
+	if kw.ID == -1 {
+		kw.ID = 0
+	}
+
+	if kw.Total != 0 && kw.MaxArgIndex() >= kw.Total {
+		return nil, fmt.Errorf("keyword %q: arguments beyond the total of %d", spec, kw.Total)
+	}
+	return kw, nil
+}
+
+// findKeyword returns the keyword of list for a call of name with the qualifiers given, nil if there's none.
+// The last keyword added wins.
+func findKeyword(list []*Keyword, name string, qualifiers []string) *Keyword {
+	for i := len(list) - 1; i >= 0; i-- {
+		kw := list[i]
+		if kw.Name != name {
+			continue
+		}
+		if kw.Qualifier == "" {
+			return kw
+		}
+		for _, q := range qualifiers {
+			if kw.Qualifier == q {
+				return kw
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseKeyword(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Keyword
+	}{
+		{"T", Keyword{Name: "T", GetterDef: GetterDef{0, -1, -1, -1}}},
+		{"T:2", Keyword{Name: "T", GetterDef: GetterDef{1, -1, -1, -1}}},
+		{"TN:1,2", Keyword{Name: "TN", GetterDef: GetterDef{0, 1, -1, -1}}},
+		{"TC:1,2c", Keyword{Name: "TC", GetterDef: GetterDef{0, -1, 1, -1}}},
+		{"TC:2c,1", Keyword{Name: "TC", GetterDef: GetterDef{0, -1, 1, -1}}},
+		{"TD:2,3,1d", Keyword{Name: "TD", GetterDef: GetterDef{1, 2, -1, 0}}},
+		{"T:1,2t", Keyword{Name: "T", GetterDef: GetterDef{0, -1, -1, -1}, Total: 2}},
+		{"i18n.T", Keyword{Qualifier: "i18n", Name: "T", GetterDef: GetterDef{0, -1, -1, -1}}},
+		{"example.com/app/i18n.T:1", Keyword{Qualifier: "example.com/app/i18n", Name: "T", GetterDef: GetterDef{0, -1, -1, -1}}},
+		{"(*i18n.Translator).TNC:1,2,4c", Keyword{Qualifier: "i18n.Translator", Name: "TNC", GetterDef: GetterDef{0, 1, 3, -1}}},
+	}
+	for _, tt := range tests {
+		kw, err := ParseKeyword(tt.spec)
+		if err != nil {
+			t.Errorf("ParseKeyword(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		if *kw != tt.want {
+			t.Errorf("ParseKeyword(%q) = %+v, want %+v", tt.spec, *kw, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", ":1", "pkg.", ".T", "T:0", "T:x", "T:1,2,3", "T:1c,2c", "T:1,2d,3d", "T:1t,2t", "T:1,3,2t"} {
+		if _, err := ParseKeyword(spec); err == nil {
+			t.Errorf("ParseKeyword(%q) didn't fail", spec)
+		}
+	}
+}
+
+func TestFindKeyword(t *testing.T) {
+	settings := &Settings{}
+	for _, spec := range []string{"T", "i18n.TN:1,2", "i18n.Translator.TC:1,2c"} {
+		if err := settings.AddKeyword(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := settings.AddKeyword("T:0"); err == nil {
+		t.Error("Expected an invalid spec to fail")
+	}
+
+	if kw := findKeyword(settings.keywords, "T", nil); kw == nil || kw.Name != "T" {
+		t.Errorf("Expected unqualified keywords to match any call, got %v", kw)
+	}
+	if kw := findKeyword(settings.keywords, "TN", []string{"example.com/i18n", "i18n"}); kw == nil {
+		t.Error("Expected i18n.TN to match the package name")
+	}
+	if kw := findKeyword(settings.keywords, "TN", []string{"example.com/other", "other"}); kw != nil {
+		t.Errorf("Expected i18n.TN not to match other packages, got %v", kw)
+	}
+	if kw := findKeyword(settings.keywords, "TC", []string{"Translator", "i18n.Translator", "example.com/i18n.Translator"}); kw == nil || kw.Context != 1 {
+		t.Errorf("Expected i18n.Translator.TC to match the method, got %v", kw)
+	}
+}
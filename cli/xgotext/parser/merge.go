@@ -0,0 +1,341 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// Minimum similarity of msgids, from 0 to 1, for a translation to be reused as a fuzzy one
+const fuzzyThreshold = 0.6
+
+// mergeEntry is an entry of a merged PO file
+type mergeEntry struct {
+	Context  string
+	MsgID    string
+	PluralID string
+	Trs      map[int]string
+
+	// Extracted entries only
+	Comments  []string
+	Locations []string
+	Flags     []string
+
+	// Entry the translation was taken from when fuzzy matched
+	Previous *mergeEntry
+}
+
+// key identifies the entry by context and msgid
+func (e *mergeEntry) key() string {
+	return e.Context + "\x04" + e.MsgID
+}
+
+// translated reports whether the entry has any translation
+func (e *mergeEntry) translated() bool {
+	for _, tr := range e.Trs {
+		if tr != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// toGotext returns the entry as a gotext Translation
+func (e *mergeEntry) toGotext() *gotext.Translation {
+	trans := gotext.NewTranslation()
+	trans.ID = e.MsgID
+	trans.PluralID = e.PluralID
+	for form, tr := range e.Trs {
+		trans.Trs[form] = tr
+	}
+	trans.Refs = sortLocations(e.Locations)
+	trans.Flags = e.Flags
+	trans.ExtractedComments = e.Comments
+	if p := e.Previous; p != nil {
+		trans.PreviousContext = p.Context
+		trans.PreviousID = p.MsgID
+		trans.PreviousPluralID = p.PluralID
+	}
+	return trans
+}
+
+// Merge updates the PO file at path with the translations of the domain, like msgmerge does:
+// translations and the header of the file are kept, translated entries not extracted anymore are made obsolete,
+// and new entries get the translation of a similar msgid, flagged as fuzzy, when there's one.
+// The file is created if it doesn't exist, and left unchanged if it has malformed lines.
+func (d *Domain) Merge(path string) error {
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	merged, err := d.merge(path, old)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	err = os.WriteFile(path, merged, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
+
+// merge returns old, the contents of the PO file at path, updated with the translations of the domain,
+// or an error if old has malformed lines
+func (d *Domain) merge(path string, old []byte) ([]byte, error) {
+	current, err := gotext.ParseCatalog(path, old)
+	if err != nil {
+		return nil, err
+	}
+	header, obsolete := splitPo(old)
+
+	// Entries of the old file, current ones taking precedence over obsolete ones
+	po := gotext.NewPo()
+	po.Parse(obsolete)
+	oldEntries := make(map[string]*mergeEntry)
+	addPoEntries(oldEntries, po)
+	addPoEntries(oldEntries, current)
+
+	if len(header) == 0 {
+		header = []byte(potHeader)
+	}
+	merged := newGotextDomain(string(header), nil)
+
+	used := make(map[string]bool)
+	for _, e := range d.mergeEntries(oldEntries, used) {
+		merged.SetTranslation(e.Context, e.toGotext())
+	}
+
+	// Left over translations become obsolete
+	leftover := make([]*mergeEntry, 0, len(oldEntries))
+	for key, e := range oldEntries {
+		if !used[key] && e.translated() {
+			leftover = append(leftover, e)
+		}
+	}
+	sort.Slice(leftover, func(i, j int) bool {
+		return leftover[i].key() < leftover[j].key()
+	})
+	for _, e := range leftover {
+		merged.AddObsolete(e.Context, e.toGotext())
+	}
+
+	text, _ := merged.MarshalText()
+	return append(text, '\n'), nil
+}
+
+// mergeEntries returns the entries of the domain, with the translations of
+// oldEntries, and their previous msgids for entries still fuzzy. The keys of the old entries found by msgid,
+// or used as the source of a fuzzy translation, are set in used.
+func (d *Domain) mergeEntries(oldEntries map[string]*mergeEntry, used map[string]bool) []*mergeEntry {
+	translations := d.list()
+	entries := make([]*mergeEntry, 0, len(translations))
+	var unmatched []*mergeEntry
+	for _, t := range translations {
+		e := &mergeEntry{
+			Context:   t.Context,
+			MsgID:     t.MsgID,
+			PluralID:  t.MsgIDPlural,
+			Trs:       make(map[int]string),
+			Comments:  t.Comments,
+			Locations: t.SourceLocations,
+			Flags:     append([]string(nil), t.Flags...),
+		}
+		entries = append(entries, e)
+
+		if o, ok := oldEntries[e.key()]; ok {
+			used[e.key()] = true
+			e.Trs = o.Trs
+			// Translations still to be reviewed stay so
+			if hasFlag(o.Flags, "fuzzy") && e.translated() {
+				e.Flags = append(e.Flags, "fuzzy")
+				e.Previous = o.Previous
+			}
+		} else {
+			unmatched = append(unmatched, e)
+		}
+	}
+
+	// Entries with a new msgid, translated as the most similar old one, which isn't made obsolete then.
+	// An old entry may be the source of several new ones.
+	var sources []string
+	for _, e := range unmatched {
+		var best *mergeEntry
+		bestScore := fuzzyThreshold
+		for key, o := range oldEntries {
+			if used[key] || o.Context != e.Context || !o.translated() {
+				continue
+			}
+			score := similarity(e.MsgID, o.MsgID)
+			if score > bestScore || score == bestScore && best != nil && o.MsgID < best.MsgID {
+				best, bestScore = o, score
+			}
+		}
+		if best != nil {
+			e.Trs = best.Trs
+			e.Flags = append(e.Flags, "fuzzy")
+			e.Previous = best
+			sources = append(sources, best.key())
+		}
+	}
+	for _, key := range sources {
+		used[key] = true
+	}
+
+	return entries
+}
+
+// addPoEntries adds the translations of po to entries
+func addPoEntries(entries map[string]*mergeEntry, po gotext.Translator) {
+	add := func(ctx string, t *gotext.Translation) {
+		if t.ID == "" {
+			return
+		}
+		e := &mergeEntry{
+			Context:  ctx,
+			MsgID:    t.ID,
+			PluralID: t.PluralID,
+			Trs:      t.Trs,
+			Flags:    t.Flags,
+		}
+		if t.PreviousID != "" {
+			e.Previous = &mergeEntry{Context: t.PreviousContext, MsgID: t.PreviousID, PluralID: t.PreviousPluralID}
+		}
+		entries[e.key()] = e
+	}
+
+	for _, t := range po.GetDomain().GetTranslations() {
+		add("", t)
+	}
+	for ctx, translations := range po.GetDomain().GetCtxTranslations() {
+		for _, t := range translations {
+			add(ctx, t)
+		}
+	}
+}
+
+// splitPo returns the header entry of a PO file, with the comments before it, and its obsolete entries
+// without their "#~" prefix.
+func splitPo(data []byte) (header, obsolete []byte) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	// The header is the first entry when its msgid is empty
+	end := 0
+	for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+		end++
+	}
+	for i := 0; i < end; i++ {
+		l := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(l, "#") {
+			continue
+		}
+		if l == `msgid ""` {
+			header = []byte(strings.Join(lines[:end], "\n"))
+		}
+		break
+	}
+
+	var obs []string
+	for _, l := range lines {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "#~") {
+			l = strings.TrimSpace(trimmed[2:])
+			// Previous msgids of obsolete entries
+			if strings.HasPrefix(l, "|") {
+				l = "#" + l
+			}
+			obs = append(obs, l)
+			continue
+		}
+		// Keep entries apart
+		if trimmed == "" {
+			obs = append(obs, "")
+		}
+	}
+	return header, []byte(strings.Join(obs, "\n"))
+}
+
+// similarity returns how similar a and b are, from 0 to 1, based on their edit distance
+func similarity(a, b string) float64 {
+	la, lb := utf8.RuneCountInString(a), utf8.RuneCountInString(b)
+	longest := la
+	if lb > longest {
+		longest = lb
+	}
+	if longest == 0 {
+		return 1
+	}
+	// The distance is at least the difference of lengths
+	if float64(la+lb-longest) < float64(longest)*fuzzyThreshold {
+		return 0
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge updates the existing <lang>/LC_MESSAGES/<domain>.po files of every language directory
+// under directory with the domains, as Domain.Merge does. It returns the files updated.
+func (m *DomainMap) Merge(directory string) ([]string, error) {
+	langs, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output dir: %v", err)
+	}
+
+	var updated []string
+	for _, lang := range langs {
+		if !lang.IsDir() || strings.HasPrefix(lang.Name(), ".") {
+			continue
+		}
+		for _, name := range sortedDomains(m.Domains) {
+			path := filepath.Join(directory, lang.Name(), "LC_MESSAGES", name+".po")
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			if err := m.POT.withLocations(m.Domains[name]).Merge(path); err != nil {
+				return updated, fmt.Errorf("failed to merge domain %s: %v", name, err)
+			}
+			updated = append(updated, path)
+		}
+	}
+	return updated, nil
+}
+
+func sortedDomains(domains map[string]*Domain) []string {
+	names := make([]string, 0, len(domains))
+	for name := range domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
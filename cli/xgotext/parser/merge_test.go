@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const mergeOldPo = `# Spanish translations
+msgid ""
+msgstr ""
+"Language: es\n"
+"Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 ? 1 : 2);\n"
+
+#: main.go:10
+msgid "Hello"
+msgstr "Hola"
+
+#, fuzzy
+msgid "Bye"
+msgstr "Chau"
+
+msgid "Open the file %s"
+msgstr "Abrir el archivo %s"
+
+msgctxt "menu"
+msgid "File"
+msgstr "Archivo"
+
+msgid "%d apple"
+msgid_plural "%d apples"
+msgstr[0] "%d manzana"
+msgstr[1] "%d manzanas"
+msgstr[2] "%d manzanas"
+
+msgid "Removed"
+msgstr "Eliminado"
+
+#~ msgid "Back again"
+#~ msgstr "De vuelta"
+`
+
+func mergeTestDomain() *Domain {
+	d := &Domain{}
+	d.AddTranslation(&Translation{MsgID: "Hello", SourceLocations: []string{"main.go:12"}})
+	d.AddTranslation(&Translation{MsgID: "Bye"})
+	d.AddTranslation(&Translation{MsgID: "Open the file %q"})
+	d.AddTranslation(&Translation{MsgID: "File", Context: "menu"})
+	d.AddTranslation(&Translation{MsgID: "%d apple", MsgIDPlural: "%d apples"})
+	d.AddTranslation(&Translation{MsgID: "%d pear", MsgIDPlural: "%d pears"})
+	d.AddTranslation(&Translation{MsgID: "Back again"})
+	d.AddTranslation(&Translation{MsgID: "Something else entirely"})
+	return d
+}
+
+// mustMerge returns old merged with the translations of d
+func mustMerge(t *testing.T, d *Domain, old string) string {
+	t.Helper()
+	merged, err := d.merge("default.po", []byte(old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(merged)
+}
+
+func TestDomain_Merge(t *testing.T) {
+	merged := mustMerge(t, mergeTestDomain(), mergeOldPo)
+
+	for _, want := range []string{
+		// Header kept as is
+		"# Spanish translations\nmsgid \"\"\nmsgstr \"\"\n\"Language: es\\n\"\n",
+		// Translations kept, with the new locations
+		"#: main.go:12\nmsgid \"Hello\"\nmsgstr \"Hola\"",
+		"#, fuzzy\nmsgid \"Bye\"\nmsgstr \"Chau\"",
+		"msgctxt \"menu\"\nmsgid \"File\"\nmsgstr \"Archivo\"",
+		"msgid \"%d apple\"\nmsgid_plural \"%d apples\"\nmsgstr[0] \"%d manzana\"\nmsgstr[1] \"%d manzanas\"\nmsgstr[2] \"%d manzanas\"",
+		// Renamed entry
+		"#, fuzzy\n#| msgid \"Open the file %s\"\nmsgid \"Open the file %q\"\nmsgstr \"Abrir el archivo %s\"",
+		// New entries, with the plural forms of the language
+		"msgid \"%d pear\"\nmsgid_plural \"%d pears\"\nmsgstr[0] \"\"\nmsgstr[1] \"\"\nmsgstr[2] \"\"",
+		"msgid \"Something else entirely\"\nmsgstr \"\"",
+		// Obsolete entry used again
+		"msgid \"Back again\"\nmsgstr \"De vuelta\"",
+		// Vanished entry
+		"#~ msgid \"Removed\"\n#~ msgstr \"Eliminado\"",
+	} {
+		if !strings.Contains(merged, want) {
+			t.Errorf("Merged file doesn't contain\n%s\n\ngot:\n%s", want, merged)
+		}
+	}
+	if strings.Contains(merged, "main.go:10") {
+		t.Error("Old locations weren't replaced")
+	}
+	if strings.Contains(merged, "#~ msgid \"Back again\"") {
+		t.Error("Entry used again is still obsolete")
+	}
+	if strings.Contains(merged, "#~ msgid \"Open the file %s\"") {
+		t.Error("Entry used for a fuzzy translation is also obsolete")
+	}
+
+	// Merging again changes nothing
+	if again := mustMerge(t, mergeTestDomain(), merged); again != merged {
+		t.Errorf("Merging twice changed the file:\n%s\n\nthen:\n%s", merged, again)
+	}
+}
+
+func TestDomain_Merge_NoFile(t *testing.T) {
+	merged := mustMerge(t, mergeTestDomain(), "")
+	header, _ := newGotextDomain(potHeader, nil).MarshalText()
+	if !strings.HasPrefix(merged, string(header)+"\n\n") {
+		t.Errorf("Expected the default header, got:\n%s", merged)
+	}
+	if strings.Contains(merged, "fuzzy") || strings.Contains(merged, "#~") {
+		t.Errorf("Expected no fuzzy nor obsolete entries, got:\n%s", merged)
+	}
+}
+
+func TestDomain_Merge_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "default.po")
+	old := "msgid \"Hello\"\nmsgstr \"Hola\"\n\nmsgid \"Bye\nmsgstr \"Adiós\"\n"
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := mergeTestDomain().Merge(path)
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("Expected the malformed line to be reported, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != old {
+		t.Errorf("Expected the file to be left unchanged, got:\n%s", data)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"", "", 1, 1},
+		{"Hello", "Hello", 1, 1},
+		{"Open the file %s", "Open the file %q", 0.9, 0.95},
+		{"Hello", "Goodbye, see you", 0, 0.6},
+	}
+	for _, tt := range tests {
+		if s := similarity(tt.a, tt.b); s < tt.min || s > tt.max {
+			t.Errorf("similarity(%q, %q) = %v, want between %v and %v", tt.a, tt.b, s, tt.min, tt.max)
+		}
+	}
+}
+
+func TestDomainMap_Merge(t *testing.T) {
+	dir := t.TempDir()
+	for _, lang := range []string{"es", "fr"} {
+		if err := os.MkdirAll(filepath.Join(dir, lang, "LC_MESSAGES"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "es", "LC_MESSAGES", "default.po")
+	if err := os.WriteFile(path, []byte(mergeOldPo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dm := &DomainMap{Domains: map[string]*Domain{"default": mergeTestDomain()}}
+	updated, err := dm.Merge(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != 1 || updated[0] != path {
+		t.Errorf("Expected only %s to be updated, got %v", path, updated)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "msgid \"Something else entirely\"") {
+		t.Errorf("File wasn't merged:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "fr", "LC_MESSAGES", "default.po")); err == nil {
+		t.Error("Expected no file to be created for fr")
+	}
+}
+
+func TestDomain_Merge_MultilineObsolete(t *testing.T) {
+	old := "msgid \"First\\n\"\n\"second\"\nmsgstr \"\"\n\"Primera\\n\"\n\"segunda\"\n"
+	merged := mustMerge(t, &Domain{}, old)
+	want := "#~ msgid \"\"\n#~ \"First\\n\"\n#~ \"second\"\n#~ msgstr \"\"\n#~ \"Primera\\n\"\n#~ \"segunda\"\n"
+	if !strings.HasSuffix(merged, want) {
+		t.Errorf("Expected\n%s\n\nat the end of:\n%s", want, merged)
+	}
+
+	// Kept obsolete when merged again
+	if again := mustMerge(t, &Domain{}, merged); again != merged {
+		t.Errorf("Merging twice changed the file:\n%s\n\nthen:\n%s", merged, again)
+	}
+}
+
+func TestMergeEntry_ToGotext(t *testing.T) {
+	locations := []string{"main.go:100", "main.go:20", "app.go:3"}
+	e := &mergeEntry{
+		MsgID:     "Hello",
+		Locations: locations,
+		Trs:       map[int]string{0: "Hola"},
+		Previous:  &mergeEntry{Context: "greeting", MsgID: "Hi"},
+	}
+	trans := e.toGotext()
+	if want := []string{"app.go:3", "main.go:20", "main.go:100"}; !reflect.DeepEqual(trans.Refs, want) {
+		t.Errorf("Expected locations %v, got %v", want, trans.Refs)
+	}
+	if locations[0] != "main.go:100" {
+		t.Errorf("Expected the locations of the entry to be left as is, got %v", locations)
+	}
+	if trans.PreviousContext != "greeting" || trans.PreviousID != "Hi" {
+		t.Errorf("Expected the previous msgid of the entry, got %+v", trans)
+	}
+	trans.Trs[0] = "Buenas"
+	if e.Trs[0] != "Hola" {
+		t.Error("Expected the translations of the entry to be copied")
+	}
+}
@@ -1,11 +1,13 @@
 package pkgtree
 
 import (
+	"context"
 	"fmt"
 	"go/ast"
 	"go/token"
-	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 
 	"golang.org/x/tools/go/packages"
@@ -15,28 +17,45 @@ import (
 
 // ParsePkgTree parse go package tree
 func ParsePkgTree(pkgPath string, data *parser.DomainMap, verbose bool) error {
+	var progress func(string)
+	if verbose {
+		progress = func(id string) { fmt.Println(id) }
+	}
+	return ParsePkgTreeContext(context.Background(), pkgPath, data, progress)
+}
+
+// ParsePkgTreeContext parses the go package tree of pkgPath, with locations relative to the working directory,
+// stopping when ctx is done. progress, if not nil, is called with the ID of each package before it's parsed.
+func ParsePkgTreeContext(ctx context.Context, pkgPath string, data *parser.DomainMap, progress func(id string)) error {
 	basePath, err := os.Getwd()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	return pkgParser(pkgPath, basePath, data, verbose)
+	return pkgParser(ctx, pkgPath, basePath, data, progress)
 }
 
-func pkgParser(dirPath, basePath string, data *parser.DomainMap, verbose bool) error {
-	mainPkg, err := loadPackage(dirPath)
+func pkgParser(ctx context.Context, dirPath, basePath string, data *parser.DomainMap, progress func(string)) error {
+	settings := data.ParserSettings()
+	mainPkg, err := loadPackage(ctx, dirPath, settings)
 	if err != nil {
 		return err
 	}
 
-	for _, pkg := range filterPkgs(mainPkg) {
-		if verbose {
-			fmt.Println(pkg.ID)
+	// Packages of the tree by ID, of this run only
+	pkgCache := make(map[string]*packages.Package)
+	for _, pkg := range filterPkgs(mainPkg, pkgCache, settings) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(pkg.ID)
 		}
 		for _, node := range pkg.Syntax {
 			file := GoFile{
 				parser.GoFile{
 					FilePath: pkg.Fset.Position(node.Package).Filename,
 					BasePath: basePath,
+					File:     node,
 					Data:     data,
 					FileSet:  pkg.Fset,
 
@@ -44,18 +63,37 @@ func pkgParser(dirPath, basePath string, data *parser.DomainMap, verbose bool) e
 						pkg.Name: pkg,
 					},
 				},
+				pkgCache,
 			}
 
 			ast.Inspect(node, file.InspectFile)
 		}
+
+		// Templates embedded with go:embed
+		for _, path := range pkg.EmbedFiles {
+			if !settings.IsTemplate(path) {
+				continue
+			}
+			text, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			file := parser.TemplateFile{
+				FilePath: path,
+				BasePath: basePath,
+				Data:     data,
+			}
+			if err := file.Parse(text); err != nil {
+				rel, _ := filepath.Rel(basePath, path)
+				data.AddDiagnostic(rel, "failed to parse template: %v", err)
+			}
+		}
 	}
 
 	return nil
 }
 
-var pkgCache = make(map[string]*packages.Package)
-
-func loadPackage(name string) (*packages.Package, error) {
+func loadPackage(ctx context.Context, name string, settings *parser.Settings) (*packages.Package, error) {
 	fileSet := token.NewFileSet()
 	conf := &packages.Config{
 		Mode: packages.NeedName |
@@ -64,9 +102,13 @@ func loadPackage(name string) (*packages.Package, error) {
 			packages.NeedTypes |
 			packages.NeedTypesInfo |
 			packages.NeedImports |
-			packages.NeedDeps,
-		Fset: fileSet,
-		Dir:  name,
+			packages.NeedDeps |
+			packages.NeedModule |
+			packages.NeedEmbedFiles,
+		Context:    ctx,
+		Fset:       fileSet,
+		Dir:        name,
+		BuildFlags: settings.BuildFlags(),
 	}
 	pkgs, err := packages.Load(conf)
 	if err != nil {
@@ -76,22 +118,36 @@ func loadPackage(name string) (*packages.Package, error) {
 	return pkgs[0], nil
 }
 
-func filterPkgs(pkg *packages.Package) []*packages.Package {
-	result := filterPkgsRec(pkg)
+// filterPkgs returns the packages to parse: the ones importing gotext and, when there are keywords,
+// the ones of the same module as pkg, which may call wrappers of gotext. Every package of the tree is added to cache.
+func filterPkgs(pkg *packages.Package, cache map[string]*packages.Package, settings *parser.Settings) []*packages.Package {
+	result := filterPkgsRec(pkg, pkg.Module, cache, settings)
 	return result
 }
 
-func filterPkgsRec(pkg *packages.Package) []*packages.Package {
+func filterPkgsRec(pkg *packages.Package, module *packages.Module, cache map[string]*packages.Package, settings *parser.Settings) []*packages.Package {
 	result := make([]*packages.Package, 0, 100)
-	pkgCache[pkg.ID] = pkg
-	for _, importedPkg := range pkg.Imports {
-		if importedPkg.ID == "github.com/leonelquinteros/gotext" {
+	cache[pkg.ID] = pkg
+
+	sameModule := settings.HasKeywords() && module != nil && pkg.Module != nil && pkg.Module.Path == module.Path
+	if sameModule {
+		result = append(result, pkg)
+	}
+	// Imports in a stable order, so comments of calls are found in the same order
+	paths := make([]string, 0, len(pkg.Imports))
+	for path := range pkg.Imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		importedPkg := pkg.Imports[path]
+		if importedPkg.ID == "github.com/leonelquinteros/gotext" && !sameModule {
 			result = append(result, pkg)
 		}
-		if _, ok := pkgCache[importedPkg.ID]; ok {
+		if _, ok := cache[importedPkg.ID]; ok {
 			continue
 		}
-		result = append(result, filterPkgsRec(importedPkg)...)
+		result = append(result, filterPkgsRec(importedPkg, module, cache, settings)...)
 	}
 	return result
 }
@@ -99,11 +155,14 @@ func filterPkgsRec(pkg *packages.Package) []*packages.Package {
 // GoFile handles the parsing of one go file
 type GoFile struct {
 	parser.GoFile
+
+	// Packages of the tree by ID
+	pkgCache map[string]*packages.Package
 }
 
 // GetPackage loads module by name
 func (g *GoFile) GetPackage(name string) (*packages.Package, error) {
-	pkg, ok := pkgCache[name]
+	pkg, ok := g.pkgCache[name]
 	if !ok {
 		return nil, fmt.Errorf("not found in cache")
 	}
@@ -119,7 +178,7 @@ func (g *GoFile) InspectFile(n ast.Node) bool {
 
 		pkg, err := g.GetPackage(packageName)
 		if err != nil {
-			log.Printf("failed to load package %s: %s", packageName, err)
+			g.Data.AddDiagnostic(g.Position(x.Pos()), "failed to load package %s: %s", packageName, err)
 		} else {
 			if x.Name == nil {
 				g.ImportedPackages[pkg.Name] = pkg
@@ -46,3 +46,80 @@ EOL`, "multline\nending with EOL\n", "type alias", "locale constructor call",
 		}
 	}
 }
+
+func TestParsePkgTree_Keywords(t *testing.T) {
+	settings := &parser.Settings{}
+	for _, spec := range []string{"keywords.T:1,1t", "keywords.TN:1,2", "TC:1,2c", "(*keywords.Translator).TD:2,3,1d", "Translator.T"} {
+		if err := settings.AddKeyword(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := &parser.DomainMap{
+		Default:  "default",
+		Settings: settings,
+	}
+	currentPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgPath := filepath.Join(filepath.Dir(filepath.Dir(currentPath)), "fixtures", "keywords")
+	if err = ParsePkgTree(pkgPath, data, false); err != nil {
+		t.Fatal(err)
+	}
+
+	translations := data.Domains["default"].Translations
+	for _, id := range []string{"keyword call", "one keyword", "method keyword"} {
+		if _, ok := translations[id]; !ok {
+			t.Errorf("translation '%v' not in result", id)
+		}
+	}
+	if tr := translations["one keyword"]; tr != nil && tr.MsgIDPlural != "%d keywords" {
+		t.Errorf("Expected msgid_plural '%%d keywords', got %q", tr.MsgIDPlural)
+	}
+	for _, id := range []string{"keyword call with %d argument", "not a keyword"} {
+		if _, ok := translations[id]; ok {
+			t.Errorf("translation '%v' shouldn't be in result", id)
+		}
+	}
+
+	if len(data.Domains["default"].ContextTranslations) != 1 {
+		t.Errorf("Expected one context, got %v", data.Domains["default"].ContextTranslations)
+	}
+	if tr := data.Domains["kwdomain"]; tr == nil || tr.Translations["domain keyword"] == nil || tr.Translations["domain keyword"].MsgIDPlural != "domain keywords" {
+		t.Errorf("Expected 'domain keyword' in the kwdomain domain, got %v", tr)
+	}
+}
+
+func TestParsePkgTree_Templates(t *testing.T) {
+	settings := &parser.Settings{}
+	if err := settings.AddTemplateKeyword("gettext"); err != nil {
+		t.Fatal(err)
+	}
+
+	data := &parser.DomainMap{
+		Default:  "default",
+		Settings: settings,
+	}
+	currentPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgPath := filepath.Join(filepath.Dir(filepath.Dir(currentPath)), "fixtures", "templates")
+	if err = ParsePkgTree(pkgPath, data, false); err != nil {
+		t.Fatal(err)
+	}
+
+	translations := data.Domains["default"].Translations
+	for _, id := range []string{"templates package", "Sign in", "%d message", "Sign out"} {
+		if _, ok := translations[id]; !ok {
+			t.Errorf("translation '%v' not in result", id)
+		}
+	}
+	if tr := translations["Sign in"]; tr != nil && (len(tr.SourceLocations) != 1 || filepath.Base(tr.SourceLocations[0]) != "page.gohtml:2") {
+		t.Errorf("Expected the template location, got %v", tr.SourceLocations)
+	}
+	if _, ok := translations["not a template"]; ok {
+		t.Error("Expected files not matching the template patterns to be skipped")
+	}
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocationMode tells how source locations are written in POT files, as xgettext --add-location does
+type LocationMode string
+
+// Location modes
+const (
+	LocationFull  LocationMode = "full"  // "#: file:line", the default
+	LocationFile  LocationMode = "file"  // "#: file"
+	LocationNever LocationMode = "never" // no locations
+)
+
+// ParseLocationMode returns the location mode named s
+func ParseLocationMode(s string) (LocationMode, error) {
+	switch mode := LocationMode(s); mode {
+	case LocationFull, LocationFile, LocationNever:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid location mode %q, expected full, file or never", s)
+}
+
+// POTOptions are the options of the POT files written by DomainMap.Save
+type POTOptions struct {
+	// Package name and version, for the Project-Id-Version field
+	PackageName    string
+	PackageVersion string
+	// Address to report bugs in the msgids to, for the Report-Msgid-Bugs-To field
+	BugsAddress string
+	// Copyright holder, for the comment of the header
+	CopyrightHolder string
+
+	// Date of the POT-Creation-Date field. If zero, the date of SOURCE_DATE_EPOCH is used if set,
+	// so reproducible builds write identical files, and the current time otherwise.
+	CreationDate time.Time
+
+	// Whether the header entry is left out
+	OmitHeader bool
+	// How source locations are written, LocationFull if empty
+	Locations LocationMode
+}
+
+// creationDate returns the date of the POT-Creation-Date field
+func (o *POTOptions) creationDate() (time.Time, error) {
+	if !o.CreationDate.IsZero() {
+		return o.CreationDate, nil
+	}
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		sec, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q", epoch)
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	return time.Now(), nil
+}
+
+// header returns the header entry of POT files, and its fields
+func (o *POTOptions) header() (string, map[string]string, error) {
+	date, err := o.creationDate()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var b strings.Builder
+	if o.CopyrightHolder != "" {
+		fmt.Fprintf(&b, "# Copyright (C) %d %s\n", date.Year(), o.CopyrightHolder)
+		if o.PackageName != "" {
+			fmt.Fprintf(&b, "# This file is distributed under the same license as the %s package.\n", o.PackageName)
+		}
+	}
+	b.WriteString(potHeader)
+
+	fields := map[string]string{
+		"POT-Creation-Date": date.Format("2006-01-02 15:04-0700"),
+	}
+	if id := strings.TrimSpace(o.PackageName + " " + o.PackageVersion); id != "" {
+		fields["Project-Id-Version"] = id
+	}
+	if o.BugsAddress != "" {
+		fields["Report-Msgid-Bugs-To"] = o.BugsAddress
+	}
+	return b.String(), fields, nil
+}
+
+// locations returns the translations with their source locations written as set by Locations
+func (o *POTOptions) locations(translations []*Translation) []*Translation {
+	if o.Locations != LocationFile && o.Locations != LocationNever {
+		return translations
+	}
+
+	result := make([]*Translation, len(translations))
+	for i, t := range translations {
+		trans := *t
+		trans.SourceLocations = nil
+		if o.Locations == LocationFile {
+			for _, location := range t.SourceLocations {
+				file, _ := splitLocation(location)
+				trans.SourceLocations = appendMissing(trans.SourceLocations, []string{file})
+			}
+		}
+		result[i] = &trans
+	}
+	return result
+}
+
+// withLocations returns the domain with its source locations written as set by Locations
+func (o *POTOptions) withLocations(d *Domain) *Domain {
+	if o.Locations != LocationFile && o.Locations != LocationNever {
+		return d
+	}
+	result := &Domain{}
+	for _, t := range o.locations(d.list()) {
+		result.AddTranslation(t)
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func potTestDomain() *Domain {
+	d := &Domain{}
+	d.AddTranslation(&Translation{MsgID: "Hello", SourceLocations: []string{"main.go:12", "main.go:5", "cmd/app.go:3"}})
+	d.AddTranslation(&Translation{MsgID: "Bye", SourceLocations: []string{"main.go:20"}})
+	return d
+}
+
+func TestDomain_MarshalText_Header(t *testing.T) {
+	opts := &POTOptions{
+		PackageName:     "app",
+		PackageVersion:  "1.2.0",
+		BugsAddress:     "i18n@example.com",
+		CopyrightHolder: "Example Inc.",
+		CreationDate:    time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+	}
+	text, err := potTestDomain().marshalText(opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# Copyright (C) 2024 Example Inc.
+# This file is distributed under the same license as the app package.
+msgid ""
+msgstr ""
+"Project-Id-Version: app 1.2.0\n"
+"Report-Msgid-Bugs-To: i18n@example.com\n"
+"POT-Creation-Date: 2024-03-01 10:30+0000\n"
+`
+	if !strings.HasPrefix(string(text), want) {
+		t.Errorf("Expected the header to start with\n%s\ngot:\n%s", want, text)
+	}
+}
+
+func TestDomain_MarshalText_SourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	first, err := potTestDomain().marshalText(&POTOptions{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(first), `"POT-Creation-Date: 2023-11-14 22:13+0000\n"`) {
+		t.Errorf("Expected the date of SOURCE_DATE_EPOCH, got:\n%s", first)
+	}
+	second, err := potTestDomain().marshalText(&POTOptions{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Error("Expected identical output with SOURCE_DATE_EPOCH")
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, err := potTestDomain().marshalText(&POTOptions{}, nil); err == nil {
+		t.Error("Expected an error for an invalid SOURCE_DATE_EPOCH")
+	}
+}
+
+func TestDomain_MarshalText_Locations(t *testing.T) {
+	tests := []struct {
+		mode LocationMode
+		want string
+	}{
+		{"", "#: cmd/app.go:3 main.go:5 main.go:12\nmsgid \"Hello\""},
+		{LocationFull, "#: cmd/app.go:3 main.go:5 main.go:12\nmsgid \"Hello\""},
+		{LocationFile, "#: cmd/app.go main.go\nmsgid \"Hello\""},
+		{LocationNever, "msgstr \"\"\n\nmsgid \"Hello\""},
+	}
+	for _, tt := range tests {
+		text, err := potTestDomain().marshalText(&POTOptions{Locations: tt.mode, OmitHeader: true}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(text), tt.want) {
+			t.Errorf("Locations %q: expected\n%s\n\nin:\n%s", tt.mode, tt.want, text)
+		}
+		if tt.mode == LocationNever && strings.Contains(string(text), "#:") {
+			t.Errorf("Locations %q: unexpected locations in:\n%s", tt.mode, text)
+		}
+	}
+}
+
+func TestDomain_MarshalText_OmitHeader(t *testing.T) {
+	text, err := potTestDomain().marshalText(&POTOptions{OmitHeader: true}, map[string]string{"Language": "es"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(text), `msgid ""`) || !strings.HasPrefix(string(text), "#: ") {
+		t.Errorf("Expected no header entry, got:\n%s", text)
+	}
+}
+
+func TestParseLocationMode(t *testing.T) {
+	for _, s := range []string{"full", "file", "never"} {
+		if mode, err := ParseLocationMode(s); err != nil || string(mode) != s {
+			t.Errorf("ParseLocationMode(%q) = %q, %v", s, mode, err)
+		}
+	}
+	if _, err := ParseLocationMode("line"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
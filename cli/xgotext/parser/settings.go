@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Settings tell the parsers what to extract besides the gotext getters, and how to load packages.
+// The zero value extracts the gotext getters of Go files and templates named "*.tmpl" and "*.gohtml".
+// The parsers use the settings of the DomainMap they fill (see DomainMap.ParserSettings).
+type Settings struct {
+	keywords         []*Keyword
+	templatePatterns []string
+	templateKeywords []*Keyword
+	configKeys       [][]string
+	extractComments  bool
+	commentTag       string
+	buildTags        []string
+
+	// Whether defaultTemplateKeywords were removed with an empty template keyword
+	noDefaultTemplateKeywords bool
+}
+
+// Settings changed by the functions of the package, used by the maps without settings of their own
+var defaultSettings Settings
+
+// Template methods extracted unless removed: the gotext getters called on a T field, as in {{ .T.Get "Sign in" }}
+var defaultTemplateKeywords = func() []*Keyword {
+	names := make([]string, 0, len(gotextGetter))
+	for name := range gotextGetter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keywords := make([]*Keyword, len(names))
+	for i, name := range names {
+		keywords[i] = &Keyword{Qualifier: "T", Name: name, GetterDef: gotextGetter[name], ordinal: ordinalGetter[name]}
+	}
+	return keywords
+}()
+
+// DefaultSettings returns a copy of the settings changed by the functions of the package,
+// like AddKeyword. Changing the copy doesn't change them.
+func DefaultSettings() *Settings {
+	s := defaultSettings
+	// Clipped, so appends to the copy don't overwrite the defaults
+	s.keywords, s.templateKeywords = slices.Clip(s.keywords), slices.Clip(s.templateKeywords)
+	s.templatePatterns, s.configKeys = slices.Clip(s.templatePatterns), slices.Clip(s.configKeys)
+	s.buildTags = slices.Clip(s.buildTags)
+	return &s
+}
+
+// AddKeyword adds a function or method to extract, given as a keyword spec (see ParseKeyword).
+// Keywords are checked before the gotext getters, so they can also redefine those.
+func (s *Settings) AddKeyword(spec string) error {
+	kw, err := ParseKeyword(spec)
+	if err != nil {
+		return err
+	}
+	s.keywords = append(s.keywords, kw)
+	return nil
+}
+
+// HasKeywords reports whether keywords were added with AddKeyword
+func (s *Settings) HasKeywords() bool {
+	return len(s.keywords) > 0
+}
+
+// AddTemplatePattern adds a glob pattern, like "*.html", of template files to parse.
+// Files named "*.tmpl" and "*.gohtml" are always parsed.
+func (s *Settings) AddTemplatePattern(pattern string) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("template pattern %q: %v", pattern, err)
+	}
+	s.templatePatterns = append(s.templatePatterns, pattern)
+	return nil
+}
+
+// TemplatePatterns returns the glob patterns of the template files to parse
+func (s *Settings) TemplatePatterns() []string {
+	return append([]string{"*.tmpl", "*.gohtml"}, s.templatePatterns...)
+}
+
+// IsTemplate reports whether the file at path is a template to parse
+func (s *Settings) IsTemplate(path string) bool {
+	name := filepath.Base(path)
+	for _, pattern := range s.TemplatePatterns() {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTemplateKeyword adds a template function or method to extract, given as a keyword spec (see ParseKeyword).
+// Functions are matched by name, as "gettext" in {{ gettext "Sign in" }}. Methods are matched by name, or
+// qualified with the field they're called on, as "Locale.Get" for {{ .Locale.Get "Sign in" }}.
+// The gotext getters called on a T field, as "T.Get" and "T.GetN", are extracted by default. Keywords added
+// take precedence over them, and an empty spec removes them, as xgettext does for an empty -k.
+func (s *Settings) AddTemplateKeyword(spec string) error {
+	if strings.TrimSpace(spec) == "" {
+		s.noDefaultTemplateKeywords = true
+		return nil
+	}
+	kw, err := ParseKeyword(spec)
+	if err != nil {
+		return err
+	}
+	s.templateKeywords = append(s.templateKeywords, kw)
+	return nil
+}
+
+// templateKeyword returns the template keyword matching the function or method name, called on
+// one of qualifiers, nil if there's none
+func (s *Settings) templateKeyword(name string, qualifiers []string) *Keyword {
+	if kw := findKeyword(s.templateKeywords, name, qualifiers); kw != nil || s.noDefaultTemplateKeywords {
+		return kw
+	}
+	return findKeyword(defaultTemplateKeywords, name, qualifiers)
+}
+
+// AddConfigKey adds a key whose string values are extracted from JSON and YAML files.
+// A key is either a name, like "title", matched at any depth, or a dotted path from the root
+// of the document, like "menu.*.label", whose elements may be glob patterns.
+// Arrays are transparent: "menu.label" matches the labels of the objects of a "menu" array.
+func (s *Settings) AddConfigKey(key string) error {
+	elems := strings.Split(key, ".")
+	for _, elem := range elems {
+		if _, err := path.Match(elem, ""); err != nil || elem == "" {
+			return fmt.Errorf("invalid config key %q", key)
+		}
+	}
+	s.configKeys = append(s.configKeys, elems)
+	return nil
+}
+
+// HasConfigKeys reports whether config keys were added with AddConfigKey
+func (s *Settings) HasConfigKeys() bool {
+	return len(s.configKeys) > 0
+}
+
+// ExtractComments makes calls keep the comment right before them, or ending on their line, as extracted
+// comments for translators, like xgettext --add-comments does. With a tag, like "TRANSLATORS:", only
+// comments starting with it are kept, from the tag on.
+func (s *Settings) ExtractComments(tag string) {
+	s.extractComments = true
+	s.commentTag = strings.TrimSpace(tag)
+}
+
+// SetBuildTags sets the build tags used to load packages, as with go build -tags
+func (s *Settings) SetBuildTags(tags []string) {
+	s.buildTags = tags
+}
+
+// BuildFlags returns the build flags used to load packages
+func (s *Settings) BuildFlags() []string {
+	if len(s.buildTags) == 0 {
+		return nil
+	}
+	return []string{"-tags=" + strings.Join(s.buildTags, ",")}
+}
+
+// AddKeyword adds a keyword to the default settings (see Settings.AddKeyword)
+func AddKeyword(spec string) error {
+	return defaultSettings.AddKeyword(spec)
+}
+
+// AddTemplatePattern adds a template pattern to the default settings (see Settings.AddTemplatePattern)
+func AddTemplatePattern(pattern string) error {
+	return defaultSettings.AddTemplatePattern(pattern)
+}
+
+// AddTemplateKeyword adds a template keyword to the default settings (see Settings.AddTemplateKeyword)
+func AddTemplateKeyword(spec string) error {
+	return defaultSettings.AddTemplateKeyword(spec)
+}
+
+// AddConfigKey adds a config key to the default settings (see Settings.AddConfigKey)
+func AddConfigKey(key string) error {
+	return defaultSettings.AddConfigKey(key)
+}
+
+// ExtractComments makes the default settings extract comments for translators (see Settings.ExtractComments)
+func ExtractComments(tag string) {
+	defaultSettings.ExtractComments(tag)
+}
+
+// SetBuildTags sets the build tags of the default settings (see Settings.SetBuildTags)
+func SetBuildTags(tags []string) {
+	defaultSettings.SetBuildTags(tags)
+}
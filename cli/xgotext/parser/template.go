@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// TemplateFile handles the parsing of one text/template or html/template file
+type TemplateFile struct {
+	FilePath string
+	BasePath string
+	Data     *DomainMap
+
+	tree *parse.Tree
+}
+
+// Parse parses the template text and adds its translations to Data
+func (t *TemplateFile) Parse(text []byte) error {
+	trees := make(map[string]*parse.Tree)
+	tree := parse.New(t.FilePath)
+	// Functions are provided by the application
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(string(text), "", "", trees); err != nil {
+		return err
+	}
+
+	// Templates defined in the file, in a stable order
+	names := make([]string, 0, len(trees))
+	for name := range trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t.tree = trees[name]
+		t.inspect(t.tree.Root)
+	}
+	return nil
+}
+
+// inspect looks for calls to extract in the node and its children
+func (t *TemplateFile) inspect(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			t.inspect(child)
+		}
+	case *parse.ActionNode:
+		t.inspect(n.Pipe)
+	case *parse.IfNode:
+		t.inspectBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		t.inspectBranch(&n.BranchNode)
+	case *parse.WithNode:
+		t.inspectBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		t.inspect(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			var piped parse.Node
+			if i > 0 && len(n.Cmds[i-1].Args) == 1 {
+				piped = n.Cmds[i-1].Args[0]
+			}
+			t.inspectCommand(cmd, piped)
+		}
+	}
+}
+
+func (t *TemplateFile) inspectBranch(n *parse.BranchNode) {
+	t.inspect(n.Pipe)
+	t.inspect(n.List)
+	t.inspect(n.ElseList)
+}
+
+// inspectCommand extracts the command if it's a call to extract, and inspects its arguments.
+// piped is the value of the previous command of the pipeline, passed as last argument, if any.
+func (t *TemplateFile) inspectCommand(cmd *parse.CommandNode, piped parse.Node) {
+	for _, arg := range cmd.Args {
+		if pipe, ok := arg.(*parse.PipeNode); ok {
+			t.inspect(pipe)
+		}
+	}
+	if len(cmd.Args) == 0 {
+		return
+	}
+
+	// Name of the function or method, and the field the method is called on
+	var name, field string
+	switch fn := cmd.Args[0].(type) {
+	case *parse.IdentifierNode:
+		name = fn.Ident
+	case *parse.FieldNode:
+		name, field = lastIdents(fn.Ident)
+	case *parse.VariableNode:
+		name, field = lastIdents(fn.Ident)
+	case *parse.ChainNode:
+		name, field = lastIdents(fn.Field)
+	default:
+		return
+	}
+
+	nodes := cmd.Args[1:]
+	if piped != nil {
+		nodes = append(nodes[:len(nodes):len(nodes)], piped)
+	}
+	def, ordinal, ok := t.getterDef(name, field, len(nodes))
+	if !ok {
+		return
+	}
+
+	args := make([]*ast.BasicLit, len(nodes))
+	for idx, arg := range nodes {
+		if str, ok := arg.(*parse.StringNode); ok {
+			args[idx] = &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(str.Text)}
+		}
+	}
+
+	g := &GoFile{Data: t.Data}
+	g.parseGetter(def, args, t.position(cmd), ordinal, nil)
+}
+
+// getterDef returns the arguments of the function or method name, called on field if not empty with
+// nargs arguments, and whether it's an ordinal getter. ok is false if it isn't extracted.
+func (t *TemplateFile) getterDef(name, field string, nargs int) (def GetterDef, ordinal, ok bool) {
+	var qualifiers []string
+	if field != "" {
+		qualifiers = []string{field}
+	}
+	kw := t.Data.ParserSettings().templateKeyword(name, qualifiers)
+	if kw == nil {
+		return def, false, false
+	}
+	return kw.GetterDef, kw.ordinal, kw.Total == 0 || kw.Total == nargs
+}
+
+// position returns the source location of the node, relative to BasePath
+func (t *TemplateFile) position(node parse.Node) string {
+	path, _ := filepath.Rel(t.BasePath, t.FilePath)
+	location, _ := t.tree.ErrorContext(node)
+	// location is "name:line:col"
+	parts := strings.Split(location, ":")
+	line := "0"
+	if len(parts) >= 3 {
+		line = parts[len(parts)-2]
+	}
+	return path + ":" + line
+}
+
+// lastIdents returns the last identifier of a field chain, and the one before it if any
+func lastIdents(idents []string) (name, field string) {
+	if len(idents) == 0 {
+		return "", ""
+	}
+	name = idents[len(idents)-1]
+	if len(idents) > 1 {
+		field = idents[len(idents)-2]
+	}
+	return name, field
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const templateSource = `{{ define "header" }}<h1>{{ .T.Get "Welcome" }}</h1>{{ end }}
+{{ template "header" . }}
+{{ if .User }}
+	{{ .T.GetN "%d message" "%d messages" .Count }}
+{{ else }}
+	{{ $.Locale.GetC "Sign in" "button" }}
+{{ end }}
+{{ range .Items }}{{ gettext "Item" }}{{ end }}
+{{ "Piped" | gettext }}
+{{ printf "%s" (gettext "Nested") }}
+{{ .T.GetD "admin" "Admin" }}
+{{ .T.Get .Dynamic }}
+{{ other "Not extracted" }}
+{{ .Helper.Tr "Method keyword" }}
+{{ .Other.Tr "Other field" }}
+{{ .T.GetO "%d. place" 3 }}
+{{ .Cache.Get "Cache key" }}
+{{ with .T }}{{ .Get "Unqualified getter" }}{{ end }}
+`
+
+func TestTemplateFile_Parse(t *testing.T) {
+	settings := &Settings{}
+	for _, spec := range []string{"gettext", "Helper.Tr", "Locale.GetC:1,2c"} {
+		if err := settings.AddTemplateKeyword(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := &DomainMap{Settings: settings}
+	file := &TemplateFile{FilePath: "views/page.tmpl", BasePath: ".", Data: data}
+	if err := file.Parse([]byte(templateSource)); err != nil {
+		t.Fatal(err)
+	}
+
+	translations := data.Domains["default"].Translations
+	tests := map[string][]string{
+		"Welcome":        {"views/page.tmpl:1"},
+		"%d message":     {"views/page.tmpl:4"},
+		"Item":           {"views/page.tmpl:8"},
+		"Piped":          {"views/page.tmpl:9"},
+		"Nested":         {"views/page.tmpl:10"},
+		"Method keyword": {"views/page.tmpl:14"},
+		"%d. place":      {"views/page.tmpl:16"},
+	}
+	for id, want := range tests {
+		tr, ok := translations[id]
+		if !ok {
+			t.Errorf("translation %q not in result", id)
+			continue
+		}
+		if !reflect.DeepEqual(tr.SourceLocations, want) {
+			t.Errorf("Locations of %q = %v, want %v", id, tr.SourceLocations, want)
+		}
+	}
+	if tr := translations["%d message"]; tr != nil && tr.MsgIDPlural != "%d messages" {
+		t.Errorf("Expected msgid_plural, got %q", tr.MsgIDPlural)
+	}
+	if tr := data.Domains["default"].ContextTranslations["button"]["Sign in"]; tr == nil {
+		t.Error("Expected the context translation")
+	}
+	if tr := data.Domains["admin"]; tr == nil || tr.Translations["Admin"] == nil {
+		t.Error("Expected the domain translation")
+	}
+	if tr := translations["%d. place"]; tr != nil && (tr.MsgIDPlural != "%d. place" || len(tr.Flags) != 1 || tr.Flags[0] != "ordinal") {
+		t.Errorf("Expected an ordinal entry, got %+v", tr)
+	}
+	for _, id := range []string{"Not extracted", "Other field", "Cache key", "Unqualified getter"} {
+		if _, ok := translations[id]; ok {
+			t.Errorf("translation %q shouldn't be in result", id)
+		}
+	}
+	if len(translations) != len(tests) {
+		t.Errorf("Expected %d translations, got %d", len(tests), len(translations))
+	}
+}
+
+func TestTemplateFile_ParseNoDefaultKeywords(t *testing.T) {
+	settings := &Settings{}
+	for _, spec := range []string{"", "Locale.Get"} {
+		if err := settings.AddTemplateKeyword(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := &DomainMap{Settings: settings}
+	file := &TemplateFile{FilePath: "page.tmpl", BasePath: ".", Data: data}
+	if err := file.Parse([]byte(`{{ .T.Get "Default" }}{{ .Locale.Get "Added" }}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	translations := data.Domains["default"].Translations
+	if _, ok := translations["Default"]; ok {
+		t.Error("Expected the default keywords to be removed")
+	}
+	if _, ok := translations["Added"]; !ok {
+		t.Error("Expected the keyword added to be extracted")
+	}
+}
+
+func TestTemplateFile_ParseError(t *testing.T) {
+	file := &TemplateFile{FilePath: "bad.tmpl", Data: &DomainMap{}}
+	if err := file.Parse([]byte(`{{ if }}`)); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+}
+
+func TestSettings_IsTemplate(t *testing.T) {
+	settings := &Settings{}
+	if !settings.IsTemplate("views/page.gohtml") || !settings.IsTemplate("mail.tmpl") || settings.IsTemplate("index.html") {
+		t.Error("Unexpected default template patterns")
+	}
+	if err := settings.AddTemplatePattern("*.html"); err != nil {
+		t.Fatal(err)
+	}
+	if !settings.IsTemplate("views/index.html") {
+		t.Error("Expected *.html files to be templates")
+	}
+	if err := settings.AddTemplatePattern("[*.html"); err == nil {
+		t.Error("Expected an invalid pattern to fail")
+	}
+}
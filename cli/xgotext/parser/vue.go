@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	vueScript    = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script>`)
+	vueTemplate  = regexp.MustCompile(`(?is)<template\b[^>]*>(.*)</template>`)
+	vueMustache  = regexp.MustCompile(`(?s)\{\{(.*?)\}\}`)
+	vueBinding   = regexp.MustCompile(`\s(?::|@|v-)[\w.:-]*\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	vueTranslate = regexp.MustCompile(`(?is)<translate\b([^>]*)>(.*?)</translate>`)
+	vueAttribute = regexp.MustCompile(`(?s)([\w:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// VueFile handles the parsing of one Vue single-file component
+type VueFile struct {
+	FilePath string
+	BasePath string
+	Data     *DomainMap
+}
+
+// Parse parses the component text and adds its translations to Data: the calls of the gettext functions
+// in its scripts and in the expressions of its template, and the contents of its <translate> elements
+func (v *VueFile) Parse(text []byte) error {
+	src := string(text)
+
+	// Scripts are parsed with the other parts of the file blanked out, to keep the line numbers
+	keep := make([]bool, len(src))
+	for _, m := range vueScript.FindAllStringSubmatchIndex(src, -1) {
+		keepRange(keep, m[2], m[3])
+	}
+	if m := vueTemplate.FindStringSubmatchIndex(src); m != nil {
+		template := src[m[2]:m[3]]
+		for _, e := range vueMustache.FindAllStringSubmatchIndex(template, -1) {
+			keepRange(keep, m[2]+e[2], m[2]+e[3])
+		}
+		for _, e := range vueBinding.FindAllStringSubmatchIndex(template, -1) {
+			// Double or single quoted value
+			if e[2] >= 0 {
+				keepRange(keep, m[2]+e[2], m[2]+e[3])
+			} else {
+				keepRange(keep, m[2]+e[4], m[2]+e[5])
+			}
+		}
+		v.parseTranslate(src, m[2], m[3])
+	}
+
+	masked := []byte(src)
+	for i, c := range masked {
+		if !keep[i] && c != '\n' {
+			masked[i] = ' '
+		}
+	}
+
+	script := ScriptFile{FilePath: v.FilePath, BasePath: v.BasePath, Data: v.Data}
+	return script.Parse(masked)
+}
+
+// parseTranslate adds the contents of the <translate> elements of src[start:end], as in vue-gettext
+func (v *VueFile) parseTranslate(src string, start, end int) {
+	path, _ := filepath.Rel(v.BasePath, v.FilePath)
+	for _, m := range vueTranslate.FindAllStringSubmatchIndex(src[start:end], -1) {
+		msgid := strings.TrimSpace(src[start+m[4] : start+m[5]])
+		if msgid == "" {
+			continue
+		}
+
+		trans := &Translation{
+			MsgID:           msgid,
+			SourceLocations: []string{fmt.Sprintf("%s:%d", path, strings.Count(src[:start+m[4]], "\n")+1)},
+		}
+		for _, attr := range vueAttribute.FindAllStringSubmatch(src[start+m[2]:start+m[3]], -1) {
+			value := attr[2] + attr[3]
+			switch attr[1] {
+			case "translate-context":
+				trans.Context = value
+			case "translate-plural":
+				trans.MsgIDPlural = value
+			}
+		}
+		v.Data.AddTranslation("", trans)
+	}
+}
+
+// keepRange marks the bytes from start to end to be kept
+func keepRange(keep []bool, start, end int) {
+	for i := start; i < end; i++ {
+		keep[i] = true
+	}
+}
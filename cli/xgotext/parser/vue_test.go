@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"testing"
+)
+
+const vueSource = `<template>
+  <div :title="$gettext('Tooltip')">
+    <p>Don't extract gettext("this text")</p>
+    <p>{{ $gettext("Welcome") }}</p>
+    <translate translate-context="header">Sign in</translate>
+    <translate translate-plural="%{n} files">
+      %{n} file
+    </translate>
+  </div>
+</template>
+
+<script>
+export default {
+  computed: {
+    label() { return this.$pgettext("button", "Save"); },
+  },
+};
+</script>
+
+<style>
+.gettext { content: "gettext('style')"; }
+</style>
+`
+
+func TestVueFile_Parse(t *testing.T) {
+	data := &DomainMap{}
+	file := VueFile{FilePath: "App.vue", BasePath: ".", Data: data}
+	if err := file.Parse([]byte(vueSource)); err != nil {
+		t.Fatal(err)
+	}
+
+	d := data.Domains["default"]
+	tests := map[string]string{
+		"Tooltip":   "App.vue:2",
+		"Welcome":   "App.vue:4",
+		"%{n} file": "App.vue:6",
+	}
+	for id, want := range tests {
+		if tr := d.Translations[id]; tr == nil || len(tr.SourceLocations) != 1 || tr.SourceLocations[0] != want {
+			t.Errorf("Expected %q at %s, got %+v", id, want, tr)
+		}
+	}
+	if tr := d.Translations["%{n} file"]; tr != nil && tr.MsgIDPlural != "%{n} files" {
+		t.Errorf("Expected plural, got %q", tr.MsgIDPlural)
+	}
+	if d.ContextTranslations["header"]["Sign in"] == nil {
+		t.Error("Expected <translate> with context")
+	}
+	if tr := d.ContextTranslations["button"]["Save"]; tr == nil || tr.SourceLocations[0] != "App.vue:15" {
+		t.Errorf("Expected script translation at App.vue:15, got %+v", tr)
+	}
+	if d.Translations["this text"] != nil || d.Translations["style"] != nil {
+		t.Error("Unexpected translation outside of scripts and expressions")
+	}
+}
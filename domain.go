@@ -4,11 +4,12 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
-	"regexp"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/leonelquinteros/gotext/plurals"
 )
@@ -27,26 +28,311 @@ type Domain struct {
 	// Preserve comments at head of PO for round-trip
 	headerComments []string
 
+	// Current contents. Lookups read it without locking, writers publish modified copies.
+	data atomic.Pointer[domainData]
+
+	// Sync Mutex, serializes writers
+	writeMutex sync.Mutex
+
+	// Parsing buffers
+	dataBuffer    *domainData
+	trBuffer      *Translation
+	ctxBuffer     string
+	refBuffer     string
+	flagBuffer    []string
+	commentBuffer []string
+	seenBuffer    map[string]bool
+	headerBuffer  []string
+
+	// Previous msgid of the entry being parsed, from "#|" lines, and the part of it being parsed
+	previousBuffer Translation
+	previousState  parseState
+
+	// Part of the obsolete entry being parsed, and its context and msgstr index
+	obsoleteState parseState
+	obsoleteCtx   string
+	obsoleteForm  int
+}
+
+// domainData is an immutable snapshot of a Domain's translations and plural rules.
+// Once published it's never modified: neither the maps nor the Translation objects in them.
+type domainData struct {
 	// Parsed Plural-Forms header values
 	nplurals    int
 	plural      string
 	pluralforms plurals.Expression
 
+	// Parsed X-Ordinal-Forms header
+	nordinals    int
+	ordinalForms plurals.Expression
+
+	// Built-in plural rules for the catalog language, nil if it's unknown
+	language *languagePlurals
+
 	// Storage
 	translations        map[string]*Translation
 	contextTranslations map[string]map[string]*Translation
 	pluralTranslations  map[string]*Translation
 
-	// Sync Mutex
-	trMutex     sync.RWMutex
-	pluralMutex sync.RWMutex
+	customPluralResolver func(int) int
 
-	// Parsing buffers
-	trBuffer  *Translation
-	ctxBuffer string
-	refBuffer string
+	// How lookups without usable translation are resolved
+	policy LookupPolicy
 
-	customPluralResolver func(int) int
+	// Context maps already copied while modifying a clone
+	ownedContexts map[string]bool
+
+	// Entries found more than once while parsing, for Lint
+	duplicates []duplicateEntry
+
+	// Obsolete entries ("#~ msgid ..."), found while parsing or added with AddObsolete
+	obsolete []obsoleteEntry
+}
+
+// obsoleteEntry is an obsolete entry of a catalog, not used for lookups
+type obsoleteEntry struct {
+	ctx   string
+	trans *Translation
+}
+
+// duplicateEntry is a repeated entry of a parsed catalog
+type duplicateEntry struct {
+	ctx  string
+	id   string
+	line int
+}
+
+// emptyDomainData is used by Domain objects not created with NewDomain
+var emptyDomainData = newDomainData()
+
+func newDomainData() *domainData {
+	return &domainData{
+		translations:        make(map[string]*Translation),
+		contextTranslations: make(map[string]map[string]*Translation),
+		pluralTranslations:  make(map[string]*Translation),
+	}
+}
+
+// clone returns a copy of the snapshot that can be modified before being published.
+// Maps are copied, Translation objects are shared and must be cloned before being modified.
+func (d *domainData) clone() *domainData {
+	c := *d
+	c.ownedContexts = nil
+	c.duplicates = d.duplicates[:len(d.duplicates):len(d.duplicates)]
+	c.obsolete = d.obsolete[:len(d.obsolete):len(d.obsolete)]
+
+	c.translations = make(map[string]*Translation, len(d.translations))
+	for id, trans := range d.translations {
+		c.translations[id] = trans
+	}
+
+	c.contextTranslations = make(map[string]map[string]*Translation, len(d.contextTranslations))
+	for ctx, translations := range d.contextTranslations {
+		c.contextTranslations[ctx] = translations
+	}
+
+	c.pluralTranslations = make(map[string]*Translation, len(d.pluralTranslations))
+	for id, trans := range d.pluralTranslations {
+		c.pluralTranslations[id] = trans
+	}
+
+	return &c
+}
+
+// context returns a modifiable copy of the translations for ctx, creating it if needed.
+func (d *domainData) context(ctx string) map[string]*Translation {
+	if d.ownedContexts[ctx] {
+		return d.contextTranslations[ctx]
+	}
+
+	translations := make(map[string]*Translation, len(d.contextTranslations[ctx])+1)
+	for id, trans := range d.contextTranslations[ctx] {
+		translations[id] = trans
+	}
+	d.contextTranslations[ctx] = translations
+
+	if d.ownedContexts == nil {
+		d.ownedContexts = make(map[string]bool)
+	}
+	d.ownedContexts[ctx] = true
+
+	return translations
+}
+
+// pluralOperand converts n to the unsigned value plural expressions are evaluated with.
+// Negative numbers use their absolute value, so -1 is singular like 1.
+func pluralOperand(n int) uint64 {
+	if n < 0 {
+		// Also right for math.MinInt, whose negation overflows to itself
+		return uint64(-n)
+	}
+	return uint64(n)
+}
+
+func (d *domainData) pluralForm(n int) int {
+	// Failure fallback
+	if d.pluralforms == nil {
+		if d.customPluralResolver != nil {
+			return d.customPluralResolver(n)
+		}
+		if d.language != nil && d.language.forms != nil {
+			return d.language.forms.Eval(pluralOperand(n))
+		}
+
+		/* Use the Germanic plural rule.  */
+		return englishPluralForm(n)
+	}
+	return d.pluralforms.Eval(pluralOperand(n))
+}
+
+// numPlurals returns the number of plural forms of the catalog
+func (d *domainData) numPlurals() int {
+	if d.nplurals > 0 {
+		return d.nplurals
+	}
+	if d.language != nil {
+		return d.language.nplurals
+	}
+	return 2
+}
+
+// numOrdinals returns the number of ordinal forms of the catalog: the nplurals of the X-Ordinal-Forms
+// header, or the number of CLDR ordinal categories of the catalog language, or else its number of plural forms
+func (d *domainData) numOrdinals() int {
+	if d.ordinalForms != nil && d.nordinals > 0 {
+		return d.nordinals
+	}
+	if d.language != nil && d.language.ordinal != nil {
+		return len(d.language.ordinal.Categories())
+	}
+	return d.numPlurals()
+}
+
+// numForms returns the number of msgstr of the plural entry trans, ordinal or not
+func (d *domainData) numForms(trans *Translation) int {
+	if trans.HasFlag("ordinal") {
+		return d.numOrdinals()
+	}
+	return d.numPlurals()
+}
+
+// decimalForm returns the plural form for n, a number formatted as a decimal, and whether
+// n would use the singular form in English. The CLDR category of n is mapped to the form
+// of the smallest integer in the same category, or to the last form for categories
+// only used by decimals, or when n isn't a valid number. Without CLDR rules for the catalog
+// language, integers use the plural form of the catalog and other numbers the last form.
+func (d *domainData) decimalForm(n string) (int, bool) {
+	op, err := plurals.ParseOperands(n)
+	if err != nil {
+		return d.numPlurals() - 1, false
+	}
+	singular := plurals.EnglishCardinal().Category(op) == plurals.One
+
+	if d.language == nil || d.language.cardinal == nil {
+		if op.V == 0 && op.I <= math.MaxInt {
+			return d.pluralForm(int(op.I)), singular
+		}
+		return d.numPlurals() - 1, singular
+	}
+	rules := d.language.cardinal
+	if sample, ok := rules.IntegerSample(rules.Category(op)); ok {
+		return d.pluralForm(int(sample)), singular
+	}
+	return d.numPlurals() - 1, singular
+}
+
+// ordinalForm returns the form of the ordinal n, using the X-Ordinal-Forms header
+// or one form per CLDR ordinal category of the catalog language
+func (d *domainData) ordinalForm(n int) int {
+	if d.ordinalForms != nil {
+		return d.ordinalForms.Eval(pluralOperand(n))
+	}
+	if d.language == nil || d.language.ordinal == nil {
+		return 0
+	}
+	rules := d.language.ordinal
+	return rules.Index(rules.Category(plurals.IntOperands(int64(n))))
+}
+
+// languagePlurals holds the built-in plural rules of a language
+type languagePlurals struct {
+	// Used when there's no Plural-Forms header
+	forms    plurals.Expression
+	nplurals int
+
+	// Used for decimal numbers
+	cardinal *plurals.CLDRRules
+
+	// Used for ordinals when there's no X-Ordinal-Forms header
+	ordinal *plurals.CLDRRules
+}
+
+// newLanguagePlurals returns the built-in plural rules for lang, or nil if it's unknown
+func newLanguagePlurals(lang string) *languagePlurals {
+	rule, ok := plurals.ForLanguage(lang)
+	if !ok {
+		return nil
+	}
+
+	lp := &languagePlurals{nplurals: rule.NPlurals}
+	lp.forms, _ = plurals.Compile(rule.Plural)
+	lp.cardinal, _ = plurals.CardinalForLanguage(lang)
+	lp.ordinal, _ = plurals.OrdinalForLanguage(lang)
+	return lp
+}
+
+// setDefaultLanguage sets the language whose built-in plural rules are used when the catalog
+// doesn't have a Language header with known rules.
+func (do *Domain) setDefaultLanguage(lang string) {
+	if do.load().language != nil {
+		return
+	}
+	lp := newLanguagePlurals(lang)
+	if lp == nil {
+		return
+	}
+	do.updateSettings(func(d *domainData) {
+		if d.language == nil {
+			d.language = lp
+		}
+	})
+}
+
+// usable reports whether trans can be used to translate, according to the lookup policy
+func (d *domainData) usable(trans *Translation) bool {
+	return !d.policy.SkipFuzzy || !trans.IsFuzzy()
+}
+
+// formatFits reports whether the translation tr found for msgid can be formatted with vars.
+// It's always true unless the policy checks formats, and calls the policy hook when false.
+func (d *domainData) formatFits(msgid, tr string, found bool, vars []interface{}) bool {
+	if !found || !d.policy.CheckFormat || len(vars) == 0 {
+		return true
+	}
+	err := CheckArgs(tr, vars...)
+	if err == nil {
+		return true
+	}
+	if d.policy.OnFormatMismatch != nil {
+		d.policy.OnFormatMismatch(msgid, tr, err)
+	}
+	return false
+}
+
+// format formats the result of a lookup with vars, unless the policy says misses must be returned as-is
+func (d *domainData) format(tr string, found bool, vars []interface{}) string {
+	if !found && !d.policy.formatMiss() {
+		return tr
+	}
+	return FormatString(tr, vars...)
+}
+
+// appendf appends the result of a lookup, formatted like format does
+func (d *domainData) appendf(b []byte, tr string, found bool, vars []interface{}) []byte {
+	if !found && !d.policy.formatMiss() {
+		return append(b, tr...)
+	}
+	return Appendf(b, tr, vars...)
 }
 
 // HeaderMap preserves MIMEHeader behaviour, without the canonicalisation
@@ -93,49 +379,82 @@ func NewDomain() *Domain {
 
 	domain.Headers = make(HeaderMap)
 	domain.headerComments = make([]string, 0)
-	domain.translations = make(map[string]*Translation)
-	domain.contextTranslations = make(map[string]map[string]*Translation)
-	domain.pluralTranslations = make(map[string]*Translation)
+	domain.data.Store(newDomainData())
 
 	return domain
 }
 
+// load returns the current snapshot of the domain contents
+func (do *Domain) load() *domainData {
+	if d := do.data.Load(); d != nil {
+		return d
+	}
+	return emptyDomainData
+}
+
+// update publishes a modified copy of the domain contents.
+// The function receives a private copy it's free to modify, and can't be called concurrently.
+func (do *Domain) update(f func(d *domainData)) {
+	do.writeMutex.Lock()
+	defer do.writeMutex.Unlock()
+
+	d := do.load().clone()
+	f(d)
+	d.ownedContexts = nil
+	do.data.Store(d)
+}
+
+// updateSettings is like update, for functions that don't modify translations.
+// The copy shares its maps with the current snapshot.
+func (do *Domain) updateSettings(f func(d *domainData)) {
+	do.writeMutex.Lock()
+	defer do.writeMutex.Unlock()
+
+	d := *do.load()
+	f(&d)
+	do.data.Store(&d)
+}
+
+// SetLookupPolicy sets how lookups without usable translation are resolved
+func (do *Domain) SetLookupPolicy(p LookupPolicy) {
+	do.updateSettings(func(d *domainData) {
+		d.policy = p
+	})
+}
+
+// GetLookupPolicy returns the policy set with SetLookupPolicy
+func (do *Domain) GetLookupPolicy() LookupPolicy {
+	return do.load().policy
+}
+
 // SetPluralResolver sets a custom plural resolver function
 func (do *Domain) SetPluralResolver(f func(int) int) {
-	do.customPluralResolver = f
+	do.updateSettings(func(d *domainData) {
+		d.customPluralResolver = f
+	})
 }
 
 func (do *Domain) pluralForm(n int) int {
-	// do we really need locking here? not sure how this plurals.Expression works, so sticking with it for now
-	do.pluralMutex.RLock()
-	defer do.pluralMutex.RUnlock()
-
-	// Failure fallback
-	if do.pluralforms == nil {
-		if do.customPluralResolver != nil {
-			return do.customPluralResolver(n)
-		}
-
-		/* Use the Germanic plural rule.  */
-		if n == 1 {
-			return 0
-		}
-		return 1
-	}
-	return do.pluralforms.Eval(uint32(n))
+	return do.load().pluralForm(n)
 }
 
-// parseHeaders retrieves data from previously parsed headers. it's called by both Mo and Po when parsing
+// parseHeaders retrieves data from previously parsed headers. it's called by both Mo and Po when parsing,
+// with the snapshot being parsed in dataBuffer.
 func (do *Domain) parseHeaders() {
+	d := do.dataBuffer
+
 	raw := ""
-	if _, ok := do.translations[raw]; ok {
-		raw = do.translations[raw].Get()
+	if _, ok := d.translations[raw]; ok {
+		raw = d.translations[raw].Get()
 	}
 
 	// textproto.ReadMIMEHeader() forces keys through CanonicalMIMEHeaderKey(); must read header manually to have one-to-one round-trip of keys
 	languageKey := "Language"
 	pluralFormsKey := "Plural-Forms"
+	ordinalFormsKey := "X-Ordinal-Forms"
 
+	// Fields of the header entry replace their previous values, so parsing again doesn't repeat them
+	parsed := make(HeaderMap)
 	rawLines := strings.Split(raw, "\n")
 	for _, line := range rawLines {
 		if len(line) == 0 {
@@ -153,23 +472,54 @@ func (do *Domain) parseHeaders() {
 			languageKey = key
 		} else if lowerKey == strings.ToLower(pluralFormsKey) {
 			pluralFormsKey = key
+		} else if lowerKey == strings.ToLower(ordinalFormsKey) {
+			ordinalFormsKey = key
 		}
 
 		value := strings.TrimSpace(line[colonIdx+1:])
-		do.Headers.Add(key, value)
+		parsed.Add(key, value)
+	}
+	for key, values := range parsed {
+		do.Headers[key] = values
 	}
 
 	// Get/save needed headers
 	do.Language = do.Headers.Get(languageKey)
 	do.PluralForms = do.Headers.Get(pluralFormsKey)
+	d.language = newLanguagePlurals(do.Language)
+
+	// Parse X-Ordinal-Forms formula, with the same syntax as Plural-Forms
+	d.nordinals, d.ordinalForms = compileOrdinalForms(do.Headers.Get(ordinalFormsKey))
 
 	// Parse Plural-Forms formula
 	if do.PluralForms == "" {
 		return
 	}
 
+	d.nplurals, d.plural = parsePluralForms(do.PluralForms)
+	if expr, err := plurals.Compile(d.plural); err == nil {
+		d.pluralforms = expr
+	}
+}
+
+// compileOrdinalForms returns the nplurals and expression of a X-Ordinal-Forms header,
+// or 0 and nil if it's empty or invalid
+func compileOrdinalForms(header string) (int, plurals.Expression) {
+	if header == "" {
+		return 0, nil
+	}
+	nplurals, plural := parsePluralForms(header)
+	expr, err := plurals.Compile(plural)
+	if err != nil {
+		return 0, nil
+	}
+	return nplurals, expr
+}
+
+// parsePluralForms returns the values of a Plural-Forms header
+func parsePluralForms(header string) (nplurals int, plural string) {
 	// Split plural form header value
-	pfs := strings.Split(do.PluralForms, ";")
+	pfs := strings.Split(header, ";")
 
 	// Parse values
 	for _, i := range pfs {
@@ -180,324 +530,396 @@ func (do *Domain) parseHeaders() {
 
 		switch strings.TrimSpace(vs[0]) {
 		case "nplurals":
-			do.nplurals, _ = strconv.Atoi(vs[1])
+			nplurals, _ = strconv.Atoi(vs[1])
 
 		case "plural":
-			do.plural = vs[1]
-
-			if expr, err := plurals.Compile(do.plural); err == nil {
-				do.pluralforms = expr
-			}
-
+			plural = vs[1]
 		}
 	}
+	return nplurals, plural
 }
 
 // DropStaleTranslations drops any translations stored that have not been Set*()
 // since 'po' was initialised
 func (do *Domain) DropStaleTranslations() {
-	do.trMutex.Lock()
-	do.pluralMutex.Lock()
-	defer do.trMutex.Unlock()
-	defer do.pluralMutex.Unlock()
-
-	for name, ctx := range do.contextTranslations {
-		for id, trans := range ctx {
-			if trans.IsStale() {
-				delete(ctx, id)
+	do.update(func(d *domainData) {
+		for name, ctx := range d.contextTranslations {
+			kept := make(map[string]*Translation, len(ctx))
+			for id, trans := range ctx {
+				if !trans.IsStale() {
+					kept[id] = trans
+				}
+			}
+			if len(kept) == 0 {
+				delete(d.contextTranslations, name)
+			} else {
+				d.contextTranslations[name] = kept
 			}
 		}
-		if len(ctx) == 0 {
-			delete(do.contextTranslations, name)
-		}
-	}
 
-	for id, trans := range do.translations {
-		if trans.IsStale() {
-			delete(do.translations, id)
+		for id, trans := range d.translations {
+			if trans.IsStale() {
+				delete(d.translations, id)
+			}
 		}
-	}
+	})
 }
 
 // SetRefs set source references for a given translation
 func (do *Domain) SetRefs(str string, refs []string) {
-	do.trMutex.Lock()
-	do.pluralMutex.Lock()
-	defer do.trMutex.Unlock()
-	defer do.pluralMutex.Unlock()
-
-	if trans, ok := do.translations[str]; ok {
-		trans.Refs = refs
-	} else {
-		trans = NewTranslation()
-		trans.ID = str
-		trans.SetRefs(refs)
-		do.translations[str] = trans
-	}
+	do.update(func(d *domainData) {
+		if trans, ok := d.translations[str]; ok {
+			trans = trans.clone()
+			trans.Refs = refs
+			d.translations[str] = trans
+		} else {
+			trans = NewTranslation()
+			trans.ID = str
+			trans.SetRefs(refs)
+			d.translations[str] = trans
+		}
+	})
 }
 
 // GetRefs get source references for a given translation
 func (do *Domain) GetRefs(str string) []string {
-	// Sync read
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
-
-	if do.translations != nil {
-		if trans, ok := do.translations[str]; ok {
-			return trans.Refs
-		}
+	if trans, ok := do.load().translations[str]; ok {
+		return trans.Refs
 	}
 	return nil
 }
 
 // Set the translation of a given string
 func (do *Domain) Set(id, str string) {
-	do.trMutex.Lock()
-	do.pluralMutex.Lock()
-	defer do.trMutex.Unlock()
-	defer do.pluralMutex.Unlock()
-
-	if trans, ok := do.translations[id]; ok {
-		trans.Set(str)
-	} else {
-		trans = NewTranslation()
-		trans.ID = id
-		trans.Set(str)
-		do.translations[id] = trans
-	}
+	do.update(func(d *domainData) {
+		if trans, ok := d.translations[id]; ok {
+			trans = trans.clone()
+			trans.Set(str)
+			d.translations[id] = trans
+		} else {
+			trans = NewTranslation()
+			trans.ID = id
+			trans.Set(str)
+			d.translations[id] = trans
+		}
+	})
 }
 
 // Get retrieves the Translation for the given string.
 func (do *Domain) Get(str string, vars ...interface{}) string {
-	// Sync read
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
-
-	if do.translations != nil {
-		if _, ok := do.translations[str]; ok {
-			return FormatString(do.translations[str].Get(), vars...)
-		}
+	d := do.load()
+	tr, found := d.lookup(str)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = str
 	}
-
-	// Return the same we received by default
-	return FormatString(str, vars...)
+	return d.format(tr, found, vars)
 }
 
 // Append retrieves the Translation for the given string.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (do *Domain) Append(b []byte, str string, vars ...interface{}) []byte {
-	// Sync read
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
+	d := do.load()
+	tr, found := d.lookup(str)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = str
+	}
+	return d.appendf(b, tr, found, vars)
+}
 
-	if do.translations != nil {
-		if _, ok := do.translations[str]; ok {
-			return Appendf(b, do.translations[str].Get(), vars...)
-		}
+// Lookup retrieves the Translation for the given string, reporting whether it was found.
+// When it wasn't, the same string received is returned, unless the LookupPolicy says otherwise.
+func (do *Domain) Lookup(str string) (string, bool) {
+	return do.load().lookup(str)
+}
+
+func (d *domainData) lookup(str string) (string, bool) {
+	if trans, ok := d.translations[str]; ok && d.usable(trans) && trans.IsTranslated() {
+		return trans.Trs[0], true
 	}
 
 	// Return the same we received by default
-	return Appendf(b, str, vars...)
+	return d.policy.miss(str), false
 }
 
 // SetN sets the (N)th plural form for the given string
 func (do *Domain) SetN(id, plural string, n int, str string) {
-	// Get plural form _before_ lock down
-	pluralForm := do.pluralForm(n)
-
-	do.trMutex.Lock()
-	do.pluralMutex.Lock()
-	defer do.trMutex.Unlock()
-	defer do.pluralMutex.Unlock()
+	do.update(func(d *domainData) {
+		pluralForm := d.pluralForm(n)
 
-	if trans, ok := do.translations[id]; ok {
-		trans.SetN(pluralForm, str)
-	} else {
-		trans = NewTranslation()
-		trans.ID = id
-		trans.PluralID = plural
-		trans.SetN(pluralForm, str)
-		do.translations[id] = trans
-	}
+		if trans, ok := d.translations[id]; ok {
+			trans = trans.clone()
+			trans.SetN(pluralForm, str)
+			d.translations[id] = trans
+		} else {
+			trans = NewTranslation()
+			trans.ID = id
+			trans.PluralID = plural
+			trans.SetN(pluralForm, str)
+			d.translations[id] = trans
+		}
+	})
 }
 
 // GetN retrieves the (N)th plural form of Translation for the given string.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (do *Domain) GetN(str, plural string, n int, vars ...interface{}) string {
-	// Sync read
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
-
-	if do.translations != nil {
-		if _, ok := do.translations[str]; ok {
-			return FormatString(do.translations[str].GetN(do.pluralForm(n)), vars...)
-		}
+	d := do.load()
+	tr, _, found := d.lookupN(str, plural, n)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = d.policy.sourceN(str, plural, n)
 	}
-
-	// Parse plural forms to distinguish between plural and singular
-	if do.pluralForm(n) == 0 {
-		return FormatString(str, vars...)
-	}
-	return FormatString(plural, vars...)
+	return d.format(tr, found, vars)
 }
 
 // AppendN adds the (N)th plural form of Translation for the given string.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (do *Domain) AppendN(b []byte, str, plural string, n int, vars ...interface{}) []byte {
-	// Sync read
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
-
-	if do.translations != nil {
-		if _, ok := do.translations[str]; ok {
-			return Appendf(b, do.translations[str].GetN(do.pluralForm(n)), vars...)
-		}
+	d := do.load()
+	tr, _, found := d.lookupN(str, plural, n)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = d.policy.sourceN(str, plural, n)
 	}
+	return d.appendf(b, tr, found, vars)
+}
 
-	// Parse plural forms to distinguish between plural and singular
-	if do.pluralForm(n) == 0 {
-		return Appendf(b, str, vars...)
+// LookupN retrieves the (N)th plural form of Translation for the given string,
+// along with the plural form index used and whether the translation was found.
+// When it wasn't, the singular or plural string received is returned, unless the LookupPolicy says otherwise.
+func (do *Domain) LookupN(str, plural string, n int) (string, int, bool) {
+	return do.load().lookupN(str, plural, n)
+}
+
+func (d *domainData) lookupN(str, plural string, n int) (string, int, bool) {
+	idx := d.pluralForm(n)
+
+	if trans, ok := d.translations[str]; ok && d.usable(trans) && trans.IsTranslatedN(idx) {
+		return trans.Trs[idx], idx, true
 	}
-	return Appendf(b, plural, vars...)
+
+	// The strings received follow the plural rule of the source language, not the one of the catalog
+	return d.policy.missN(str, plural, n), idx, false
 }
 
 // SetC sets the translation for the given string in the given context
 func (do *Domain) SetC(id, ctx, str string) {
-	do.trMutex.Lock()
-	do.pluralMutex.Lock()
-	defer do.trMutex.Unlock()
-	defer do.pluralMutex.Unlock()
+	do.update(func(d *domainData) {
+		context := d.context(ctx)
 
-	if context, ok := do.contextTranslations[ctx]; ok {
-		if trans, hasTrans := context[id]; hasTrans {
+		if trans, ok := context[id]; ok {
+			trans = trans.clone()
 			trans.Set(str)
+			context[id] = trans
 		} else {
 			trans = NewTranslation()
 			trans.ID = id
 			trans.Set(str)
 			context[id] = trans
 		}
-	} else {
-		trans := NewTranslation()
-		trans.ID = id
-		trans.Set(str)
-		do.contextTranslations[ctx] = map[string]*Translation{
-			id: trans,
+	})
+}
+
+// GetNDecimal retrieves the plural form of Translation for the given string matching n,
+// a number formatted as a decimal like "1.5", "2,0" or "1.2c6" (see plurals.ParseOperands).
+// The form is chosen with the CLDR plural rules of the catalog language, so "1.0" may
+// need a different form than "1". Languages without CLDR rules use the form of GetN for
+// integers and the last form otherwise. When not found, the plural string is returned unless
+// n is singular in English.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (do *Domain) GetNDecimal(str, plural, n string, vars ...interface{}) string {
+	d := do.load()
+	tr, _, found := d.lookupNDecimal(str, plural, n)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = plural
+		if _, singular := d.decimalForm(n); singular {
+			tr = str
 		}
 	}
+	return d.format(tr, found, vars)
+}
+
+// GetNFloat is like GetNDecimal for a float64, formatted with the minimum number of digits needed.
+// Use GetNDecimal when the number of visible fraction digits matters, as in "2.0".
+func (do *Domain) GetNFloat(str, plural string, n float64, vars ...interface{}) string {
+	return do.GetNDecimal(str, plural, strconv.FormatFloat(n, 'f', -1, 64), vars...)
+}
+
+// LookupNDecimal is like LookupN for a number formatted as a decimal, as GetNDecimal takes.
+func (do *Domain) LookupNDecimal(str, plural, n string) (string, int, bool) {
+	return do.load().lookupNDecimal(str, plural, n)
+}
+
+func (d *domainData) lookupNDecimal(str, plural, n string) (string, int, bool) {
+	idx, singular := d.decimalForm(n)
+
+	if trans, ok := d.translations[str]; ok && d.usable(trans) && trans.IsTranslatedN(idx) {
+		return trans.Trs[idx], idx, true
+	}
+
+	if singular {
+		return d.policy.miss(str), idx, false
+	}
+	return d.policy.miss(plural), idx, false
+}
+
+// GetO retrieves the ordinal form of Translation for the given string matching n, as in "%d. place" translated
+// to "1st place" or "2nd place". Ordinal entries are plural entries with one msgstr per ordinal form, as chosen by the
+// X-Ordinal-Forms header, which has the syntax of Plural-Forms, or else by the CLDR ordinal categories of the catalog
+// language, in CLDR order (zero, one, two, few, many, other).
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (do *Domain) GetO(str string, n int, vars ...interface{}) string {
+	d := do.load()
+	tr, _, found := d.lookupO(str, n)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = str
+	}
+	return d.format(tr, found, vars)
+}
+
+// LookupO retrieves the ordinal form of Translation for the given string matching n,
+// along with the form index used and whether the translation was found.
+func (do *Domain) LookupO(str string, n int) (string, int, bool) {
+	return do.load().lookupO(str, n)
+}
+
+func (d *domainData) lookupO(str string, n int) (string, int, bool) {
+	idx := d.ordinalForm(n)
+
+	if trans, ok := d.translations[str]; ok && d.usable(trans) && trans.IsTranslatedN(idx) {
+		return trans.Trs[idx], idx, true
+	}
+	return d.policy.miss(str), idx, false
+}
+
+// GetOC retrieves the ordinal form of Translation for the given string matching n in the given context.
+// See GetO.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (do *Domain) GetOC(str string, n int, ctx string, vars ...interface{}) string {
+	d := do.load()
+	tr, _, found := d.lookupOC(str, n, ctx)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = str
+	}
+	return d.format(tr, found, vars)
+}
+
+// LookupOC retrieves the ordinal form of Translation for the given string matching n in the given context,
+// along with the form index used and whether the translation was found.
+func (do *Domain) LookupOC(str string, n int, ctx string) (string, int, bool) {
+	return do.load().lookupOC(str, n, ctx)
+}
+
+func (d *domainData) lookupOC(str string, n int, ctx string) (string, int, bool) {
+	idx := d.ordinalForm(n)
+
+	if trans, ok := d.contextTranslations[ctx][str]; ok && d.usable(trans) && trans.IsTranslatedN(idx) {
+		return trans.Trs[idx], idx, true
+	}
+	if d.policy.ContextFallback {
+		return d.lookupO(str, n)
+	}
+	return d.policy.miss(str), idx, false
 }
 
 // GetC retrieves the corresponding Translation for a given string in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (do *Domain) GetC(str, ctx string, vars ...interface{}) string {
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
-
-	if do.contextTranslations != nil {
-		if _, ok := do.contextTranslations[ctx]; ok {
-			if do.contextTranslations[ctx] != nil {
-				if _, ok := do.contextTranslations[ctx][str]; ok {
-					return FormatString(do.contextTranslations[ctx][str].Get(), vars...)
-				}
-			}
-		}
+	d := do.load()
+	tr, found := d.lookupC(str, ctx)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = str
 	}
-
-	// Return the string we received by default
-	return FormatString(str, vars...)
+	return d.format(tr, found, vars)
 }
 
 // AppendC retrieves the corresponding Translation for a given string in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (do *Domain) AppendC(b []byte, str, ctx string, vars ...interface{}) []byte {
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
-
-	if do.contextTranslations != nil {
-		if _, ok := do.contextTranslations[ctx]; ok {
-			if do.contextTranslations[ctx] != nil {
-				if _, ok := do.contextTranslations[ctx][str]; ok {
-					return Appendf(b, do.contextTranslations[ctx][str].Get(), vars...)
-				}
-			}
-		}
+	d := do.load()
+	tr, found := d.lookupC(str, ctx)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = str
+	}
+	return d.appendf(b, tr, found, vars)
+}
+
+// LookupC retrieves the corresponding Translation for a given string in the given context,
+// reporting whether it was found. When it wasn't, the same string received is returned,
+// unless the LookupPolicy says otherwise.
+func (do *Domain) LookupC(str, ctx string) (string, bool) {
+	return do.load().lookupC(str, ctx)
+}
+
+func (d *domainData) lookupC(str, ctx string) (string, bool) {
+	if trans, ok := d.contextTranslations[ctx][str]; ok && d.usable(trans) && trans.IsTranslated() {
+		return trans.Trs[0], true
+	}
+
+	if d.policy.ContextFallback {
+		return d.lookup(str)
 	}
 
 	// Return the string we received by default
-	return Appendf(b, str, vars...)
+	return d.policy.miss(str), false
 }
 
 // SetNC sets the (N)th plural form for the given string in the given context
 func (do *Domain) SetNC(id, plural, ctx string, n int, str string) {
-	// Get plural form _before_ lock down
-	pluralForm := do.pluralForm(n)
-
-	do.trMutex.Lock()
-	do.pluralMutex.Lock()
-	defer do.trMutex.Unlock()
-	defer do.pluralMutex.Unlock()
+	do.update(func(d *domainData) {
+		pluralForm := d.pluralForm(n)
+		context := d.context(ctx)
 
-	if context, ok := do.contextTranslations[ctx]; ok {
-		if trans, hasTrans := context[id]; hasTrans {
+		if trans, ok := context[id]; ok {
+			trans = trans.clone()
 			trans.SetN(pluralForm, str)
+			context[id] = trans
 		} else {
 			trans = NewTranslation()
 			trans.ID = id
 			trans.SetN(pluralForm, str)
 			context[id] = trans
 		}
-	} else {
-		trans := NewTranslation()
-		trans.ID = id
-		trans.SetN(pluralForm, str)
-		do.contextTranslations[ctx] = map[string]*Translation{
-			id: trans,
-		}
-	}
+	})
 }
 
 // GetNC retrieves the (N)th plural form of Translation for the given string in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (do *Domain) GetNC(str, plural string, n int, ctx string, vars ...interface{}) string {
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
-
-	if do.contextTranslations != nil {
-		if _, ok := do.contextTranslations[ctx]; ok {
-			if do.contextTranslations[ctx] != nil {
-				if _, ok := do.contextTranslations[ctx][str]; ok {
-					return FormatString(do.contextTranslations[ctx][str].GetN(do.pluralForm(n)), vars...)
-				}
-			}
-		}
-	}
-
-	if n == 1 {
-		return FormatString(str, vars...)
+	d := do.load()
+	tr, _, found := d.lookupNC(str, plural, n, ctx)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = d.policy.sourceN(str, plural, n)
 	}
-	return FormatString(plural, vars...)
+	return d.format(tr, found, vars)
 }
 
 // AppendNC retrieves the (N)th plural form of Translation for the given string in the given context.
 // Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
 func (do *Domain) AppendNC(b []byte, str, plural string, n int, ctx string, vars ...interface{}) []byte {
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
-
-	if do.contextTranslations != nil {
-		if _, ok := do.contextTranslations[ctx]; ok {
-			if do.contextTranslations[ctx] != nil {
-				if _, ok := do.contextTranslations[ctx][str]; ok {
-					return Appendf(b, do.contextTranslations[ctx][str].GetN(do.pluralForm(n)), vars...)
-				}
-			}
-		}
+	d := do.load()
+	tr, _, found := d.lookupNC(str, plural, n, ctx)
+	if !d.formatFits(str, tr, found, vars) {
+		tr = d.policy.sourceN(str, plural, n)
+	}
+	return d.appendf(b, tr, found, vars)
+}
+
+// LookupNC retrieves the (N)th plural form of Translation for the given string in the given context,
+// along with the plural form index used and whether the translation was found.
+// When it wasn't, the singular or plural string received is returned, unless the LookupPolicy says otherwise.
+func (do *Domain) LookupNC(str, plural string, n int, ctx string) (string, int, bool) {
+	return do.load().lookupNC(str, plural, n, ctx)
+}
+
+func (d *domainData) lookupNC(str, plural string, n int, ctx string) (string, int, bool) {
+	idx := d.pluralForm(n)
+	if trans, ok := d.contextTranslations[ctx][str]; ok && d.usable(trans) && trans.IsTranslatedN(idx) {
+		return trans.Trs[idx], idx, true
 	}
 
-	if n == 1 {
-		return Appendf(b, str, vars...)
+	if d.policy.ContextFallback {
+		return d.lookupN(str, plural, n)
 	}
-	return Appendf(b, plural, vars...)
+	return d.policy.missN(str, plural, n), idx, false
 }
 
 // IsTranslated reports whether a string is translated
@@ -507,17 +929,15 @@ func (do *Domain) IsTranslated(str string) bool {
 
 // IsTranslatedN reports whether a plural string is translated
 func (do *Domain) IsTranslatedN(str string, n int) bool {
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
+	return do.load().isTranslatedN(str, n)
+}
 
-	if do.translations == nil {
-		return false
-	}
-	tr, ok := do.translations[str]
-	if !ok {
+func (d *domainData) isTranslatedN(str string, n int) bool {
+	tr, ok := d.translations[str]
+	if !ok || !d.usable(tr) {
 		return false
 	}
-	return tr.IsTranslatedN(do.pluralForm(n))
+	return tr.IsTranslatedN(d.pluralForm(n))
 }
 
 // IsTranslatedC reports whether a context string is translated
@@ -527,43 +947,47 @@ func (do *Domain) IsTranslatedC(str, ctx string) bool {
 
 // IsTranslatedNC reports whether a plural context string is translated
 func (do *Domain) IsTranslatedNC(str string, n int, ctx string) bool {
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
+	d := do.load()
 
-	if do.contextTranslations == nil {
-		return false
-	}
-	translations, ok := do.contextTranslations[ctx]
-	if !ok {
-		return false
-	}
-	tr, ok := translations[str]
-	if !ok {
-		return false
+	if tr, ok := d.contextTranslations[ctx][str]; ok && d.usable(tr) && tr.IsTranslatedN(d.pluralForm(n)) {
+		return true
 	}
-	return tr.IsTranslatedN(do.pluralForm(n))
+	return d.policy.ContextFallback && d.isTranslatedN(str, n)
 }
 
-// GetTranslations returns a copy of every translation in the domain. It does not support contexts.
-func (do *Domain) GetTranslations() map[string]*Translation {
-	all := make(map[string]*Translation, len(do.translations))
-
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
+// SetTranslation adds a copy of trans to the domain, in the context ctx if not empty,
+// replacing any translation with the same msgid.
+func (do *Domain) SetTranslation(ctx string, trans *Translation) {
+	trans = trans.clone()
+	trans.dirty = true
 
-	for msgID, trans := range do.translations {
-		newTrans := NewTranslation()
-		newTrans.ID = trans.ID
-		newTrans.PluralID = trans.PluralID
-		newTrans.dirty = trans.dirty
-		if len(trans.Refs) > 0 {
-			newTrans.Refs = make([]string, len(trans.Refs))
-			copy(newTrans.Refs, trans.Refs)
+	do.update(func(d *domainData) {
+		if ctx == "" {
+			d.translations[trans.ID] = trans
+		} else {
+			d.context(ctx)[trans.ID] = trans
 		}
-		for k, v := range trans.Trs {
-			newTrans.Trs[k] = v
+		if trans.PluralID != "" {
+			d.pluralTranslations[trans.PluralID] = trans
 		}
-		all[msgID] = newTrans
+	})
+}
+
+// AddObsolete adds an obsolete entry to the domain, written last by MarshalText
+// and not used for lookups.
+func (do *Domain) AddObsolete(ctx string, trans *Translation) {
+	do.update(func(d *domainData) {
+		d.obsolete = append(d.obsolete, obsoleteEntry{ctx: ctx, trans: trans.clone()})
+	})
+}
+
+// GetTranslations returns a copy of every translation in the domain. It does not support contexts.
+func (do *Domain) GetTranslations() map[string]*Translation {
+	d := do.load()
+	all := make(map[string]*Translation, len(d.translations))
+
+	for msgID, trans := range d.translations {
+		all[msgID] = trans.clone()
 	}
 
 	return all
@@ -571,30 +995,16 @@ func (do *Domain) GetTranslations() map[string]*Translation {
 
 // GetCtxTranslations returns a copy of every translation in the domain with context
 func (do *Domain) GetCtxTranslations() map[string]map[string]*Translation {
-	all := make(map[string]map[string]*Translation, len(do.contextTranslations))
+	d := do.load()
+	all := make(map[string]map[string]*Translation, len(d.contextTranslations))
 
-	do.trMutex.RLock()
-	defer do.trMutex.RUnlock()
-
-	for ctx, translations := range do.contextTranslations {
+	for ctx, translations := range d.contextTranslations {
 		for msgID, trans := range translations {
-			newTrans := NewTranslation()
-			newTrans.ID = trans.ID
-			newTrans.PluralID = trans.PluralID
-			newTrans.dirty = trans.dirty
-			if len(trans.Refs) > 0 {
-				newTrans.Refs = make([]string, len(trans.Refs))
-				copy(newTrans.Refs, trans.Refs)
-			}
-			for k, v := range trans.Trs {
-				newTrans.Trs[k] = v
-			}
-
 			if all[ctx] == nil {
 				all[ctx] = make(map[string]*Translation)
 			}
 
-			all[ctx][msgID] = newTrans
+			all[ctx][msgID] = trans.clone()
 		}
 
 	}
@@ -634,6 +1044,8 @@ func (do *Domain) MarshalText() ([]byte, error) {
 	}
 	buf.WriteString("msgid \"\"\nmsgstr \"\"")
 
+	d := do.load()
+
 	// Standard order consistent with xgettext
 	headerOrder := map[string]int{
 		"project-id-version":        0,
@@ -687,7 +1099,7 @@ func (do *Domain) MarshalText() ([]byte, error) {
 
 	// Just as with headers, output translations in consistent order (to minimise diffs between round-trips), with (first) source reference taking priority, followed by context and finally ID
 	references := make([]SourceReference, 0)
-	for name, ctx := range do.contextTranslations {
+	for name, ctx := range d.contextTranslations {
 		for id, trans := range ctx {
 			if id == "" {
 				continue
@@ -711,7 +1123,7 @@ func (do *Domain) MarshalText() ([]byte, error) {
 		}
 	}
 
-	for id, trans := range do.translations {
+	for id, trans := range d.translations {
 		if id == "" {
 			continue
 		}
@@ -758,79 +1170,134 @@ func (do *Domain) MarshalText() ([]byte, error) {
 	})
 
 	for _, ref := range references {
-		trans := ref.trans
-		if len(trans.Refs) > 0 {
-			buf.WriteString("\n\n#: " + strings.Join(trans.Refs, " "))
-		} else {
-			buf.WriteByte(byte('\n'))
-		}
-
-		if ref.context == "" {
-			buf.WriteString("\nmsgid \"" + EscapeSpecialCharacters(trans.ID) + "\"")
-		} else {
-			buf.WriteString("\nmsgctxt \"" + EscapeSpecialCharacters(ref.context) + "\"\nmsgid \"" + EscapeSpecialCharacters(trans.ID) + "\"")
-		}
+		writeEntry(&buf, ref.context, ref.trans, d.numForms(ref.trans), false)
+	}
 
-		if trans.PluralID == "" {
-			buf.WriteString("\nmsgstr \"" + EscapeSpecialCharacters(trans.Trs[0]) + "\"")
-		} else {
-			buf.WriteString("\nmsgid_plural \"" + trans.PluralID + "\"")
-			for i, tr := range trans.Trs {
-				buf.WriteString("\nmsgstr[" + EscapeSpecialCharacters(strconv.Itoa(i)) + "] \"" + tr + "\"")
-			}
-		}
+	// Obsolete entries last, in the order they were found or added
+	for _, entry := range d.obsolete {
+		writeEntry(&buf, entry.ctx, entry.trans, d.numForms(entry.trans), true)
 	}
 
 	return buf.Bytes(), nil
 }
 
-// EscapeSpecialCharacters escapes special characters in a string
-func EscapeSpecialCharacters(s string) string {
-	s = regexp.MustCompile(`([^\\])(")`).ReplaceAllString(s, "$1\\\"") // Escape non-escaped double quotation marks
+// writeEntry writes an entry of a PO file, after an empty line, with at least forms msgstr if it's plural.
+// The msgctxt, msgid and msgstr lines of obsolete entries are commented out with "#~", and their previous
+// msgid lines with "#~|".
+func writeEntry(buf *bytes.Buffer, ctx string, trans *Translation, forms int, obsolete bool) {
+	prefix, previous := "", "#| "
+	if obsolete {
+		prefix, previous = "#~ ", "#~| "
+	}
 
-	if strings.Count(s, "\n") == 0 {
-		return s
+	buf.WriteByte(byte('\n'))
+	for _, comment := range trans.ExtractedComments {
+		buf.WriteString("\n#. " + comment)
+	}
+	if len(trans.Refs) > 0 {
+		buf.WriteString("\n#: " + strings.Join(trans.Refs, " "))
+	}
+	if len(trans.Flags) > 0 {
+		buf.WriteString("\n#, " + strings.Join(trans.Flags, ", "))
+	}
+	if trans.PreviousContext != "" {
+		writeField(buf, previous, "msgctxt", trans.PreviousContext)
+	}
+	if trans.PreviousID != "" {
+		writeField(buf, previous, "msgid", trans.PreviousID)
+	}
+	if trans.PreviousPluralID != "" {
+		writeField(buf, previous, "msgid_plural", trans.PreviousPluralID)
 	}
 
-	// Handle EOL and multi-lines
-	// Only one line, but finishing with \n
-	if strings.Count(s, "\n") == 1 && strings.HasSuffix(s, "\n") {
-		return strings.ReplaceAll(s, "\n", "\\n")
+	if ctx != "" {
+		writeField(buf, prefix, "msgctxt", ctx)
 	}
+	writeField(buf, prefix, "msgid", trans.ID)
 
-	elems := strings.Split(s, "\n")
-	// Skip last element for multiline which is an empty
-	var shouldEndWithEOL bool
-	if elems[len(elems)-1] == "" {
-		elems = elems[:len(elems)-1]
-		shouldEndWithEOL = true
+	if trans.PluralID == "" {
+		writeField(buf, prefix, "msgstr", trans.Trs[0])
+		return
 	}
-	data := []string{(`"`)}
-	for i, v := range elems {
-		l := fmt.Sprintf(`"%s\n"`, v)
-		// Last element without EOL
-		if i == len(elems)-1 && !shouldEndWithEOL {
-			l = fmt.Sprintf(`"%s"`, v)
+	writeField(buf, prefix, "msgid_plural", trans.PluralID)
+	// Every form, in order, even when untranslated
+	for form := range trans.Trs {
+		if form >= forms {
+			forms = form + 1
 		}
-		// Remove finale " to last element as the whole string will be quoted
-		if i == len(elems)-1 {
-			l = strings.TrimSuffix(l, `"`)
+	}
+	for form := 0; form < forms; form++ {
+		writeField(buf, prefix, "msgstr["+strconv.Itoa(form)+"]", trans.Trs[form])
+	}
+}
+
+// writeField writes the line of a keyword and its quoted value, with prefix before each of its lines
+func writeField(buf *bytes.Buffer, prefix, keyword, s string) {
+	value := strings.ReplaceAll(EscapeSpecialCharacters(s), "\n", "\n"+prefix)
+	buf.WriteString("\n" + prefix + keyword + " \"" + value + "\"")
+}
+
+// EscapeSpecialCharacters escapes s to be written between double quotes in a PO file: backslashes,
+// double quotes and control characters are escaped, and strings with line breaks other than a final one
+// are split into one quoted line each, after an empty first line.
+func EscapeSpecialCharacters(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		return escapeLine(s)
+	}
+
+	var b strings.Builder
+	b.WriteString("\"")
+	for i, l := range lines {
+		b.WriteString("\n\"" + escapeLine(l))
+		if i < len(lines)-1 {
+			b.WriteString("\"")
+		}
+	}
+	return b.String()
+}
+
+// escapeLine escapes the special characters of s
+func escapeLine(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch r {
+		case '\\', '"':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < ' ' || r == 0x7f {
+				fmt.Fprintf(&b, "\\%03o", r)
+			} else {
+				b.WriteRune(r)
+			}
 		}
-		data = append(data, l)
 	}
-	return strings.Join(data, "\n")
+	return b.String()
 }
 
 // MarshalBinary implements encoding.BinaryMarshaler interface
 func (do *Domain) MarshalBinary() ([]byte, error) {
+	d := do.load()
+
 	obj := new(TranslatorEncoding)
 	obj.Headers = do.Headers
 	obj.Language = do.Language
 	obj.PluralForms = do.PluralForms
-	obj.Nplurals = do.nplurals
-	obj.Plural = do.plural
-	obj.Translations = do.translations
-	obj.Contexts = do.contextTranslations
+	obj.Nplurals = d.nplurals
+	obj.Plural = d.plural
+	obj.Translations = d.translations
+	obj.Contexts = d.contextTranslations
 
 	var buff bytes.Buffer
 	encoder := gob.NewEncoder(&buff)
@@ -853,14 +1320,34 @@ func (do *Domain) UnmarshalBinary(data []byte) error {
 	do.Headers = obj.Headers
 	do.Language = obj.Language
 	do.PluralForms = obj.PluralForms
-	do.nplurals = obj.Nplurals
-	do.plural = obj.Plural
-	do.translations = obj.Translations
-	do.contextTranslations = obj.Contexts
+	do.setData(obj.Nplurals, obj.Plural, obj.Translations, obj.Contexts)
+
+	return nil
+}
+
+// setData replaces the domain contents with decoded ones
+func (do *Domain) setData(nplurals int, plural string, translations map[string]*Translation, contexts map[string]map[string]*Translation) {
+	d := newDomainData()
+	d.nplurals = nplurals
+	d.plural = plural
+	if translations != nil {
+		d.translations = translations
+	}
+	if contexts != nil {
+		d.contextTranslations = contexts
+	}
 
-	if expr, err := plurals.Compile(do.plural); err == nil {
-		do.pluralforms = expr
+	if expr, err := plurals.Compile(d.plural); err == nil {
+		d.pluralforms = expr
+	}
+	d.language = newLanguagePlurals(do.Language)
+	for key, values := range do.Headers {
+		if strings.EqualFold(key, "X-Ordinal-Forms") && len(values) > 0 {
+			d.nordinals, d.ordinalForms = compileOrdinalForms(values[0])
+		}
 	}
 
-	return nil
+	do.writeMutex.Lock()
+	do.data.Store(d)
+	do.writeMutex.Unlock()
 }
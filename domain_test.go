@@ -1,6 +1,11 @@
 package gotext
 
 import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -47,11 +52,11 @@ func TestDomain_GetTranslations(t *testing.T) {
 	domain := po.GetDomain()
 	all := domain.GetTranslations()
 
-	if len(all) != len(domain.translations) {
+	if len(all) != len(domain.load().translations) {
 		t.Error("lengths should match")
 	}
 
-	for k, v := range domain.translations {
+	for k, v := range domain.load().translations {
 		if all[k] == v {
 			t.Error("GetTranslations should be returning a copy, but pointers are equal")
 		}
@@ -80,15 +85,15 @@ func TestDomain_GetCtxTranslations(t *testing.T) {
 	domain := po.GetDomain()
 	all := domain.GetCtxTranslations()
 
-	if len(all) != len(domain.contextTranslations) {
+	if len(all) != len(domain.load().contextTranslations) {
 		t.Error("lengths should match")
 	}
 
-	if domain.contextTranslations["Ctx"] == nil {
+	if domain.load().contextTranslations["Ctx"] == nil {
 		t.Error("Context 'Ctx' should exist")
 	}
 
-	for k, v := range domain.contextTranslations {
+	for k, v := range domain.load().contextTranslations {
 		for kk, vv := range v {
 			if all[k][kk] == vv {
 				t.Error("GetCtxTranslations should be returning a copy, but pointers are equal")
@@ -180,6 +185,126 @@ msgstr ""
 	}
 }
 
+func TestDomain_MarshalTextEscaping(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 ? 1 : 2);\n"
+`))
+	d := po.GetDomain()
+
+	d.SetTranslation("say \"hi\"", &Translation{
+		ID:                "C:\\path\tfile",
+		Refs:              []string{"main.go:10", "main.go:12"},
+		ExtractedComments: []string{"TRANSLATORS: a path"},
+	})
+	d.SetTranslation("", &Translation{ID: "%d \"file\"", PluralID: "%d \"files\"", Trs: map[int]string{1: "b", 0: "a"}})
+	d.SetTranslation("", &Translation{ID: "Line\nother line\n"})
+
+	text, err := d.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"#. TRANSLATORS: a path\n#: main.go:10 main.go:12\nmsgctxt \"say \\\"hi\\\"\"\nmsgid \"C:\\\\path\\tfile\"\nmsgstr \"\"",
+		"msgid \"%d \\\"file\\\"\"\nmsgid_plural \"%d \\\"files\\\"\"\nmsgstr[0] \"a\"\nmsgstr[1] \"b\"\nmsgstr[2] \"\"",
+		"msgid \"\"\n\"Line\\n\"\n\"other line\\n\"\nmsgstr \"\"",
+	} {
+		if !strings.Contains(string(text), want) {
+			t.Errorf("Expected\n%s\n\nin:\n%s", want, text)
+		}
+	}
+
+	// Round-trip
+	po2 := NewPo()
+	po2.Parse(text)
+	trs := po2.GetDomain().GetCtxTranslations()["say \"hi\""]
+	if tr := trs["C:\\path\tfile"]; tr == nil || len(tr.ExtractedComments) != 1 || tr.ExtractedComments[0] != "TRANSLATORS: a path" {
+		t.Errorf("Entry didn't round-trip: %+v", tr)
+	}
+	if tr := po2.GetN("%d \"file\"", "%d \"files\"", 3); tr != "b" {
+		t.Errorf("Expected plural to round-trip, got %q", tr)
+	}
+	if _, ok := po2.GetDomain().GetTranslations()["Line\nother line\n"]; !ok {
+		t.Error("Expected multi-line msgid to round-trip")
+	}
+}
+
+func TestDomain_MarshalTextPreviousAndObsolete(t *testing.T) {
+	// Written as MarshalText writes it, so it must be written back unchanged
+	text := `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#: main.go:10
+#, fuzzy
+#| msgctxt "menu"
+#| msgid "Open file"
+msgctxt "menu"
+msgid "Open a file"
+msgstr "Abrir archivo"
+
+#: main.go:12
+#, fuzzy
+#| msgid "%d file"
+#| msgid_plural "%d files"
+msgid "%d item"
+msgid_plural "%d items"
+msgstr[0] "%d archivo"
+msgstr[1] "%d archivos"
+
+#, fuzzy
+#~| msgid "Quit"
+#~ msgctxt "menu"
+#~ msgid "Exit"
+#~ msgstr "Salir"
+
+#~ msgid ""
+#~ "First line\n"
+#~ "second line"
+#~ msgid_plural "Lines"
+#~ msgstr[0] "Línea"
+#~ msgstr[1] ""
+#~ "Líneas\n"
+#~ "más"`
+
+	po := NewPo()
+	po.Parse([]byte(text))
+	d := po.GetDomain()
+
+	tr := d.GetCtxTranslations()["menu"]["Open a file"]
+	if tr == nil || tr.PreviousContext != "menu" || tr.PreviousID != "Open file" {
+		t.Errorf("Expected previous msgid to be parsed, got %+v", tr)
+	}
+	tr = d.GetTranslations()["%d item"]
+	if tr == nil || tr.PreviousID != "%d file" || tr.PreviousPluralID != "%d files" {
+		t.Errorf("Expected previous msgid_plural to be parsed, got %+v", tr)
+	}
+	if got := d.GetC("Exit", "menu"); got != "Exit" {
+		t.Errorf("Expected obsolete entry not to be used, got %q", got)
+	}
+
+	out, err := d.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != text {
+		t.Errorf("Expected\n%s\n\ngot:\n%s", text, out)
+	}
+
+	// Obsolete entries added to the domain
+	d = NewDomain()
+	d.AddObsolete("", &Translation{ID: "Bye", Trs: map[int]string{0: "Adiós"}, PreviousID: "Goodbye"})
+	out, err = d.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\n\n#~| msgid \"Goodbye\"\n#~ msgid \"Bye\"\n#~ msgstr \"Adiós\""; !strings.HasSuffix(string(out), want) {
+		t.Errorf("Expected\n%s\n\nat the end of:\n%s", want, out)
+	}
+}
+
 func TestDomain_GetWithVar(t *testing.T) {
 	po := NewPo()
 	po.ParseFile(enUSFixture)
@@ -352,3 +477,513 @@ func TestDomain_SetPluralResolver(t *testing.T) {
 		t.Error("Custom plural resolver failed")
 	}
 }
+
+func TestDomain_LookupsDoNotAllocate(t *testing.T) {
+	po := NewPo()
+	po.ParseFile(enUSFixture)
+	d := po.GetDomain()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = d.Get("My text")
+		_ = d.GetN("One with var: %s", "Several with vars: %s", 3)
+		_ = d.GetC("Some random in a context", "Ctx")
+		_ = d.IsTranslatedNC("One with var: %s", 3, "Ctx")
+	})
+	if allocs != 0 {
+		t.Errorf("Expected lookups not to allocate, got %v allocations", allocs)
+	}
+}
+
+func TestDomain_CopyOnWrite(t *testing.T) {
+	d := NewDomain()
+	d.Set("id", "first")
+	d.SetC("id", "ctx", "first in ctx")
+
+	before := d.load()
+	d.Set("id", "second")
+	d.SetC("id", "ctx", "second in ctx")
+
+	if before.translations["id"].Get() != "first" {
+		t.Error("Set modified a published snapshot")
+	}
+	if before.contextTranslations["ctx"]["id"].Get() != "first in ctx" {
+		t.Error("SetC modified a published snapshot")
+	}
+	if d.Get("id") != "second" || d.GetC("id", "ctx") != "second in ctx" {
+		t.Error("Set didn't publish the new translation")
+	}
+}
+
+func TestDomain_ConcurrentSetAndGet(t *testing.T) {
+	d := NewDomain()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				d.Set(strconv.Itoa(j), strconv.Itoa(i))
+				d.SetNC("item", "items", "ctx", j, strconv.Itoa(i))
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = d.Get(strconv.Itoa(j))
+				_ = d.GetNC("item", "items", j, "ctx")
+				_ = d.GetTranslations()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(d.GetTranslations()) != 100 {
+		t.Errorf("Expected 100 translations, got %d", len(d.GetTranslations()))
+	}
+}
+
+func TestDomain_Lookup(t *testing.T) {
+	po := NewPo()
+	po.ParseFile(enUSFixture)
+	d := po.GetDomain()
+
+	if tr, ok := d.Lookup("My text"); !ok || tr != translatedText {
+		t.Errorf("Expected ('%s', true) but got ('%s', %v)", translatedText, tr, ok)
+	}
+	if tr, ok := d.Lookup("Empty translation"); ok || tr != "Empty translation" {
+		t.Errorf("Expected ('Empty translation', false) but got ('%s', %v)", tr, ok)
+	}
+	if tr, ok := d.Lookup("Missing"); ok || tr != "Missing" {
+		t.Errorf("Expected ('Missing', false) but got ('%s', %v)", tr, ok)
+	}
+
+	tr, idx, ok := d.LookupN("One with var: %s", "Several with vars: %s", 3)
+	if !ok || idx != 1 || tr != "This one is the plural: %s" {
+		t.Errorf("Expected ('This one is the plural: %%s', 1, true) but got ('%s', %d, %v)", tr, idx, ok)
+	}
+	tr, idx, ok = d.LookupN("Empty plural form singular", "Empty plural form", 2)
+	if ok || idx != 1 || tr != "Empty plural form" {
+		t.Errorf("Expected ('Empty plural form', 1, false) but got ('%s', %d, %v)", tr, idx, ok)
+	}
+	tr, idx, ok = d.LookupN("Missing", "Missings", 1)
+	if ok || idx != 0 || tr != "Missing" {
+		t.Errorf("Expected ('Missing', 0, false) but got ('%s', %d, %v)", tr, idx, ok)
+	}
+
+	if tr, ok := d.LookupC("Some random in a context", "Ctx"); !ok || tr != "Some random translation in a context" {
+		t.Errorf("Expected context translation but got ('%s', %v)", tr, ok)
+	}
+	if tr, ok := d.LookupC("Some random in a context", "Other"); ok || tr != "Some random in a context" {
+		t.Errorf("Expected ('Some random in a context', false) but got ('%s', %v)", tr, ok)
+	}
+
+	tr, idx, ok = d.LookupNC("One with var: %s", "Several with vars: %s", 1, "Ctx")
+	if !ok || idx != 0 || tr != "This one is the singular in a Ctx context: %s" {
+		t.Errorf("Expected context singular but got ('%s', %d, %v)", tr, idx, ok)
+	}
+	tr, idx, ok = d.LookupNC("Missing", "Missings", 5, "Ctx")
+	if ok || idx != 1 || tr != "Missings" {
+		t.Errorf("Expected ('Missings', 1, false) but got ('%s', %d, %v)", tr, idx, ok)
+	}
+
+	var _ LookupTranslator = po
+	var _ LookupTranslator = NewMo()
+}
+
+func TestDomain_LookupPolicy(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "Save"
+msgstr "Guardar"
+
+#, fuzzy
+msgid "Open"
+msgstr "Abrir"
+
+#, fuzzy, c-format
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+`))
+	d := po.GetDomain()
+
+	if !d.load().translations["Open"].IsFuzzy() {
+		t.Fatal("Expected 'Open' to be flagged as fuzzy")
+	}
+	if !d.load().translations["One file"].HasFlag("c-format") {
+		t.Fatal("Expected 'One file' to be flagged as c-format")
+	}
+
+	// Fuzzy entries are used by default
+	if tr := d.Get("Open"); tr != "Abrir" {
+		t.Errorf("Expected 'Abrir' but got '%s'", tr)
+	}
+	if tr := d.GetC("Save", "menu"); tr != "Save" {
+		t.Errorf("Expected 'Save' but got '%s'", tr)
+	}
+
+	d.SetLookupPolicy(LookupPolicy{ContextFallback: true, SkipFuzzy: true})
+
+	if tr, ok := d.Lookup("Open"); ok || tr != "Open" {
+		t.Errorf("Expected ('Open', false) but got ('%s', %v)", tr, ok)
+	}
+	if tr := d.GetN("One file", "%d files", 3, 3); tr != "3 files" {
+		t.Errorf("Expected '3 files' but got '%s'", tr)
+	}
+	if d.IsTranslated("Open") {
+		t.Error("Expected fuzzy 'Open' not to be translated")
+	}
+	if tr, ok := d.LookupC("Save", "menu"); !ok || tr != "Guardar" {
+		t.Errorf("Expected ('Guardar', true) but got ('%s', %v)", tr, ok)
+	}
+	if !d.IsTranslatedC("Save", "menu") {
+		t.Error("Expected 'Save' in context 'menu' to fall back to the entry without context")
+	}
+
+	d.SetLookupPolicy(LookupPolicy{Miss: MissReturnSentinel, Sentinel: "???"})
+
+	if tr := d.Get("Missing %s", "var"); tr != "???" {
+		t.Errorf("Expected '???' but got '%s'", tr)
+	}
+	if tr := string(d.Append(nil, "Missing %s", "var")); tr != "???" {
+		t.Errorf("Expected '???' but got '%s'", tr)
+	}
+
+	d.SetLookupPolicy(LookupPolicy{Miss: MissReturnEmpty})
+
+	if tr, ok := d.Lookup("Missing"); ok || tr != "" {
+		t.Errorf("Expected ('', false) but got ('%s', %v)", tr, ok)
+	}
+	if tr := d.Get("Save"); tr != "Guardar" {
+		t.Errorf("Expected 'Guardar' but got '%s'", tr)
+	}
+
+	// A source language where 0 is singular too
+	d.SetLookupPolicy(LookupPolicy{SourcePluralForm: func(n int) int {
+		if n > 1 {
+			return 1
+		}
+		return 0
+	}})
+
+	if tr, idx, _ := d.LookupNC("One item", "%d items", 0, "ctx"); tr != "One item" || idx != 1 {
+		t.Errorf("Expected ('One item', 1) but got ('%s', %d)", tr, idx)
+	}
+}
+
+func TestDomain_MissSourcePluralRule(t *testing.T) {
+	for _, lang := range []string{"ar", "ru"} {
+		po := NewPo()
+		po.Parse([]byte(`msgid ""
+msgstr ""
+"Language: ` + lang + `\n"
+
+msgid "Other"
+msgstr "Translated"
+`))
+		d := po.GetDomain()
+
+		// The catalog picks form 0 for some of these, the source strings follow English
+		for n, want := range map[int]string{0: "0 files", 1: "1 file", 2: "2 files", 21: "21 files", 101: "101 files"} {
+			if tr := d.GetN("%d file", "%d files", n, n); tr != want {
+				t.Errorf("%s: expected '%s' but got '%s'", lang, want, tr)
+			}
+			if tr := d.GetNC("%d file", "%d files", n, "ctx", n); tr != want {
+				t.Errorf("%s: expected '%s' in context but got '%s'", lang, want, tr)
+			}
+			if tr, _, ok := d.LookupN("%d file", "%d files", n); ok || FormatString(tr, n) != want {
+				t.Errorf("%s: expected ('%s', false) but got ('%s', %v)", lang, want, FormatString(tr, n), ok)
+			}
+		}
+	}
+}
+
+func TestDomain_LanguagePluralRule(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Language: pl\n"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "%d plik"
+msgstr[1] "%d pliki"
+msgstr[2] "%d plików"
+`))
+
+	for n, want := range map[int]string{1: "1 plik", 3: "3 pliki", 5: "5 plików", 22: "22 pliki"} {
+		if tr := po.GetN("One file", "%d files", n, n); tr != want {
+			t.Errorf("Expected '%s' for n = %d but got '%s'", want, n, tr)
+		}
+	}
+
+	// The custom resolver takes precedence over the built-in rule
+	po.SetPluralResolver(func(n int) int { return 0 })
+	if tr := po.GetN("One file", "%d files", 5, 5); tr != "5 plik" {
+		t.Errorf("Expected '5 plik' but got '%s'", tr)
+	}
+}
+
+func TestDomain_GetNDecimal(t *testing.T) {
+	parse := func(lang, forms string, trs ...string) *Po {
+		po := NewPo()
+		s := "msgid \"\"\nmsgstr \"\"\n\"Language: " + lang + "\\n\"\n"
+		if forms != "" {
+			s += "\"Plural-Forms: " + forms + "\\n\"\n"
+		}
+		s += "\nmsgid \"%s hour\"\nmsgid_plural \"%s hours\"\n"
+		for i, tr := range trs {
+			s += "msgstr[" + strconv.Itoa(i) + "] \"" + tr + "\"\n"
+		}
+		po.Parse([]byte(s))
+		return po
+	}
+
+	tests := []struct {
+		po   *Po
+		n    string
+		want string
+	}{
+		{parse("en", "nplurals=2; plural=(n != 1);", "%s hour", "%s hours"), "1", "1 hour"},
+		{parse("en", "nplurals=2; plural=(n != 1);", "%s hour", "%s hours"), "1.0", "1.0 hours"},
+		{parse("de", "nplurals=2; plural=(n != 1);", "%s Stunde", "%s Stunden"), "2,0", "2,0 Stunden"},
+		{parse("fr", "nplurals=2; plural=(n > 1);", "%s heure", "%s heures"), "1.5", "1.5 heure"},
+		{parse("fr", "nplurals=2; plural=(n > 1);", "%s heure", "%s heures"), "2.5", "2.5 heures"},
+		{parse("pl", "", "%s godzina", "%s godziny", "%s godzin"), "22", "22 godziny"},
+		{parse("pl", "", "%s godzina", "%s godziny", "%s godzin"), "1.5", "1.5 godzin"},
+		{parse("pl", "", "%s godzina", "%s godziny", "%s godzin"), "invalid", "invalid godzin"},
+		{parse("xx", "", "%s X", "%s Xs"), "1.0", "1.0 Xs"},
+		{parse("he", "nplurals=2; plural=(n != 1);", "%s שעה", "%s שעות"), "1", "1 שעה"},
+		{parse("he", "nplurals=2; plural=(n != 1);", "%s שעה", "%s שעות"), "2", "2 שעות"},
+		{parse("xx", "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);", "%s a", "%s b", "%s c"), "21", "21 a"},
+		{parse("xx", "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);", "%s a", "%s b", "%s c"), "2.5", "2.5 c"},
+	}
+	for _, tt := range tests {
+		if tr := tt.po.GetNDecimal("%s hour", "%s hours", tt.n, tt.n); tr != tt.want {
+			t.Errorf("%s: expected '%s' for %s but got '%s'", tt.po.Language, tt.want, tt.n, tr)
+		}
+
+		// Integers use the same form as with GetN
+		if n, err := strconv.Atoi(tt.n); err == nil {
+			if tr := tt.po.GetN("%s hour", "%s hours", n, tt.n); tr != tt.want {
+				t.Errorf("%s: expected '%s' from GetN for %d but got '%s'", tt.po.Language, tt.want, n, tr)
+			}
+		}
+	}
+
+	po := parse("en", "nplurals=2; plural=(n != 1);", "%v hour", "%v hours")
+	if tr := po.GetNFloat("%v hour", "%v hours", 1.5, 1.5); tr != "1.5 hours" {
+		t.Errorf("Expected '1.5 hours' but got '%s'", tr)
+	}
+
+	// Untranslated strings use the English rules
+	d := NewDomain()
+	if tr, idx, ok := d.LookupNDecimal("One", "Many", "1"); ok || idx != 0 || tr != "One" {
+		t.Errorf("Expected ('One', 0, false) but got ('%s', %d, %v)", tr, idx, ok)
+	}
+	if tr, idx, ok := d.LookupNDecimal("One", "Many", "1.0"); ok || idx != 1 || tr != "Many" {
+		t.Errorf("Expected ('Many', 1, false) but got ('%s', %d, %v)", tr, idx, ok)
+	}
+}
+
+func TestDomain_GetO(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Language: en\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#, ordinal
+msgid "%d. place"
+msgid_plural "%d. place"
+msgstr[0] "%dst place"
+msgstr[1] "%dnd place"
+msgstr[2] "%drd place"
+msgstr[3] "%dth place"
+
+msgctxt "race"
+msgid "%d. place"
+msgid_plural "%d. place"
+msgstr[0] "%dst"
+msgstr[1] "%dnd"
+msgstr[2] "%drd"
+msgstr[3] "%dth"
+`))
+
+	for n, want := range map[int]string{1: "1st place", 2: "2nd place", 3: "3rd place", 4: "4th place", 11: "11th place", 12: "12th place", 21: "21st place", 102: "102nd place"} {
+		if tr := po.GetO("%d. place", n, n); tr != want {
+			t.Errorf("Expected '%s' for %d but got '%s'", want, n, tr)
+		}
+	}
+	if tr := po.GetOC("%d. place", 23, "race", 23); tr != "23rd" {
+		t.Errorf("Expected '23rd' but got '%s'", tr)
+	}
+	if tr, idx, ok := po.LookupO("%d. row", 3); ok || idx != 2 || tr != "%d. row" {
+		t.Errorf("Expected ('%%d. row', 2, false) but got ('%s', %d, %v)", tr, idx, ok)
+	}
+
+	// The header takes precedence over the language rules
+	po = NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Language: fr\n"
+"X-Ordinal-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "%d. place"
+msgid_plural "%d. place"
+msgstr[0] "%dre place"
+msgstr[1] "%de place"
+`))
+	if tr := po.GetO("%d. place", 1, 1); tr != "1re place" {
+		t.Errorf("Expected '1re place' but got '%s'", tr)
+	}
+	if tr := po.GetO("%d. place", 2, 2); tr != "2e place" {
+		t.Errorf("Expected '2e place' but got '%s'", tr)
+	}
+
+	// And survives encoding
+	data, err := po.GetDomain().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDomain()
+	if err = d.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, idx, _ := d.LookupO("%d. place", 5); idx != 1 {
+		t.Errorf("Expected form 1 after decoding, got %d", idx)
+	}
+}
+
+func TestDomain_MarshalTextOrdinalForms(t *testing.T) {
+	for _, tt := range []struct {
+		header string
+		forms  int
+	}{
+		{`"Language: en\n"`, 4},
+		{`"Language: fr\n"`, 2},
+		{`"Language: en\n"
+"X-Ordinal-Forms: nplurals=3; plural=(n==1 ? 0 : n==2 ? 1 : 2);\n"`, 3},
+		{`"Plural-Forms: nplurals=2; plural=(n != 1);\n"`, 2},
+	} {
+		po := NewPo()
+		po.Parse([]byte(`msgid ""
+msgstr ""
+` + tt.header + `
+
+#, ordinal
+msgid "%d. place"
+msgid_plural "%d. place"
+msgstr[0] ""
+`))
+		data, err := po.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := "msgstr[" + strconv.Itoa(tt.forms-1) + `] ""`
+		beyond := "msgstr[" + strconv.Itoa(tt.forms) + "]"
+		if !strings.Contains(string(data), last) || strings.Contains(string(data), beyond) {
+			t.Errorf("Expected %d ordinal forms with header %s, got:\n%s", tt.forms, tt.header, data)
+		}
+	}
+}
+
+func TestDomain_GetNLargeAndNegative(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "%d byte"
+msgid_plural "%d bytes"
+msgstr[0] "%d байт"
+msgstr[1] "%d байта"
+msgstr[2] "%d байтов"
+`))
+
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{4294967297, 2},  // would wrap to 1 with 32 bits
+		{4294967301, 0},  // ends in 01, would wrap to 5
+		{5000000002, 1},  // ends in 2
+		{-1, 0},          // absolute value
+		{-22, 1},         // absolute value
+		{math.MinInt, 2}, // 9223372036854775808
+	}
+	for _, tt := range tests {
+		if _, idx, _ := po.LookupN("%d byte", "%d bytes", tt.n); idx != tt.want {
+			t.Errorf("Expected form %d for %d but got %d", tt.want, tt.n, idx)
+		}
+	}
+
+	d := NewDomain()
+	if tr := d.GetN("%d point", "%d points", -1, -1); tr != "-1 point" {
+		t.Errorf("Expected '-1 point' but got '%s'", tr)
+	}
+}
+
+func TestDomain_CheckFormat(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Language: es\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "Hello %s"
+msgstr "Hola %d"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%s archivos"
+
+msgctxt "menu"
+msgid "%d of %d"
+msgstr "%[2]d de %[1]d"
+`))
+	d := po.GetDomain()
+
+	// Without CheckFormat, broken translations reach the output
+	if tr := d.Get("Hello %s", "Ana"); tr != "Hola %!d(string=Ana)" {
+		t.Errorf("Expected 'Hola %%!d(string=Ana)' but got '%s'", tr)
+	}
+
+	var mismatches []string
+	d.SetLookupPolicy(LookupPolicy{
+		CheckFormat: true,
+		OnFormatMismatch: func(msgid, translation string, err error) {
+			mismatches = append(mismatches, msgid+": "+translation)
+		},
+	})
+
+	if tr := d.Get("Hello %s", "Ana"); tr != "Hello Ana" {
+		t.Errorf("Expected 'Hello Ana' but got '%s'", tr)
+	}
+	if tr := string(d.Append(nil, "Hello %s", "Ana")); tr != "Hello Ana" {
+		t.Errorf("Expected 'Hello Ana' but got '%s'", tr)
+	}
+	if tr := d.Get("Hello %s"); tr != "Hola %d" {
+		t.Errorf("Expected 'Hola %%d' without variables but got '%s'", tr)
+	}
+	if tr := d.GetN("One file", "%d files", 3, 3); tr != "3 files" {
+		t.Errorf("Expected '3 files' but got '%s'", tr)
+	}
+	if tr := d.GetN("One file", "%d files", 1); tr != "Un archivo" {
+		t.Errorf("Expected 'Un archivo' but got '%s'", tr)
+	}
+	if tr := d.GetC("%d of %d", "menu", 1, 2); tr != "2 de 1" {
+		t.Errorf("Expected '2 de 1' but got '%s'", tr)
+	}
+
+	want := []string{"Hello %s: Hola %d", "Hello %s: Hola %d", "One file: %s archivos"}
+	if !reflect.DeepEqual(mismatches, want) {
+		t.Errorf("Expected mismatches %q but got %q", want, mismatches)
+	}
+}
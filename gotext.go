@@ -23,6 +23,7 @@ package gotext
 
 import (
 	"encoding/gob"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -42,6 +43,9 @@ type config struct {
 
 	// Storage for package level methods
 	locales []*Locale
+
+	// Whether locales have been set up for the current configuration
+	loaded bool
 }
 
 var globalConfig *config
@@ -66,6 +70,17 @@ func init() {
 // at package level based on the configuration of global configuration .
 // It is called when trying to use Get or GetD methods.
 func loadLocales(rebuildCache bool) {
+	// Fast path used by every lookup
+	if !rebuildCache {
+		globalConfig.RLock()
+		loaded := globalConfig.loaded
+		globalConfig.RUnlock()
+
+		if loaded {
+			return
+		}
+	}
+
 	globalConfig.Lock()
 
 	if globalConfig.locales == nil || rebuildCache {
@@ -77,13 +92,26 @@ func loadLocales(rebuildCache bool) {
 	}
 
 	for _, locale := range globalConfig.locales {
-		if _, ok := locale.Domains[globalConfig.domain]; !ok || rebuildCache {
-			locale.AddDomain(globalConfig.domain)
-		}
 		locale.SetDomain(globalConfig.domain)
 	}
+	globalConfig.loaded = true
+	locales := globalConfig.locales
+	dom := globalConfig.domain
 
 	globalConfig.Unlock()
+
+	// Load default domain out of the lock, concurrent lookups wait for the same load
+	for _, locale := range locales {
+		locale.loadDomain(dom)
+	}
+}
+
+// Reload forgets which domains were found or missing on disk for the package level locales,
+// and reloads the ones found.
+func Reload() {
+	for _, locale := range GetLocales() {
+		locale.Reload()
+	}
 }
 
 // GetDomain is the domain getter for the package configuration
@@ -194,6 +222,7 @@ func SetLocales(locales []*Locale) {
 	defer globalConfig.Unlock()
 
 	globalConfig.locales = locales
+	globalConfig.loaded = false
 	globalConfig.library = locales[0].path
 	globalConfig.domain = locales[0].defaultDomain
 
@@ -250,18 +279,11 @@ func GetD(dom, str string, vars ...interface{}) string {
 	globalConfig.RLock()
 	defer globalConfig.RUnlock()
 
-	var tr string
-	for i, locale := range globalConfig.locales {
-		if _, ok := locale.Domains[dom]; !ok {
-			locale.AddDomain(dom)
-		}
-		if !locale.IsTranslatedD(dom, str) && i < (len(globalConfig.locales)-1) {
-			continue
-		}
-		tr = locale.GetD(dom, str, vars...)
-		break
-	}
-	return tr
+	// Fall back through the configured languages, the last one resolves the miss with its LookupPolicy
+	return fallbackLocale(dom, func(l *Locale) bool {
+		_, ok := l.LookupD(dom, str)
+		return ok
+	}).GetD(dom, str, vars...)
 }
 
 // GetND retrieves the (N)th plural form of Translation in the given domain for a given string.
@@ -273,18 +295,87 @@ func GetND(dom, str, plural string, n int, vars ...interface{}) string {
 	globalConfig.RLock()
 	defer globalConfig.RUnlock()
 
-	var tr string
-	for i, locale := range globalConfig.locales {
-		if _, ok := locale.Domains[dom]; !ok {
-			locale.AddDomain(dom)
-		}
-		if !locale.IsTranslatedND(dom, str, n) && i < (len(globalConfig.locales)-1) {
-			continue
-		}
-		tr = locale.GetND(dom, str, plural, n, vars...)
-		break
-	}
-	return tr
+	// Fall back through the configured languages, the last one resolves the miss with its LookupPolicy
+	return fallbackLocale(dom, func(l *Locale) bool {
+		_, _, ok := l.LookupND(dom, str, plural, n)
+		return ok
+	}).GetND(dom, str, plural, n, vars...)
+}
+
+// GetNDecimal retrieves the plural form of Translation for the given string matching n in the default domain,
+// n being a number formatted as a decimal like "1.5" or "2,0". See Domain.GetNDecimal.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func GetNDecimal(str, plural, n string, vars ...interface{}) string {
+	return GetNDDecimal(GetDomain(), str, plural, n, vars...)
+}
+
+// GetNFloat retrieves the plural form of Translation for the given string matching n in the default domain.
+// See Domain.GetNFloat.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func GetNFloat(str, plural string, n float64, vars ...interface{}) string {
+	return GetNDDecimal(GetDomain(), str, plural, strconv.FormatFloat(n, 'f', -1, 64), vars...)
+}
+
+// GetNDDecimal retrieves the plural form of Translation for the given string matching n in the given domain,
+// n being a number formatted as a decimal like "1.5" or "2,0". See Domain.GetNDecimal.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func GetNDDecimal(dom, str, plural, n string, vars ...interface{}) string {
+	// Try to load default package Locales
+	loadLocales(false)
+
+	globalConfig.RLock()
+	defer globalConfig.RUnlock()
+
+	// Fall back through the configured languages, the last one resolves the miss with its LookupPolicy
+	return fallbackLocale(dom, func(l *Locale) bool {
+		_, _, ok := l.LookupNDDecimal(dom, str, plural, n)
+		return ok
+	}).GetNDDecimal(dom, str, plural, n, vars...)
+}
+
+// GetO retrieves the ordinal form of Translation for the given string matching n in the default domain.
+// See Domain.GetO.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func GetO(str string, n int, vars ...interface{}) string {
+	return GetOD(GetDomain(), str, n, vars...)
+}
+
+// GetOD retrieves the ordinal form of Translation for the given string matching n in the given domain.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func GetOD(dom, str string, n int, vars ...interface{}) string {
+	// Try to load default package Locales
+	loadLocales(false)
+
+	globalConfig.RLock()
+	defer globalConfig.RUnlock()
+
+	// Fall back through the configured languages, the last one resolves the miss with its LookupPolicy
+	return fallbackLocale(dom, func(l *Locale) bool {
+		_, _, ok := l.LookupOD(dom, str, n)
+		return ok
+	}).GetOD(dom, str, n, vars...)
+}
+
+// GetOC retrieves the ordinal form of Translation for the given string matching n in the given context in the default domain.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func GetOC(str string, n int, ctx string, vars ...interface{}) string {
+	return GetODC(GetDomain(), str, n, ctx, vars...)
+}
+
+// GetODC retrieves the ordinal form of Translation for the given string matching n in the given context in the given domain.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func GetODC(dom, str string, n int, ctx string, vars ...interface{}) string {
+	// Try to load default package Locales
+	loadLocales(false)
+
+	globalConfig.RLock()
+	defer globalConfig.RUnlock()
+
+	// Fall back through the configured languages, the last one resolves the miss with its LookupPolicy
+	return fallbackLocale(dom, func(l *Locale) bool {
+		_, _, ok := l.LookupODC(dom, str, n, ctx)
+		return ok
+	}).GetODC(dom, str, n, ctx, vars...)
 }
 
 // GetC uses the default domain globally set to return the corresponding Translation of the given string in the given context.
@@ -308,15 +399,11 @@ func GetDC(dom, str, ctx string, vars ...interface{}) string {
 	globalConfig.RLock()
 	defer globalConfig.RUnlock()
 
-	var tr string
-	for i, locale := range globalConfig.locales {
-		if !locale.IsTranslatedDC(dom, str, ctx) && i < (len(globalConfig.locales)-1) {
-			continue
-		}
-		tr = locale.GetDC(dom, str, ctx, vars...)
-		break
-	}
-	return tr
+	// Fall back through the configured languages, the last one resolves the miss with its LookupPolicy
+	return fallbackLocale(dom, func(l *Locale) bool {
+		_, ok := l.LookupDC(dom, str, ctx)
+		return ok
+	}).GetDC(dom, str, ctx, vars...)
 }
 
 // GetNDC retrieves the (N)th plural form of Translation in the given domain for a given string.
@@ -325,19 +412,27 @@ func GetNDC(dom, str, plural string, n int, ctx string, vars ...interface{}) str
 	// Try to load default package Locales
 	loadLocales(false)
 
-	// Return Translation
 	globalConfig.RLock()
 	defer globalConfig.RUnlock()
 
-	var tr string
-	for i, locale := range globalConfig.locales {
-		if !locale.IsTranslatedNDC(dom, str, n, ctx) && i < (len(globalConfig.locales)-1) {
-			continue
+	// Fall back through the configured languages, the last one resolves the miss with its LookupPolicy
+	return fallbackLocale(dom, func(l *Locale) bool {
+		_, _, ok := l.LookupNDC(dom, str, plural, n, ctx)
+		return ok
+	}).GetNDC(dom, str, plural, n, ctx, vars...)
+}
+
+// fallbackLocale loads dom in the configured locales and returns the first one where found is true,
+// or else the last one. Must be called with globalConfig locked.
+func fallbackLocale(dom string, found func(*Locale) bool) *Locale {
+	locale := &Locale{}
+	for _, locale = range globalConfig.locales {
+		locale.loadDomain(dom)
+		if found(locale) {
+			break
 		}
-		tr = locale.GetNDC(dom, str, plural, n, ctx, vars...)
-		break
 	}
-	return tr
+	return locale
 }
 
 // IsTranslated reports whether a string is translated in given languages.
@@ -374,7 +469,7 @@ func IsTranslatedND(dom, str string, n int, langs ...string) bool {
 		lang = SimplifiedLocale(lang)
 
 		for _, supportedLocale := range globalConfig.locales {
-			if lang != supportedLocale.GetActualLanguage(dom) {
+			if lang != supportedLocale.actualLanguage(dom) {
 				continue
 			}
 			return supportedLocale.IsTranslatedND(dom, str, n)
@@ -417,7 +512,7 @@ func IsTranslatedNDC(dom, str string, n int, ctx string, langs ...string) bool {
 		lang = SimplifiedLocale(lang)
 
 		for _, locale := range globalConfig.locales {
-			if lang != locale.GetActualLanguage(dom) {
+			if lang != locale.actualLanguage(dom) {
 				continue
 			}
 			return locale.IsTranslatedNDC(dom, str, n, ctx)
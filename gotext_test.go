@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+	"testing/fstest"
 )
 
 func TestGettersSetters(t *testing.T) {
@@ -736,3 +737,50 @@ func TestGotext_MissingWrappers(t *testing.T) {
 		t.Error("GetD failed")
 	}
 }
+
+func TestGetNDecimal(t *testing.T) {
+	Configure("fixtures/", "en_US", "default")
+
+	if tr := GetNDecimal("One with var: %s", "Several with vars: %s", "1", "1"); tr != "This one is the singular: 1" {
+		t.Errorf("Expected 'This one is the singular: 1' but got '%s'", tr)
+	}
+	if tr := GetNDecimal("One with var: %s", "Several with vars: %s", "1.0", "1.0"); tr != "This one is the plural: 1.0" {
+		t.Errorf("Expected 'This one is the plural: 1.0' but got '%s'", tr)
+	}
+	if tr := GetNFloat("One with var: %s", "Several with vars: %s", 0.5, "0.5"); tr != "This one is the plural: 0.5" {
+		t.Errorf("Expected 'This one is the plural: 0.5' but got '%s'", tr)
+	}
+}
+
+func TestPackageLookupPolicy(t *testing.T) {
+	fsys := fstest.MapFS{
+		"es/default.po": {Data: []byte(`msgid "Hello %s"
+msgstr "Hola %d"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+`)},
+	}
+	l := NewLocaleFS("es", fsys)
+	l.SetLookupPolicy(LookupPolicy{CheckFormat: true, Miss: MissReturnSentinel, Sentinel: "???"})
+	l.AddDomain("default")
+	SetLocales([]*Locale{l})
+
+	// Broken translations fall back to the source string
+	if tr := GetD("default", "Hello %s", "World"); tr != "Hello World" {
+		t.Errorf("Expected 'Hello World' but got '%s'", tr)
+	}
+	if tr := GetND("default", "One file", "%d files", 3, 3); tr != "3 archivos" {
+		t.Errorf("Expected '3 archivos' but got '%s'", tr)
+	}
+
+	// Misses are resolved by the policy of the locale
+	if tr := GetD("default", "Missing"); tr != "???" {
+		t.Errorf("Expected '???' but got '%s'", tr)
+	}
+	if tr := GetNDC("default", "One", "Many", 2, "Ctx"); tr != "???" {
+		t.Errorf("Expected '???' but got '%s'", tr)
+	}
+}
@@ -0,0 +1,284 @@
+package gotext
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Severity of a lint finding
+type Severity int
+
+const (
+	// SeverityInfo is for findings that are usually fine, like untranslated entries.
+	SeverityInfo Severity = iota
+
+	// SeverityWarning is for findings that may show wrong text to users.
+	SeverityWarning
+
+	// SeverityError is for findings that show wrong text to users.
+	SeverityError
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// MarshalText implements encoding.TextMarshaler, using the String form
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Severity) UnmarshalText(text []byte) error {
+	v, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// ParseSeverity returns the severity named s: "info", "warning" or "error"
+func ParseSeverity(s string) (Severity, error) {
+	for i, name := range severityNames {
+		if strings.EqualFold(s, name) {
+			return Severity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q", s)
+}
+
+// Lint rule IDs, with the severity of their findings
+const (
+	// LintPrintf reports translations whose printf verbs don't match the msgid, as CheckFormats does. Error.
+	LintPrintf = "printf"
+
+	// LintNamedPlaceholders reports translations whose %(name)s placeholders, used by Sprintf, differ from the msgid. Error.
+	LintNamedPlaceholders = "named-placeholders"
+
+	// LintNewline reports translations that don't start or end with a newline like the msgid, as msgfmt --check does. Error.
+	LintNewline = "newline"
+
+	// LintWhitespace reports translations with leading or trailing whitespace different from the msgid. Warning.
+	LintWhitespace = "whitespace"
+
+	// LintPluralForms reports plural translations without one form per nplurals of the catalog, or with more. Error.
+	LintPluralForms = "plural-forms"
+
+	// LintOrdinalForms reports ordinal translations without one form per ordinal form of the catalog, or with more. Error.
+	LintOrdinalForms = "ordinal-forms"
+
+	// LintDuplicate reports entries found more than once in the parsed file, the last one being used. Error.
+	LintDuplicate = "duplicate"
+
+	// LintUntranslatedContext reports entries with a context and no translation. Warning.
+	LintUntranslatedContext = "untranslated-context"
+
+	// LintEmpty reports entries without context and no translation. Info.
+	LintEmpty = "empty"
+)
+
+// LintFinding is a problem found by Domain.Lint in an entry.
+type LintFinding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+
+	// Line of the msgid in the parsed PO file, 0 if unknown, as for MO files.
+	Line int `json:"line,omitempty"`
+
+	Context string `json:"context,omitempty"`
+	MsgID   string `json:"msgid"`
+	Message string `json:"message"`
+}
+
+func (f LintFinding) String() string {
+	var b strings.Builder
+	if f.Line > 0 {
+		fmt.Fprintf(&b, "%d: ", f.Line)
+	}
+	fmt.Fprintf(&b, "%s [%s] ", f.Severity, f.Rule)
+	if f.Context != "" {
+		fmt.Fprintf(&b, "msgctxt %q ", f.Context)
+	}
+	fmt.Fprintf(&b, "msgid %q: %s", f.MsgID, f.Message)
+	return b.String()
+}
+
+// Lint checks the entries of the domain for common translation mistakes, described by the Lint* rule IDs.
+// Findings are sorted by line, context and msgid.
+func (do *Domain) Lint() []LintFinding {
+	d := do.load()
+	l := &linter{nplurals: d.numPlurals(), nordinals: d.numOrdinals()}
+
+	for _, id := range sortedIDs(d.translations) {
+		l.entry("", d.translations[id])
+	}
+	for _, ctx := range sortedContexts(d.contextTranslations) {
+		for _, id := range sortedIDs(d.contextTranslations[ctx]) {
+			l.entry(ctx, d.contextTranslations[ctx][id])
+		}
+	}
+	for _, dup := range d.duplicates {
+		l.add(LintDuplicate, SeverityError, dup.line, dup.ctx, dup.id, "entry is repeated, the last one is used")
+	}
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Context != b.Context {
+			return a.Context < b.Context
+		}
+		return a.MsgID < b.MsgID
+	})
+	return l.findings
+}
+
+type linter struct {
+	nplurals  int
+	nordinals int
+	findings  []LintFinding
+}
+
+func (l *linter) add(rule string, severity Severity, line int, ctx, id, format string, args ...interface{}) {
+	l.findings = append(l.findings, LintFinding{
+		Rule:     rule,
+		Severity: severity,
+		Line:     line,
+		Context:  ctx,
+		MsgID:    id,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) entry(ctx string, trans *Translation) {
+	// Header, and empty entries left by msgctxt lines
+	if trans.ID == "" {
+		return
+	}
+
+	translated := false
+	for _, str := range trans.Trs {
+		if str != "" {
+			translated = true
+		}
+	}
+	if !translated {
+		if ctx != "" {
+			l.add(LintUntranslatedContext, SeverityWarning, trans.line, ctx, trans.ID, "no translation, lookups with this context miss")
+		} else {
+			l.add(LintEmpty, SeverityInfo, trans.line, ctx, trans.ID, "no translation")
+		}
+		return
+	}
+
+	if trans.PluralID != "" {
+		if trans.HasFlag("ordinal") {
+			l.forms(LintOrdinalForms, "ordinal forms", l.nordinals, ctx, trans)
+		} else {
+			l.forms(LintPluralForms, "nplurals", l.nplurals, ctx, trans)
+		}
+	}
+
+	if re.MatchString(trans.ID) || re.MatchString(trans.PluralID) {
+		l.namedPlaceholders(ctx, trans)
+	} else {
+		for _, err := range translationFormatErrors(ctx, trans) {
+			l.add(LintPrintf, SeverityError, trans.line, ctx, trans.ID, "msgstr[%d]: %v", err.Form, err.Err)
+		}
+	}
+
+	l.whitespace(ctx, trans)
+}
+
+// forms reports the msgstr of trans missing for a catalog with n forms, and the ones beyond
+func (l *linter) forms(rule, name string, n int, ctx string, trans *Translation) {
+	var missing, extra []string
+	for form := 0; form < n; form++ {
+		if trans.Trs[form] == "" {
+			missing = append(missing, fmt.Sprint(form))
+		}
+	}
+	for _, form := range sortedForms(trans) {
+		if form >= n {
+			extra = append(extra, fmt.Sprint(form))
+		}
+	}
+	if len(missing) > 0 {
+		l.add(rule, SeverityError, trans.line, ctx, trans.ID, "missing msgstr[%s], %s=%d", strings.Join(missing, "], msgstr["), name, n)
+	}
+	if len(extra) > 0 {
+		l.add(rule, SeverityError, trans.line, ctx, trans.ID, "msgstr[%s] beyond %s=%d", strings.Join(extra, "], msgstr["), name, n)
+	}
+}
+
+// namedPlaceholders compares the %(name)s placeholders of each form with the msgid or msgid_plural
+func (l *linter) namedPlaceholders(ctx string, trans *Translation) {
+	for _, form := range sortedForms(trans) {
+		str := trans.Trs[form]
+		if str == "" {
+			continue
+		}
+		names := placeholderNames(str)
+		if names == placeholderNames(trans.ID) || trans.PluralID != "" && names == placeholderNames(trans.PluralID) {
+			continue
+		}
+		want := placeholderNames(trans.ID)
+		if form > 0 && trans.PluralID != "" {
+			want = placeholderNames(trans.PluralID)
+		}
+		l.add(LintNamedPlaceholders, SeverityError, trans.line, ctx, trans.ID, "msgstr[%d] has placeholders %s instead of %s", form, names, want)
+	}
+}
+
+// placeholderNames returns the sorted, distinct names of the %(name)s placeholders in s, like "[count name]"
+func placeholderNames(s string) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range re.FindAllStringSubmatch(s, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return "[" + strings.Join(names, " ") + "]"
+}
+
+// whitespace compares the leading and trailing whitespace of each form with the msgid
+func (l *linter) whitespace(ctx string, trans *Translation) {
+	for _, form := range sortedForms(trans) {
+		str := trans.Trs[form]
+		if str == "" {
+			continue
+		}
+
+		if strings.HasPrefix(str, "\n") != strings.HasPrefix(trans.ID, "\n") {
+			l.add(LintNewline, SeverityError, trans.line, ctx, trans.ID, "msgstr[%d] and msgid don't both begin with a newline", form)
+		} else if leadingSpace(str) != leadingSpace(trans.ID) {
+			l.add(LintWhitespace, SeverityWarning, trans.line, ctx, trans.ID, "msgstr[%d] begins with %q, msgid with %q", form, leadingSpace(str), leadingSpace(trans.ID))
+		}
+
+		if strings.HasSuffix(str, "\n") != strings.HasSuffix(trans.ID, "\n") {
+			l.add(LintNewline, SeverityError, trans.line, ctx, trans.ID, "msgstr[%d] and msgid don't both end with a newline", form)
+		} else if trailingSpace(str) != trailingSpace(trans.ID) {
+			l.add(LintWhitespace, SeverityWarning, trans.line, ctx, trans.ID, "msgstr[%d] ends with %q, msgid with %q", form, trailingSpace(str), trailingSpace(trans.ID))
+		}
+	}
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeftFunc(s, unicode.IsSpace))]
+}
+
+func trailingSpace(s string) string {
+	return s[len(strings.TrimRightFunc(s, unicode.IsSpace)):]
+}
@@ -0,0 +1,129 @@
+package gotext
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDomain_Lint(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Language: es\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "Hello %s"
+msgstr "Hola %d"
+
+msgid "Hi %(name)s"
+msgstr "Hola %(nombre)s"
+
+msgid "Done\n"
+msgstr "Hecho"
+
+msgid "Save "
+msgstr "Guardar"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+
+msgid "Open"
+msgstr "Abrir"
+
+msgid "Open"
+msgstr "Abre"
+
+msgid "Close"
+msgstr ""
+
+msgctxt "menu"
+msgid "Close"
+msgstr ""
+
+msgid "Good %s"
+msgstr "Bien %s"
+`))
+
+	want := []struct {
+		line     int
+		rule     string
+		severity Severity
+	}{
+		{6, LintPrintf, SeverityError},
+		{9, LintNamedPlaceholders, SeverityError},
+		{12, LintNewline, SeverityError},
+		{15, LintWhitespace, SeverityWarning},
+		{18, LintPluralForms, SeverityError},
+		{25, LintDuplicate, SeverityError},
+		{28, LintEmpty, SeverityInfo},
+		{32, LintUntranslatedContext, SeverityWarning},
+	}
+
+	findings := po.GetDomain().Lint()
+	if len(findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %v", len(want), findings)
+	}
+	for i, w := range want {
+		f := findings[i]
+		if f.Line != w.line || f.Rule != w.rule || f.Severity != w.severity {
+			t.Errorf("Expected %s %s at line %d, got %v", w.severity, w.rule, w.line, f)
+		}
+	}
+
+	if s := findings[4].String(); s != `18: error [plural-forms] msgid "One file": missing msgstr[1], nplurals=2` {
+		t.Errorf("Unexpected finding text %s", s)
+	}
+	if tr := po.Get("Open"); tr != "Abre" {
+		t.Errorf("Expected the last duplicate to be used, got '%s'", tr)
+	}
+}
+
+func TestDomain_LintOrdinalForms(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Language: en\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#, ordinal
+msgid "%d. place"
+msgid_plural "%d. place"
+msgstr[0] "%dst place"
+msgstr[1] "%dnd place"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d file"
+msgstr[1] "%d files"
+`))
+
+	findings := po.GetDomain().Lint()
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %v", findings)
+	}
+	if s := findings[0].String(); s != `7: error [ordinal-forms] msgid "%d. place": missing msgstr[2], msgstr[3], ordinal forms=4` {
+		t.Errorf("Unexpected finding text %s", s)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for _, s := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		parsed, err := ParseSeverity(s.String())
+		if err != nil || parsed != s {
+			t.Errorf("ParseSeverity(%q) = %v, %v", s, parsed, err)
+		}
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("Expected unknown severities to fail")
+	}
+
+	b, err := json.Marshal(LintFinding{Rule: LintEmpty, Severity: SeverityWarning})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f LintFinding
+	if err = json.Unmarshal(b, &f); err != nil || f.Severity != SeverityWarning {
+		t.Errorf("Severity didn't round-trip through %s: %v", b, err)
+	}
+}
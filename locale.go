@@ -11,6 +11,7 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"strconv"
 	"sync"
 )
 
@@ -55,9 +56,18 @@ type Locale struct {
 	// List of available Domains for this locale.
 	Domains map[string]Translator
 
+	// Catalog file loaded by AddDomain for each domain, used to reload them.
+	files map[string]string
+
+	// Outcome of looking up each domain on disk, including misses, until Reload is called.
+	loads map[string]*domainLoad
+
 	// First AddDomain is default Domain
 	defaultDomain string
 
+	// Lookup policy applied to the domains loaded by AddDomain
+	policy LookupPolicy
+
 	// Sync Mutex
 	sync.RWMutex
 
@@ -161,11 +171,101 @@ func (l *Locale) fileExists(filename string) bool {
 	return err == nil
 }
 
+// domainLoad holds the outcome of looking up a domain file, so concurrent first uses load it only once.
+type domainLoad struct {
+	once sync.Once
+
+	// Whether a file was found
+	found bool
+
+	// Language actually used, as returned by GetActualLanguage
+	lang string
+}
+
+// loadDomain makes sure the domain has been looked up on disk, loading it on first use.
+// It reports whether the domain is available.
+func (l *Locale) loadDomain(dom string) bool {
+	return l.domainLoad(dom).found
+}
+
+// actualLanguage is a cached version of GetActualLanguage.
+func (l *Locale) actualLanguage(dom string) string {
+	return l.domainLoad(dom).lang
+}
+
+func (l *Locale) domainLoad(dom string) *domainLoad {
+	l.RLock()
+	ld := l.loads[dom]
+	l.RUnlock()
+
+	if ld == nil {
+		l.Lock()
+		if ld = l.loads[dom]; ld == nil {
+			ld = new(domainLoad)
+			if l.loads == nil {
+				l.loads = make(map[string]*domainLoad)
+			}
+			l.loads[dom] = ld
+		}
+		l.Unlock()
+	}
+
+	ld.once.Do(func() {
+		l.RLock()
+		_, ok := l.Domains[dom]
+		l.RUnlock()
+
+		// Added by AddTranslator or decoded
+		if ok {
+			ld.found = true
+			ld.lang = l.GetActualLanguage(dom)
+			return
+		}
+
+		l.addDomain(dom, ld)
+	})
+
+	return ld
+}
+
+// Reload forgets which domains were found or missing on disk, so they're looked up again on next use,
+// and reloads the domains previously loaded from files.
+func (l *Locale) Reload() {
+	l.Lock()
+	l.loads = nil
+	doms := make([]string, 0, len(l.files))
+	for dom := range l.files {
+		doms = append(doms, dom)
+	}
+	l.Unlock()
+
+	for _, dom := range doms {
+		l.AddDomain(dom)
+	}
+}
+
 // AddDomain creates a new domain for a given locale object and initializes the Po object.
 // If the domain exists, it gets reloaded.
 func (l *Locale) AddDomain(dom string) {
+	ld := new(domainLoad)
+	ld.once.Do(func() {
+		l.addDomain(dom, ld)
+	})
+
+	l.Lock()
+	if l.loads == nil {
+		l.loads = make(map[string]*domainLoad)
+	}
+	l.loads[dom] = ld
+	l.Unlock()
+}
+
+// addDomain loads the domain file and records the outcome in ld.
+func (l *Locale) addDomain(dom string, ld *domainLoad) {
 	var poObj Translator
 
+	ld.lang = l.GetActualLanguage(dom)
+
 	file := l.findExt(dom, "po")
 	if file != "" {
 		poObj = NewPoFS(l.fs)
@@ -178,10 +278,14 @@ func (l *Locale) AddDomain(dom string) {
 			// Parse file.
 			poObj.ParseFile(file)
 		} else {
-			// fallback return if no file found with
+			// fallback return if no file found with, keeping any domain previously loaded
+			l.RLock()
+			_, ld.found = l.Domains[dom]
+			l.RUnlock()
 			return
 		}
 	}
+	ld.found = true
 
 	// Save new domain
 	l.Lock()
@@ -189,10 +293,18 @@ func (l *Locale) AddDomain(dom string) {
 	if l.Domains == nil {
 		l.Domains = make(map[string]Translator)
 	}
+	if l.files == nil {
+		l.files = make(map[string]string)
+	}
 	if l.defaultDomain == "" {
 		l.defaultDomain = dom
 	}
+	if d := poObj.GetDomain(); d != nil {
+		d.SetLookupPolicy(l.policy)
+		d.setDefaultLanguage(l.lang)
+	}
 	l.Domains[dom] = poObj
+	l.files[dom] = file
 
 	// Unlock "Save new domain"
 	l.Unlock()
@@ -210,9 +322,26 @@ func (l *Locale) AddTranslator(dom string, tr Translator) {
 	}
 	l.Domains[dom] = tr
 
+	// Not backed by a file anymore, so it must not be reloaded from one
+	delete(l.files, dom)
+
 	l.Unlock()
 }
 
+// SetLookupPolicy sets the LookupPolicy of the domains loaded by AddDomain, including the ones loaded later,
+// and how lookups on missing domains are resolved. Translators added with AddTranslator keep their own policy.
+func (l *Locale) SetLookupPolicy(p LookupPolicy) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.policy = p
+	for dom := range l.files {
+		if d := l.Domains[dom].GetDomain(); d != nil {
+			d.SetLookupPolicy(p)
+		}
+	}
+}
+
 // GetDomain is the domain getter for Locale configuration
 func (l *Locale) GetDomain() string {
 	l.RLock()
@@ -263,7 +392,7 @@ func (l *Locale) GetD(dom, str string, vars ...interface{}) string {
 		}
 	}
 
-	return FormatString(str, vars...)
+	return l.policy.missFormat(l.policy.miss(str), vars)
 }
 
 // GetND retrieves the (N)th plural form of Translation in the given domain for the given string.
@@ -282,10 +411,7 @@ func (l *Locale) GetND(dom, str, plural string, n int, vars ...interface{}) stri
 	}
 
 	// Use western default rule (plural > 1) to handle missing domain default result.
-	if n == 1 {
-		return FormatString(str, vars...)
-	}
-	return FormatString(plural, vars...)
+	return l.policy.missFormat(l.policy.missN(str, plural, n), vars)
 }
 
 // GetC uses a domain "default" to return the corresponding Translation of the given string in the given context.
@@ -315,7 +441,7 @@ func (l *Locale) GetDC(dom, str, ctx string, vars ...interface{}) string {
 		}
 	}
 
-	return FormatString(str, vars...)
+	return l.policy.missFormat(l.policy.miss(str), vars)
 }
 
 // GetNDC retrieves the (N)th plural form of Translation in the given domain for the given string in the given context.
@@ -334,10 +460,212 @@ func (l *Locale) GetNDC(dom, str, plural string, n int, ctx string, vars ...inte
 	}
 
 	// Use western default rule (plural > 1) to handle missing domain default result.
-	if n == 1 {
-		return FormatString(str, vars...)
+	return l.policy.missFormat(l.policy.missN(str, plural, n), vars)
+}
+
+// lookuper is implemented by LookupTranslator and Domain objects
+type lookuper interface {
+	Lookup(str string) (string, bool)
+	LookupN(str, plural string, n int) (string, int, bool)
+	LookupC(str, ctx string) (string, bool)
+	LookupNC(str, plural string, n int, ctx string) (string, int, bool)
+}
+
+// lookuper returns the object to run lookups on for the given domain, or nil if the domain isn't loaded.
+// Translators that don't implement LookupTranslator are looked up through their Domain.
+func (l *Locale) lookuper(dom string) lookuper {
+	l.RLock()
+	tr := l.Domains[dom]
+	l.RUnlock()
+
+	if tr == nil {
+		return nil
+	}
+	if lt, ok := tr.(LookupTranslator); ok {
+		return lt
 	}
-	return FormatString(plural, vars...)
+	if d := tr.GetDomain(); d != nil {
+		return d
+	}
+	return nil
+}
+
+// Lookup uses the default domain to return the corresponding Translation of a given string,
+// reporting whether it was found. When it wasn't, the same string received is returned.
+func (l *Locale) Lookup(str string) (string, bool) {
+	return l.LookupD(l.GetDomain(), str)
+}
+
+// LookupN retrieves the (N)th plural form of Translation for the given string in the default domain,
+// along with the plural form index used and whether the translation was found.
+func (l *Locale) LookupN(str, plural string, n int) (string, int, bool) {
+	return l.LookupND(l.GetDomain(), str, plural, n)
+}
+
+// LookupC uses the default domain to return the corresponding Translation of the given string in the given context,
+// reporting whether it was found.
+func (l *Locale) LookupC(str, ctx string) (string, bool) {
+	return l.LookupDC(l.GetDomain(), str, ctx)
+}
+
+// LookupNC retrieves the (N)th plural form of Translation for the given string in the given context in the default domain,
+// along with the plural form index used and whether the translation was found.
+func (l *Locale) LookupNC(str, plural string, n int, ctx string) (string, int, bool) {
+	return l.LookupNDC(l.GetDomain(), str, plural, n, ctx)
+}
+
+// LookupD returns the corresponding Translation in the given domain for the given string,
+// reporting whether it was found. When it wasn't, the same string received is returned.
+func (l *Locale) LookupD(dom, str string) (string, bool) {
+	if lu := l.lookuper(dom); lu != nil {
+		return lu.Lookup(str)
+	}
+	return l.missingDomainPolicy().miss(str), false
+}
+
+// LookupND retrieves the (N)th plural form of Translation in the given domain for the given string,
+// along with the plural form index used and whether the translation was found.
+func (l *Locale) LookupND(dom, str, plural string, n int) (string, int, bool) {
+	if lu := l.lookuper(dom); lu != nil {
+		return lu.LookupN(str, plural, n)
+	}
+
+	// Use western default rule (plural > 1) to handle missing domain default result.
+	p := l.missingDomainPolicy()
+	return p.missN(str, plural, n), englishPluralForm(n), false
+}
+
+// GetNDecimal retrieves the plural form of Translation in the default domain for the given string matching n,
+// a number formatted as a decimal like "1.5" or "2,0". See Domain.GetNDecimal.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (l *Locale) GetNDecimal(str, plural, n string, vars ...interface{}) string {
+	return l.GetNDDecimal(l.GetDomain(), str, plural, n, vars...)
+}
+
+// GetNFloat retrieves the plural form of Translation in the default domain for the given string matching n.
+// See Domain.GetNFloat.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (l *Locale) GetNFloat(str, plural string, n float64, vars ...interface{}) string {
+	return l.GetNDDecimal(l.GetDomain(), str, plural, strconv.FormatFloat(n, 'f', -1, 64), vars...)
+}
+
+// GetNDDecimal retrieves the plural form of Translation in the given domain for the given string matching n,
+// a number formatted as a decimal like "1.5" or "2,0". See Domain.GetNDecimal.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (l *Locale) GetNDDecimal(dom, str, plural, n string, vars ...interface{}) string {
+	if d := l.domain(dom); d != nil {
+		return d.GetNDecimal(str, plural, n, vars...)
+	}
+
+	// Use the English rules to handle missing domain default result.
+	p := l.missingDomainPolicy()
+	tr, _, _ := (&domainData{policy: *p}).lookupNDecimal(str, plural, n)
+	return p.missFormat(tr, vars)
+}
+
+// LookupNDDecimal retrieves the plural form of Translation in the given domain for the given string matching n,
+// a number formatted as a decimal, along with the plural form index used and whether the translation was found.
+func (l *Locale) LookupNDDecimal(dom, str, plural, n string) (string, int, bool) {
+	if d := l.domain(dom); d != nil {
+		return d.LookupNDecimal(str, plural, n)
+	}
+
+	// Use the English rules to handle missing domain default result.
+	return (&domainData{policy: *l.missingDomainPolicy()}).lookupNDecimal(str, plural, n)
+}
+
+// GetO retrieves the ordinal form of Translation in the default domain for the given string matching n.
+// See Domain.GetO.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (l *Locale) GetO(str string, n int, vars ...interface{}) string {
+	return l.GetOD(l.GetDomain(), str, n, vars...)
+}
+
+// GetOC retrieves the ordinal form of Translation in the default domain for the given string matching n in the given context.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (l *Locale) GetOC(str string, n int, ctx string, vars ...interface{}) string {
+	return l.GetODC(l.GetDomain(), str, n, ctx, vars...)
+}
+
+// GetOD retrieves the ordinal form of Translation in the given domain for the given string matching n.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (l *Locale) GetOD(dom, str string, n int, vars ...interface{}) string {
+	if d := l.domain(dom); d != nil {
+		return d.GetO(str, n, vars...)
+	}
+
+	p := l.missingDomainPolicy()
+	return p.missFormat(p.miss(str), vars)
+}
+
+// GetODC retrieves the ordinal form of Translation in the given domain for the given string matching n in the given context.
+// Supports optional parameters (vars... interface{}) to be inserted on the formatted string using the fmt.Printf syntax.
+func (l *Locale) GetODC(dom, str string, n int, ctx string, vars ...interface{}) string {
+	if d := l.domain(dom); d != nil {
+		return d.GetOC(str, n, ctx, vars...)
+	}
+
+	p := l.missingDomainPolicy()
+	return p.missFormat(p.miss(str), vars)
+}
+
+// LookupOD retrieves the ordinal form of Translation in the given domain for the given string matching n,
+// along with the form index used and whether the translation was found.
+func (l *Locale) LookupOD(dom, str string, n int) (string, int, bool) {
+	if d := l.domain(dom); d != nil {
+		return d.LookupO(str, n)
+	}
+	return l.missingDomainPolicy().miss(str), 0, false
+}
+
+// LookupODC retrieves the ordinal form of Translation in the given domain for the given string matching n
+// in the given context, along with the form index used and whether the translation was found.
+func (l *Locale) LookupODC(dom, str string, n int, ctx string) (string, int, bool) {
+	if d := l.domain(dom); d != nil {
+		return d.LookupOC(str, n, ctx)
+	}
+	return l.missingDomainPolicy().miss(str), 0, false
+}
+
+// domain returns the Domain object of the given domain, or nil if the domain isn't loaded.
+func (l *Locale) domain(dom string) *Domain {
+	l.RLock()
+	tr := l.Domains[dom]
+	l.RUnlock()
+
+	if tr == nil {
+		return nil
+	}
+	return tr.GetDomain()
+}
+
+// LookupDC returns the corresponding Translation in the given domain for the given string in the given context,
+// reporting whether it was found.
+func (l *Locale) LookupDC(dom, str, ctx string) (string, bool) {
+	if lu := l.lookuper(dom); lu != nil {
+		return lu.LookupC(str, ctx)
+	}
+	return l.missingDomainPolicy().miss(str), false
+}
+
+// LookupNDC retrieves the (N)th plural form of Translation in the given domain for the given string in the given context,
+// along with the plural form index used and whether the translation was found.
+func (l *Locale) LookupNDC(dom, str, plural string, n int, ctx string) (string, int, bool) {
+	if lu := l.lookuper(dom); lu != nil {
+		return lu.LookupNC(str, plural, n, ctx)
+	}
+
+	// Use western default rule (plural > 1) to handle missing domain default result.
+	p := l.missingDomainPolicy()
+	return p.missN(str, plural, n), englishPluralForm(n), false
+}
+
+// missingDomainPolicy returns the policy to resolve lookups on domains not loaded
+func (l *Locale) missingDomainPolicy() *LookupPolicy {
+	l.RLock()
+	p := l.policy
+	l.RUnlock()
+	return &p
 }
 
 // GetTranslations returns a copy of all translations in all domains of this locale. It does not support contexts.
@@ -7,8 +7,11 @@ package gotext
 
 import (
 	"embed"
+	"io/fs"
 	"os"
 	"path"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"testing/fstest"
 )
@@ -792,3 +795,173 @@ func TestLocale_MissingIsTranslatedWrappers(t *testing.T) {
 		t.Error("Expected false for missing domain")
 	}
 }
+
+// statCountingFS counts Stat calls, to check how often the filesystem is looked up
+type statCountingFS struct {
+	fstest.MapFS
+	stats atomic.Int64
+}
+
+func (f *statCountingFS) Stat(name string) (fs.FileInfo, error) {
+	f.stats.Add(1)
+	return f.MapFS.Stat(name)
+}
+
+func TestLocale_MissingDomainIsCached(t *testing.T) {
+	filesystem := &statCountingFS{MapFS: fstest.MapFS{}}
+	l := NewLocaleFS("en_US", filesystem)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if l.loadDomain("missing") {
+				t.Error("Expected missing domain not to be found")
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := filesystem.stats.Load()
+	if stats == 0 {
+		t.Fatal("Expected the filesystem to be looked up")
+	}
+
+	for i := 0; i < 10; i++ {
+		l.loadDomain("missing")
+		l.actualLanguage("missing")
+	}
+	if filesystem.stats.Load() != stats {
+		t.Errorf("Expected missing domain lookups to be cached, got %d stats instead of %d", filesystem.stats.Load(), stats)
+	}
+
+	// Only an explicit reload picks up new files
+	filesystem.MapFS["en/LC_MESSAGES/missing.po"] = &fstest.MapFile{Data: []byte("msgid \"My text\"\nmsgstr \"Translated text\"\n")}
+	if l.loadDomain("missing") {
+		t.Error("Expected missing domain to stay cached until reload")
+	}
+
+	l.Reload()
+	if !l.loadDomain("missing") {
+		t.Error("Expected domain to be found after reload")
+	}
+	if tr := l.GetD("missing", "My text"); tr != translatedText {
+		t.Errorf("Expected '%s' but got '%s'", translatedText, tr)
+	}
+	if lang := l.actualLanguage("missing"); lang != "en" {
+		t.Errorf("Expected actual language 'en' but got '%s'", lang)
+	}
+}
+
+func TestLocale_Lookup(t *testing.T) {
+	l := NewLocale("fixtures/", "en_US")
+	l.AddDomain("default")
+
+	if tr, ok := l.Lookup("My text"); !ok || tr != translatedText {
+		t.Errorf("Expected ('%s', true) but got ('%s', %v)", translatedText, tr, ok)
+	}
+	if tr, idx, ok := l.LookupN("One with var: %s", "Several with vars: %s", 1); !ok || idx != 0 || tr != "This one is the singular: %s" {
+		t.Errorf("Expected singular translation but got ('%s', %d, %v)", tr, idx, ok)
+	}
+	if tr, ok := l.LookupC("Some random in a context", "Ctx"); !ok || tr != "Some random translation in a context" {
+		t.Errorf("Expected context translation but got ('%s', %v)", tr, ok)
+	}
+	if tr, idx, ok := l.LookupNC("One with var: %s", "Several with vars: %s", 2, "Ctx"); !ok || idx != 1 || tr != "This one is the plural in a Ctx context: %s" {
+		t.Errorf("Expected context plural translation but got ('%s', %d, %v)", tr, idx, ok)
+	}
+
+	// Missing domain
+	if tr, ok := l.LookupD("missing", "My text"); ok || tr != "My text" {
+		t.Errorf("Expected ('My text', false) but got ('%s', %v)", tr, ok)
+	}
+	if tr, idx, ok := l.LookupNDC("missing", "One", "Several", 2, "Ctx"); ok || idx != 1 || tr != "Several" {
+		t.Errorf("Expected ('Several', 1, false) but got ('%s', %d, %v)", tr, idx, ok)
+	}
+}
+
+func TestLocale_SetLookupPolicy(t *testing.T) {
+	l := NewLocale("fixtures/", "en_US")
+	l.SetLookupPolicy(LookupPolicy{ContextFallback: true, Miss: MissReturnSentinel, Sentinel: "<missing>"})
+	l.AddDomain("default")
+
+	if tr := l.GetC("My text", "Ctx"); tr != translatedText {
+		t.Errorf("Expected '%s' but got '%s'", translatedText, tr)
+	}
+	if tr := l.Get("Missing"); tr != "<missing>" {
+		t.Errorf("Expected '<missing>' but got '%s'", tr)
+	}
+	if tr := l.GetD("no-such-domain", "Missing %s", "var"); tr != "<missing>" {
+		t.Errorf("Expected '<missing>' but got '%s'", tr)
+	}
+	if tr, idx, ok := l.LookupND("no-such-domain", "One", "Many", 2); ok || idx != 1 || tr != "<missing>" {
+		t.Errorf("Expected ('<missing>', 1, false) but got ('%s', %d, %v)", tr, idx, ok)
+	}
+
+	l.SetLookupPolicy(LookupPolicy{})
+	if tr := l.Get("Missing"); tr != "Missing" {
+		t.Errorf("Expected 'Missing' but got '%s'", tr)
+	}
+}
+
+func TestLocale_LanguagePluralRule(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ru/default.po": {Data: []byte(`msgid "One day"
+msgid_plural "%d days"
+msgstr[0] "%d день"
+msgstr[1] "%d дня"
+msgstr[2] "%d дней"
+`)},
+	}
+	l := NewLocaleFS("ru_RU", fsys)
+	l.AddDomain("default")
+
+	for n, want := range map[int]string{1: "1 день", 2: "2 дня", 5: "5 дней", 21: "21 день"} {
+		if tr := l.GetN("One day", "%d days", n, n); tr != want {
+			t.Errorf("Expected '%s' for n = %d but got '%s'", want, n, tr)
+		}
+	}
+}
+
+func TestLocale_GetNDecimal(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cs/default.po": {Data: []byte(`msgid "%s liter"
+msgid_plural "%s liters"
+msgstr[0] "%s litr"
+msgstr[1] "%s litry"
+msgstr[2] "%s litrů"
+`)},
+	}
+	l := NewLocaleFS("cs_CZ", fsys)
+	l.AddDomain("default")
+
+	for n, want := range map[string]string{"1": "1 litr", "3": "3 litry", "7": "7 litrů", "1.5": "1.5 litrů"} {
+		if tr := l.GetNDecimal("%s liter", "%s liters", n, n); tr != want {
+			t.Errorf("Expected '%s' for %s but got '%s'", want, n, tr)
+		}
+	}
+	if tr := l.GetNDDecimal("missing", "%s liter", "%s liters", "1.0", "1.0"); tr != "1.0 liters" {
+		t.Errorf("Expected '1.0 liters' but got '%s'", tr)
+	}
+}
+
+func TestLocale_GetO(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sv/default.po": {Data: []byte(`msgid "%d. place"
+msgid_plural "%d. place"
+msgstr[0] "%d:a plats"
+msgstr[1] "%d:e plats"
+`)},
+	}
+	l := NewLocaleFS("sv_SE", fsys)
+	l.AddDomain("default")
+
+	for n, want := range map[int]string{1: "1:a plats", 2: "2:a plats", 3: "3:e plats", 11: "11:e plats", 22: "22:a plats"} {
+		if tr := l.GetO("%d. place", n, n); tr != want {
+			t.Errorf("Expected '%s' for %d but got '%s'", want, n, tr)
+		}
+	}
+	if tr := l.GetOD("missing", "%d. place", 3, 3); tr != "3. place" {
+		t.Errorf("Expected '3. place' but got '%s'", tr)
+	}
+}
@@ -8,6 +8,8 @@ package gotext
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io/fs"
 )
 
@@ -85,6 +87,11 @@ func (mo *Mo) Append(b []byte, str string, vars ...interface{}) []byte {
 	return mo.domain.Append(b, str, vars...)
 }
 
+// Lookup returns the translation for the given string, reporting whether it was found
+func (mo *Mo) Lookup(str string) (string, bool) {
+	return mo.domain.Lookup(str)
+}
+
 // GetN returns the translation for the given string and plural form
 func (mo *Mo) GetN(str, plural string, n int, vars ...interface{}) string {
 	return mo.domain.GetN(str, plural, n, vars...)
@@ -95,6 +102,48 @@ func (mo *Mo) AppendN(b []byte, str, plural string, n int, vars ...interface{})
 	return mo.domain.AppendN(b, str, plural, n, vars...)
 }
 
+// LookupN returns the translation for the given string and plural form,
+// reporting the plural form used and whether it was found
+func (mo *Mo) LookupN(str, plural string, n int) (string, int, bool) {
+	return mo.domain.LookupN(str, plural, n)
+}
+
+// GetNDecimal returns the translation for a number formatted as a decimal, like "1.5"
+func (mo *Mo) GetNDecimal(str, plural, n string, vars ...interface{}) string {
+	return mo.domain.GetNDecimal(str, plural, n, vars...)
+}
+
+// GetNFloat returns the translation for a float64
+func (mo *Mo) GetNFloat(str, plural string, n float64, vars ...interface{}) string {
+	return mo.domain.GetNFloat(str, plural, n, vars...)
+}
+
+// GetO returns the ordinal translation for the given string matching n
+func (mo *Mo) GetO(str string, n int, vars ...interface{}) string {
+	return mo.domain.GetO(str, n, vars...)
+}
+
+// GetOC returns the ordinal translation for the given string matching n in the given context
+func (mo *Mo) GetOC(str string, n int, ctx string, vars ...interface{}) string {
+	return mo.domain.GetOC(str, n, ctx, vars...)
+}
+
+// LookupO returns the ordinal translation, reporting the form used and whether it was found
+func (mo *Mo) LookupO(str string, n int) (string, int, bool) {
+	return mo.domain.LookupO(str, n)
+}
+
+// LookupOC returns the ordinal translation in the given context, reporting the form used and whether it was found
+func (mo *Mo) LookupOC(str string, n int, ctx string) (string, int, bool) {
+	return mo.domain.LookupOC(str, n, ctx)
+}
+
+// LookupNDecimal returns the translation for a number formatted as a decimal,
+// reporting the plural form used and whether it was found
+func (mo *Mo) LookupNDecimal(str, plural, n string) (string, int, bool) {
+	return mo.domain.LookupNDecimal(str, plural, n)
+}
+
 // GetC returns the translation for the given string and context
 func (mo *Mo) GetC(str, ctx string, vars ...interface{}) string {
 	return mo.domain.GetC(str, ctx, vars...)
@@ -105,6 +154,11 @@ func (mo *Mo) AppendC(b []byte, str, ctx string, vars ...interface{}) []byte {
 	return mo.domain.AppendC(b, str, ctx, vars...)
 }
 
+// LookupC returns the translation for the given string and context, reporting whether it was found
+func (mo *Mo) LookupC(str, ctx string) (string, bool) {
+	return mo.domain.LookupC(str, ctx)
+}
+
 // GetNC returns the translation for the given string, plural form and context
 func (mo *Mo) GetNC(str, plural string, n int, ctx string, vars ...interface{}) string {
 	return mo.domain.GetNC(str, plural, n, ctx, vars...)
@@ -115,6 +169,12 @@ func (mo *Mo) AppendNC(b []byte, str, plural string, n int, ctx string, vars ...
 	return mo.domain.AppendNC(b, str, plural, n, ctx, vars...)
 }
 
+// LookupNC returns the translation for the given string, plural form and context,
+// reporting the plural form used and whether it was found
+func (mo *Mo) LookupNC(str, plural string, n int, ctx string) (string, int, bool) {
+	return mo.domain.LookupNC(str, plural, n, ctx)
+}
+
 // IsTranslated checks if the given string is translated
 func (mo *Mo) IsTranslated(str string) bool {
 	return mo.domain.IsTranslated(str)
@@ -157,18 +217,25 @@ func (mo *Mo) ParseFile(f string) {
 
 // Parse loads the translations specified in the provided byte slice, in the GNU gettext .mo format
 func (mo *Mo) Parse(buf []byte) {
-	// Lock while parsing
-	mo.domain.trMutex.Lock()
-	mo.domain.pluralMutex.Lock()
-	defer mo.domain.trMutex.Unlock()
-	defer mo.domain.pluralMutex.Unlock()
+	_ = mo.parse(buf)
+}
+
+// parse does the work for Parse and reports why the given buffer couldn't be loaded.
+func (mo *Mo) parse(buf []byte) error {
+	// Lock while parsing, lookups keep using the current contents until the new ones are published
+	mo.domain.writeMutex.Lock()
+	defer mo.domain.writeMutex.Unlock()
+
+	mo.domain.dataBuffer = mo.domain.load().clone()
+	defer func() {
+		mo.domain.dataBuffer = nil
+	}()
 
 	r := bytes.NewReader(buf)
 
 	var magicNumber uint32
 	if err := binary.Read(r, binary.LittleEndian, &magicNumber); err != nil {
-		return
-		// return fmt.Errorf("gettext: %v", err)
+		return fmt.Errorf("gettext: %v", err)
 	}
 	var bo binary.ByteOrder
 	switch magicNumber {
@@ -177,8 +244,7 @@ func (mo *Mo) Parse(buf []byte) {
 	case MoMagicBigEndian:
 		bo = binary.BigEndian
 	default:
-		return
-		// return fmt.Errorf("gettext: %v", "invalid magic number")
+		return errors.New("gettext: invalid magic number")
 	}
 
 	var header struct {
@@ -191,71 +257,58 @@ func (mo *Mo) Parse(buf []byte) {
 		HashOffset   uint32
 	}
 	if err := binary.Read(r, bo, &header); err != nil {
-		return
-		// return fmt.Errorf("gettext: %v", err)
+		return fmt.Errorf("gettext: %v", err)
 	}
 	if v := header.MajorVersion; v != 0 && v != 1 {
-		return
-		// return fmt.Errorf("gettext: %v", "invalid version number")
+		return errors.New("gettext: invalid version number")
 	}
 	if v := header.MinorVersion; v != 0 && v != 1 {
-		return
-		// return fmt.Errorf("gettext: %v", "invalid version number")
+		return errors.New("gettext: invalid version number")
 	}
 
 	msgIDStart := make([]uint32, header.MsgIDCount)
 	msgIDLen := make([]uint32, header.MsgIDCount)
 	if _, err := r.Seek(int64(header.MsgIDOffset), 0); err != nil {
-		return
-		// return fmt.Errorf("gettext: %v", err)
+		return fmt.Errorf("gettext: %v", err)
 	}
 	for i := 0; i < int(header.MsgIDCount); i++ {
 		if err := binary.Read(r, bo, &msgIDLen[i]); err != nil {
-			return
-			// return fmt.Errorf("gettext: %v", err)
+			return fmt.Errorf("gettext: %v", err)
 		}
 		if err := binary.Read(r, bo, &msgIDStart[i]); err != nil {
-			return
-			// return fmt.Errorf("gettext: %v", err)
+			return fmt.Errorf("gettext: %v", err)
 		}
 	}
 
 	msgStrStart := make([]int32, header.MsgIDCount)
 	msgStrLen := make([]int32, header.MsgIDCount)
 	if _, err := r.Seek(int64(header.MsgStrOffset), 0); err != nil {
-		return
-		// return fmt.Errorf("gettext: %v", err)
+		return fmt.Errorf("gettext: %v", err)
 	}
 	for i := 0; i < int(header.MsgIDCount); i++ {
 		if err := binary.Read(r, bo, &msgStrLen[i]); err != nil {
-			return
-			// return fmt.Errorf("gettext: %v", err)
+			return fmt.Errorf("gettext: %v", err)
 		}
 		if err := binary.Read(r, bo, &msgStrStart[i]); err != nil {
-			return
-			// return fmt.Errorf("gettext: %v", err)
+			return fmt.Errorf("gettext: %v", err)
 		}
 	}
 
 	for i := 0; i < int(header.MsgIDCount); i++ {
 		if _, err := r.Seek(int64(msgIDStart[i]), 0); err != nil {
-			return
-			// return fmt.Errorf("gettext: %v", err)
+			return fmt.Errorf("gettext: %v", err)
 		}
 		msgIDData := make([]byte, msgIDLen[i])
 		if _, err := r.Read(msgIDData); err != nil {
-			return
-			// return fmt.Errorf("gettext: %v", err)
+			return fmt.Errorf("gettext: %v", err)
 		}
 
 		if _, err := r.Seek(int64(msgStrStart[i]), 0); err != nil {
-			return
-			// return fmt.Errorf("gettext: %v", err)
+			return fmt.Errorf("gettext: %v", err)
 		}
 		msgStrData := make([]byte, msgStrLen[i])
 		if _, err := r.Read(msgStrData); err != nil {
-			return
-			// return fmt.Errorf("gettext: %v", err)
+			return fmt.Errorf("gettext: %v", err)
 		}
 
 		if len(msgIDData) == 0 {
@@ -268,11 +321,17 @@ func (mo *Mo) Parse(buf []byte) {
 	// Parse headers
 	mo.domain.parseHeaders()
 
+	// Publish parsed contents
+	mo.domain.dataBuffer.ownedContexts = nil
+	mo.domain.data.Store(mo.domain.dataBuffer)
+
 	// set values on this struct
 	// this is for backwards compatibility
 	mo.Language = mo.domain.Language
 	mo.PluralForms = mo.domain.PluralForms
 	mo.Headers = mo.domain.Headers
+
+	return nil
 }
 
 func (mo *Mo) addTranslation(msgid, msgstr []byte) {
@@ -287,10 +346,10 @@ func (mo *Mo) addTranslation(msgid, msgstr []byte) {
 		msgid, msgctxt = d[1], d[0]
 	}
 
-	dd := bytes.Split(msgid, []byte(NulSeparator))
-	if len(dd) > 1 {
-		msgid = dd[0]
-		dd = dd[1:]
+	// Plural entries have the msgid_plural after a NUL
+	var dd [][]byte
+	if idx := bytes.Index(msgid, []byte(NulSeparator)); idx >= 0 {
+		msgid, dd = msgid[:idx], bytes.Split(msgid[idx+1:], []byte(NulSeparator))
 	}
 
 	translation.ID = string(msgid)
@@ -309,11 +368,8 @@ func (mo *Mo) addTranslation(msgid, msgstr []byte) {
 
 	if len(msgctxt) > 0 {
 		// With context...
-		if _, ok := mo.domain.contextTranslations[string(msgctxt)]; !ok {
-			mo.domain.contextTranslations[string(msgctxt)] = make(map[string]*Translation)
-		}
-		mo.domain.contextTranslations[string(msgctxt)][translation.ID] = translation
+		mo.domain.dataBuffer.context(string(msgctxt))[translation.ID] = translation
 	} else {
-		mo.domain.translations[translation.ID] = translation
+		mo.domain.dataBuffer.translations[translation.ID] = translation
 	}
 }
@@ -270,3 +270,16 @@ func TestMo_MissingWrappers(t *testing.T) {
 		t.Error("Mo.IsTranslatedNC failed")
 	}
 }
+
+func TestMo_PluralID(t *testing.T) {
+	mo := NewMo()
+	mo.ParseFile("fixtures/de/default.mo")
+
+	translations := mo.GetDomain().GetTranslations()
+	if tr := translations["My text"]; tr == nil || tr.PluralID != "" {
+		t.Errorf("Expected 'My text' without msgid_plural, got %+v", tr)
+	}
+	if tr := translations["One with var: %s"]; tr == nil || tr.PluralID != "Several with vars: %s" {
+		t.Errorf("Expected msgid_plural 'Several with vars: %%s', got %+v", tr)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var samples = makeSamples()
+
+// makeSamples returns 0 to 1099, and the first 120 numbers after each power of 10 up to 10^19,
+// which exercises the modulus and comparisons used by real plural rules.
+func makeSamples() []uint64 {
+	s := make([]uint64, 0, 1100+16*120)
+	for n := uint64(0); n < 1100; n++ {
+		s = append(s, n)
+	}
+	for p := uint64(10000); ; p *= 10 {
+		for n := p; n < p+120; n++ {
+			s = append(s, n)
+		}
+		if p > 1e18 {
+			break
+		}
+	}
+	return s
+}
+
+// Samples returns the representative values of n the analysis helpers evaluate expressions with.
+func Samples() []uint64 {
+	return append([]uint64(nil), samples...)
+}
+
+// Forms evaluates expr over Samples and returns each form produced with the smallest n producing it.
+func Forms(expr Expression) map[int]uint64 {
+	forms := make(map[int]uint64)
+	for _, n := range samples {
+		f := expr.Eval(n)
+		if _, ok := forms[f]; !ok {
+			forms[f] = n
+		}
+	}
+	return forms
+}
+
+// FormsError is returned by Validate when an expression doesn't produce exactly the forms 0 to NPlurals-1.
+type FormsError struct {
+	NPlurals int
+
+	// Forms produced out of 0 to NPlurals-1, with the smallest n producing each
+	OutOfRange map[int]uint64
+
+	// Forms never produced, in order
+	Unused []int
+}
+
+func (e *FormsError) Error() string {
+	var problems []string
+	if len(e.OutOfRange) > 0 {
+		forms := make([]int, 0, len(e.OutOfRange))
+		for f := range e.OutOfRange {
+			forms = append(forms, f)
+		}
+		sort.Ints(forms)
+		for _, f := range forms {
+			problems = append(problems, fmt.Sprintf("form %d for n = %d is out of range", f, e.OutOfRange[f]))
+		}
+	}
+	if len(e.Unused) > 0 {
+		unused := make([]string, len(e.Unused))
+		for i, f := range e.Unused {
+			unused[i] = strconv.Itoa(f)
+		}
+		problems = append(problems, "forms never used: "+strings.Join(unused, ", "))
+	}
+	return fmt.Sprintf("nplurals=%d: %s", e.NPlurals, strings.Join(problems, "; "))
+}
+
+// Validate checks that expr only produces forms 0 to nplurals-1, and all of them, over Samples.
+// It returns a *FormsError otherwise.
+func Validate(expr Expression, nplurals int) error {
+	if nplurals < 1 {
+		return fmt.Errorf("invalid nplurals=%d", nplurals)
+	}
+
+	e := &FormsError{NPlurals: nplurals}
+	forms := Forms(expr)
+	for f, n := range forms {
+		if f < 0 || f >= nplurals {
+			if e.OutOfRange == nil {
+				e.OutOfRange = make(map[int]uint64)
+			}
+			e.OutOfRange[f] = n
+		}
+	}
+	for f := 0; f < nplurals; f++ {
+		if _, ok := forms[f]; !ok {
+			e.Unused = append(e.Unused, f)
+		}
+	}
+
+	if e.OutOfRange != nil || e.Unused != nil {
+		return e
+	}
+	return nil
+}
+
+// Equivalent reports whether a and b produce the same forms over Samples.
+// When they don't, it returns the smallest n they differ on.
+func Equivalent(a, b Expression) (bool, uint64) {
+	for _, n := range samples {
+		if a.Eval(n) != b.Eval(n) {
+			return false, n
+		}
+	}
+	return true, 0
+}
+
+// ParseRule parses the value of a Plural-Forms header, as in "nplurals=2; plural=(n != 1);".
+// The expression isn't compiled; use Rule.Compile for that.
+func ParseRule(header string) (Rule, error) {
+	var r Rule
+	var hasNPlurals, hasPlural bool
+
+	for _, part := range strings.Split(header, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			if strings.TrimSpace(part) != "" {
+				return r, fmt.Errorf("invalid Plural-Forms part %q", strings.TrimSpace(part))
+			}
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "nplurals":
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("invalid nplurals %q", strings.TrimSpace(value))
+			}
+			r.NPlurals, hasNPlurals = n, true
+		case "plural":
+			r.Plural, hasPlural = strings.TrimSpace(value), true
+		default:
+			return r, fmt.Errorf("unknown Plural-Forms key %q", strings.TrimSpace(key))
+		}
+	}
+
+	if !hasNPlurals {
+		return r, errors.New("missing nplurals in Plural-Forms")
+	}
+	if !hasPlural {
+		return r, errors.New("missing plural in Plural-Forms")
+	}
+	return r, nil
+}
+
+// Compile compiles the plural expression of the rule.
+func (r Rule) Compile() (Expression, error) {
+	return Compile(r.Plural)
+}
+
+// Validate compiles the plural expression of the rule and checks it against its number of forms.
+func (r Rule) Validate() error {
+	expr, err := r.Compile()
+	if err != nil {
+		return err
+	}
+	return Validate(expr, r.NPlurals)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExpression_String(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"0", "0"},
+		{"(n != 1)", "n != 1"},
+		{"n%10==1&&n%100!=11?0:n!=0?1:2", "n % 10 == 1 && n % 100 != 11 ? 0 : n != 0 ? 1 : 2"},
+		{"(n==1)?0:(n>=2&&n<=4)?1:2", "n == 1 ? 0 : n >= 2 && n <= 4 ? 1 : 2"},
+		{"n%10>=2 && (n%100<10 || n%100>=20)", "n % 10 >= 2 && (n % 100 < 10 || n % 100 >= 20)"},
+		{"(n+1)*2", "(n + 1) * 2"},
+		{"n-(n-1)", "n - (n - 1)"},
+		{"(n-1)-1", "n - 1 - 1"},
+		{"!(n%10)", "!(n % 10)"},
+		{"(n ? 1 : 2) ? 3 : 4", "(n ? 1 : 2) ? 3 : 4"},
+		{"n ? (n ? 1 : 2) : 4", "n ? n ? 1 : 2 : 4"},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.in)
+		if err != nil {
+			t.Errorf("Compile(%q) failed: %v", tt.in, err)
+			continue
+		}
+		got := expr.String()
+		if got != tt.want {
+			t.Errorf("Compile(%q).String() = %q, want %q", tt.in, got, tt.want)
+		}
+
+		// Normalized expressions compile to the same expression
+		again, err := Compile(got)
+		if err != nil {
+			t.Errorf("Compile(%q) failed: %v", got, err)
+			continue
+		}
+		if again.String() != got {
+			t.Errorf("%q isn't stable, got %q", got, again.String())
+		}
+		if ok, n := Equivalent(expr, again); !ok {
+			t.Errorf("%q and %q differ for n = %d", tt.in, got, n)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for lang, rule := range languageRules {
+		if err := rule.Validate(); err != nil {
+			t.Errorf("%s: %v", lang, err)
+		}
+	}
+
+	expr, _ := Compile("n==1 ? 0 : n%10>=2 && n%10<=4 ? 1 : n==0 ? 3 : 2")
+	err := Validate(expr, 3)
+	var ferr *FormsError
+	if !errors.As(err, &ferr) {
+		t.Fatalf("Expected a *FormsError, got %v", err)
+	}
+	if n, ok := ferr.OutOfRange[3]; !ok || n != 0 {
+		t.Errorf("Expected form 3 for n = 0 to be out of range, got %v", ferr.OutOfRange)
+	}
+
+	expr, _ = Compile("n > 1000000 ? 2 : n != 1")
+	if err = Validate(expr, 4); !errors.As(err, &ferr) || len(ferr.Unused) != 1 || ferr.Unused[0] != 3 || len(ferr.OutOfRange) != 0 {
+		t.Errorf("Expected form 3 to be unused, got %v", err)
+	}
+	if err = Validate(expr, 3); err != nil {
+		t.Errorf("Expected forms produced for large numbers only to be found, got %v", err)
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	a, _ := Compile("n != 1")
+	b, _ := Compile("n == 1 ? 0 : 1")
+	if ok, _ := Equivalent(a, b); !ok {
+		t.Error("Expected n != 1 and n == 1 ? 0 : 1 to be equivalent")
+	}
+
+	c, _ := Compile("n > 1")
+	if ok, n := Equivalent(a, c); ok || n != 0 {
+		t.Errorf("Expected n != 1 and n > 1 to differ for 0, got %v, %d", ok, n)
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	r, err := ParseRule(" nplurals=3; plural=(n==1 ? 0 : n==2 ? 1 : 2);")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.NPlurals != 3 || r.Plural != "(n==1 ? 0 : n==2 ? 1 : 2)" {
+		t.Errorf("Unexpected rule %+v", r)
+	}
+
+	for _, in := range []string{"", "nplurals=2", "plural=n != 1", "nplurals=x; plural=0;", "nplurals=0; plural=0;", "nplurals=2; plural=n!=1; foo=1", "nplurals=2 plural=n"} {
+		if _, err := ParseRule(in); err == nil {
+			t.Errorf("ParseRule(%q) didn't fail", in)
+		}
+	}
+}
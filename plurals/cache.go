@@ -0,0 +1,36 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+import "sync"
+
+// maxCached bounds the compiled expressions kept, so catalogs with unusual
+// expressions can't grow the cache without limit. A process uses a few in practice.
+const maxCached = 1024
+
+// compiled holds the compiled expressions by source and normalized text
+var compiled = &expressionCache{exprs: make(map[string]*expression)}
+
+type expressionCache struct {
+	sync.RWMutex
+	exprs map[string]*expression
+}
+
+func (c *expressionCache) load(s string) (*expression, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	expr, ok := c.exprs[s]
+	return expr, ok
+}
+
+func (c *expressionCache) store(s string, expr *expression) {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.exprs) < maxCached {
+		c.exprs[s] = expr
+	}
+}
@@ -0,0 +1,437 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Category is a CLDR plural category.
+type Category int
+
+// CLDR plural categories, in CLDR order
+const (
+	Zero Category = iota
+	One
+	Two
+	Few
+	Many
+	Other
+)
+
+var categoryNames = [...]string{"zero", "one", "two", "few", "many", "other"}
+
+func (c Category) String() string {
+	if c < Zero || c > Other {
+		return "Category(" + strconv.Itoa(int(c)) + ")"
+	}
+	return categoryNames[c]
+}
+
+// ParseCategory returns the Category named s, as in "one" or "few".
+func ParseCategory(s string) (Category, error) {
+	for c, name := range categoryNames {
+		if s == name {
+			return Category(c), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown plural category %q", s)
+}
+
+/*
+Operands are the CLDR plural operands of a decimal number:
+
+	n  absolute value
+	i  integer digits
+	v  number of visible fraction digits, with trailing zeros
+	w  number of visible fraction digits, without trailing zeros
+	f  visible fraction digits, with trailing zeros
+	t  visible fraction digits, without trailing zeros
+	e  exponent of the compact decimal notation
+*/
+type Operands struct {
+	N    float64
+	I    uint64
+	V, W int
+	F, T uint64
+	E    int
+}
+
+// IntOperands returns the operands of the integer n.
+func IntOperands(n int64) Operands {
+	if n < 0 {
+		n = -n
+	}
+	return Operands{N: float64(n), I: uint64(n)}
+}
+
+// ParseOperands returns the operands of a number formatted as a decimal, as in "1", "-1.50" or "2,0".
+// Either a point or a comma is accepted as decimal separator, but no grouping separators.
+// A compact exponent may follow as in "1.2c6", meaning 1200000 with e = 6.
+func ParseOperands(s string) (Operands, error) {
+	var op Operands
+
+	num := strings.TrimLeft(strings.TrimSpace(s), "+-")
+	if i := strings.IndexAny(num, "cCeE"); i >= 0 {
+		exp, err := strconv.Atoi(num[i+1:])
+		if err != nil || exp < 0 {
+			return op, fmt.Errorf("invalid exponent in %q", s)
+		}
+		op.E = exp
+		num = num[:i]
+	}
+
+	intPart, fracPart, _ := strings.Cut(strings.Replace(num, ",", ".", 1), ".")
+	if intPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return op, fmt.Errorf("invalid decimal number %q", s)
+	}
+
+	// Move the decimal point by the exponent
+	for e := op.E; e > 0; e-- {
+		if fracPart == "" {
+			intPart += "0"
+		} else {
+			intPart += fracPart[:1]
+			fracPart = fracPart[1:]
+		}
+	}
+
+	var err error
+	if op.I, err = strconv.ParseUint(intPart, 10, 64); err != nil {
+		return op, fmt.Errorf("invalid decimal number %q: %v", s, err)
+	}
+	op.V = len(fracPart)
+	trimmed := strings.TrimRight(fracPart, "0")
+	op.W = len(trimmed)
+	if op.V > 0 {
+		if op.F, err = strconv.ParseUint(fracPart, 10, 64); err != nil {
+			return op, fmt.Errorf("invalid decimal number %q: %v", s, err)
+		}
+	}
+	if op.W > 0 {
+		op.T, _ = strconv.ParseUint(trimmed, 10, 64)
+	}
+	op.N, _ = strconv.ParseFloat(intPart+"."+fracPart+"0", 64)
+
+	return op, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// CLDRRules are the CLDR plural rules of a language, choosing the Category of a number.
+type CLDRRules struct {
+	rules []cldrRule
+
+	// Smallest integer of each category, if any
+	samples map[Category]int64
+
+	// Position of each category in Categories, -1 for the ones not used
+	index [Other + 1]int
+}
+
+type cldrRule struct {
+	category  Category
+	condition [][]cldrRelation // or of ands
+}
+
+type cldrRelation struct {
+	operand byte
+	mod     float64
+	negate  bool
+	ranges  [][2]float64
+}
+
+//go:embed cldr.txt
+var cldrData string
+
+var cardinalRules, ordinalRules = loadCLDRData(cldrData)
+
+// englishCardinal is used for languages without known rules
+var englishCardinal = cardinalRules["en"]
+
+// CardinalForLanguage returns the CLDR cardinal plural rules for a language tag such as "pl" or "pt_BR".
+// Tags with a region not found use the rules of the language alone.
+func CardinalForLanguage(tag string) (*CLDRRules, bool) {
+	return lookupLanguage(cardinalRules, tag, true)
+}
+
+// OrdinalForLanguage returns the CLDR ordinal plural rules for a language tag such as "en" or "fr_CA",
+// used to choose between forms like "1st", "2nd", "3rd" and "4th".
+// Tags with a region not found use the rules of the language alone.
+func OrdinalForLanguage(tag string) (*CLDRRules, bool) {
+	return lookupLanguage(ordinalRules, tag, false)
+}
+
+// EnglishCardinal returns the CLDR cardinal plural rules of English.
+func EnglishCardinal() *CLDRRules {
+	return englishCardinal
+}
+
+// ParseCLDRRules parses plural rules in the syntax of CLDR plurals.xml, one "category: condition"
+// per rule separated by semicolons, as in "one: i = 1 and v = 0; few: v = 0 and i % 10 = 2..4".
+// The "other" category is implied.
+func ParseCLDRRules(s string) (*CLDRRules, error) {
+	r := &CLDRRules{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, cond, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("missing category in plural rule %q", part)
+		}
+		cat, err := ParseCategory(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		rule := cldrRule{category: cat}
+		if rule.condition, err = parseCLDRCondition(cond); err != nil {
+			return nil, fmt.Errorf("plural rule %q: %v", part, err)
+		}
+		r.rules = append(r.rules, rule)
+	}
+
+	for c := range r.index {
+		r.index[c] = -1
+	}
+	for i, c := range r.Categories() {
+		r.index[c] = i
+	}
+
+	r.samples = make(map[Category]int64)
+	for n := int64(0); n <= 1000; n++ {
+		cat := r.Category(IntOperands(n))
+		if _, ok := r.samples[cat]; !ok {
+			r.samples[cat] = n
+		}
+	}
+
+	return r, nil
+}
+
+// Category returns the category of the number with operands op.
+func (r *CLDRRules) Category(op Operands) Category {
+	for _, rule := range r.rules {
+		if rule.matches(op) {
+			return rule.category
+		}
+	}
+	return Other
+}
+
+// Categories returns the categories the rules use, in CLDR order, ending with Other.
+func (r *CLDRRules) Categories() []Category {
+	var cats []Category
+	for c := Zero; c < Other; c++ {
+		for _, rule := range r.rules {
+			if rule.category == c {
+				cats = append(cats, c)
+				break
+			}
+		}
+	}
+	return append(cats, Other)
+}
+
+// Index returns the position of c in Categories, or the position of Other if the rules don't use c.
+// It's the msgstr index of c in catalogs with one form per category.
+func (r *CLDRRules) Index(c Category) int {
+	if c < Zero || c > Other || r.index[c] < 0 {
+		return r.index[Other]
+	}
+	return r.index[c]
+}
+
+// IntegerSample returns the smallest integer up to 1000 in category c.
+// It returns false for categories only used by decimal numbers.
+func (r *CLDRRules) IntegerSample(c Category) (int64, bool) {
+	n, ok := r.samples[c]
+	return n, ok
+}
+
+func (rule cldrRule) matches(op Operands) bool {
+	for _, and := range rule.condition {
+		matched := true
+		for _, rel := range and {
+			if !rel.matches(op) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (rel cldrRelation) matches(op Operands) bool {
+	var x float64
+	switch rel.operand {
+	case 'n':
+		x = op.N
+	case 'i':
+		x = float64(op.I)
+	case 'v':
+		x = float64(op.V)
+	case 'w':
+		x = float64(op.W)
+	case 'f':
+		x = float64(op.F)
+	case 't':
+		x = float64(op.T)
+	case 'e', 'c':
+		x = float64(op.E)
+	}
+	if rel.mod != 0 {
+		x = math.Mod(x, rel.mod)
+	}
+
+	in := false
+	for _, r := range rel.ranges {
+		if r[0] == r[1] {
+			in = x == r[0]
+		} else {
+			// Ranges only contain integers
+			in = x == math.Trunc(x) && x >= r[0] && x <= r[1]
+		}
+		if in {
+			break
+		}
+	}
+	return in != rel.negate
+}
+
+// parseCLDRCondition parses a condition using the = and != operators, as CLDR does since version 24
+func parseCLDRCondition(s string) ([][]cldrRelation, error) {
+	s = strings.Join(strings.Fields(s), " ")
+
+	var or [][]cldrRelation
+	for _, andPart := range strings.Split(s, " or ") {
+		var and []cldrRelation
+		for _, relPart := range strings.Split(andPart, " and ") {
+			rel, err := parseCLDRRelation(strings.TrimSpace(relPart))
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, rel)
+		}
+		or = append(or, and)
+	}
+	return or, nil
+}
+
+func parseCLDRRelation(s string) (cldrRelation, error) {
+	var rel cldrRelation
+
+	expr, list, ok := strings.Cut(s, "=")
+	if !ok {
+		return rel, fmt.Errorf("missing '=' in relation %q", s)
+	}
+	if strings.HasSuffix(expr, "!") {
+		rel.negate = true
+		expr = expr[:len(expr)-1]
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 1 && (len(fields) != 3 || (fields[1] != "%" && fields[1] != "mod")) {
+		return rel, fmt.Errorf("invalid expression %q", expr)
+	}
+	if len(fields[0]) != 1 || !strings.Contains("niwvftec", fields[0]) {
+		return rel, fmt.Errorf("unknown operand %q", fields[0])
+	}
+	rel.operand = fields[0][0]
+	if len(fields) == 3 {
+		mod, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil || mod == 0 {
+			return rel, fmt.Errorf("invalid modulus %q", fields[2])
+		}
+		rel.mod = float64(mod)
+	}
+
+	for _, item := range strings.Split(list, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(item), "..")
+		a, err := strconv.ParseUint(from, 10, 64)
+		if err != nil {
+			return rel, fmt.Errorf("invalid value %q", item)
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.ParseUint(to, 10, 64); err != nil || b < a {
+				return rel, fmt.Errorf("invalid range %q", item)
+			}
+		}
+		rel.ranges = append(rel.ranges, [2]float64{float64(a), float64(b)})
+	}
+
+	return rel, nil
+}
+
+// loadCLDRData parses the embedded rules, which must be valid
+func loadCLDRData(data string) (cardinal, ordinal map[string]*CLDRRules) {
+	cardinal = make(map[string]*CLDRRules)
+	ordinal = make(map[string]*CLDRRules)
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		kind, rest, _ := strings.Cut(line, " ")
+		langs, rules, _ := strings.Cut(rest, ":")
+		r, err := ParseCLDRRules(rules)
+		if err != nil {
+			panic("plurals: invalid embedded CLDR data: " + err.Error())
+		}
+
+		table := cardinal
+		if kind == "ordinal" {
+			table = ordinal
+		}
+		for _, lang := range strings.Split(langs, ",") {
+			table[strings.TrimSpace(lang)] = r
+		}
+	}
+
+	return cardinal, ordinal
+}
+
+// noRules is used for languages with a single category
+var noRules, _ = ParseCLDRRules("")
+
+// lookupLanguage finds the rules for tag in table. Languages of the built-in plural table without
+// rules only use the "other" category, but for cardinal rules only the ones with a single form do:
+// the others aren't found, so their Plural-Forms is used instead.
+func lookupLanguage(table map[string]*CLDRRules, tag string, cardinal bool) (*CLDRRules, bool) {
+	lang, region, ok := normalizeTag(tag)
+	if !ok {
+		return nil, false
+	}
+	if region != "" {
+		if r, ok := table[lang+"_"+region]; ok {
+			return r, true
+		}
+	}
+	if r, ok := table[lang]; ok {
+		return r, true
+	}
+	if r, ok := languageRules[lang]; ok && (!cardinal || r.NPlurals == 1) {
+		return noRules, true
+	}
+	return nil, false
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+import "testing"
+
+func TestParseOperands(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Operands
+	}{
+		{"1", Operands{N: 1, I: 1}},
+		{"-1", Operands{N: 1, I: 1}},
+		{"1.0", Operands{N: 1, I: 1, V: 1}},
+		{"2,0", Operands{N: 2, I: 2, V: 1}},
+		{"1.50", Operands{N: 1.5, I: 1, V: 2, W: 1, F: 50, T: 5}},
+		{"0.03", Operands{N: 0.03, I: 0, V: 2, W: 2, F: 3, T: 3}},
+		{"1.2c6", Operands{N: 1200000, I: 1200000, E: 6}},
+		{"1.25e1", Operands{N: 12.5, I: 12, V: 1, W: 1, F: 5, T: 5, E: 1}},
+	}
+	for _, tt := range tests {
+		got, err := ParseOperands(tt.in)
+		if err != nil {
+			t.Errorf("ParseOperands(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseOperands(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", ".5", "1.2.3", "1,000.5", "abc", "1e"} {
+		if _, err := ParseOperands(in); err == nil {
+			t.Errorf("ParseOperands(%q) didn't fail", in)
+		}
+	}
+}
+
+func TestCardinalForLanguage(t *testing.T) {
+	tests := []struct {
+		lang string
+		num  string
+		want Category
+	}{
+		{"en", "1", One},
+		{"en", "1.0", Other},
+		{"en", "1.5", Other},
+		{"de_DE", "2,0", Other},
+		{"fr", "1.5", One},
+		{"fr", "0", One},
+		{"fr", "1000000", Many},
+		{"fr", "1.2c6", Many},
+		{"pt_BR", "0", One},
+		{"pt_PT", "0", Other},
+		{"pl", "1", One},
+		{"pl", "22", Few},
+		{"pl", "12", Many},
+		{"pl", "1.5", Other},
+		{"ru", "21", One},
+		{"ru", "1.5", Other},
+		{"cs", "1.5", Many},
+		{"lt", "0.1", Many},
+		{"lv", "0.1", One},
+		{"ar", "102", Other},
+		{"ar", "111", Many},
+		{"hr", "0.1", One},
+		{"ja", "1", Other},
+		{"he", "1", One},
+		{"he", "2", Two},
+		{"he", "0.5", One},
+		{"mk", "11", Other},
+		{"mk", "21", One},
+		{"fa", "0", One},
+		{"hy", "1.5", One},
+	}
+	for _, tt := range tests {
+		r, ok := CardinalForLanguage(tt.lang)
+		if !ok {
+			t.Errorf("No rules for %s", tt.lang)
+			continue
+		}
+		op, err := ParseOperands(tt.num)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Category(op); got != tt.want {
+			t.Errorf("%s: category of %s is %s, want %s", tt.lang, tt.num, got, tt.want)
+		}
+	}
+
+	if _, ok := CardinalForLanguage("xx"); ok {
+		t.Error("Expected no rules for xx")
+	}
+}
+
+func TestCardinalForLanguage_PluralTable(t *testing.T) {
+	// Languages with several forms need their rules, or every number would be "other"
+	for lang, rule := range languageRules {
+		if rule.NPlurals == 1 {
+			continue
+		}
+		if r, ok := CardinalForLanguage(lang); !ok || len(r.Categories()) < 2 {
+			t.Errorf("No cardinal rules for %s", lang)
+		}
+	}
+}
+
+func TestCLDRRules_Samples(t *testing.T) {
+	r, _ := CardinalForLanguage("pl")
+
+	cats := r.Categories()
+	if len(cats) != 4 || cats[0] != One || cats[1] != Few || cats[2] != Many || cats[3] != Other {
+		t.Errorf("Unexpected categories %v", cats)
+	}
+	for cat, want := range map[Category]int64{One: 1, Few: 2, Many: 0} {
+		if n, ok := r.IntegerSample(cat); !ok || n != want {
+			t.Errorf("IntegerSample(%s) = %d, %v, want %d", cat, n, ok, want)
+		}
+	}
+	if _, ok := r.IntegerSample(Other); ok {
+		t.Error("Polish integers are never in the other category")
+	}
+}
+
+func TestParseCLDRRules(t *testing.T) {
+	r, err := ParseCLDRRules("one:  i = 1  and v = 0 ; few: n % 10 = 2..4, 9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for num, want := range map[string]Category{"1": One, "1.0": Other, "9": Few, "13": Few, "2.5": Other} {
+		op, _ := ParseOperands(num)
+		if got := r.Category(op); got != want {
+			t.Errorf("category of %s is %s, want %s", num, got, want)
+		}
+	}
+
+	for _, in := range []string{"one", "single: n = 1", "one: x = 1", "one: n = a", "one: n % 0 = 1", "one: n > 1", "one: n = 3..1"} {
+		if _, err := ParseCLDRRules(in); err == nil {
+			t.Errorf("ParseCLDRRules(%q) didn't fail", in)
+		}
+	}
+}
+
+func TestOrdinalForLanguage(t *testing.T) {
+	tests := []struct {
+		lang  string
+		n     int64
+		want  Category
+		index int
+	}{
+		{"en", 1, One, 0},
+		{"en", 2, Two, 1},
+		{"en", 3, Few, 2},
+		{"en", 4, Other, 3},
+		{"en", 11, Other, 3},
+		{"en", 22, Two, 1},
+		{"en_GB", 103, Few, 2},
+		{"fr", 1, One, 0},
+		{"fr", 2, Other, 1},
+		{"sv", 2, One, 0},
+		{"it", 8, Many, 0},
+		{"it", 9, Other, 1},
+		{"de", 1, Other, 0},
+	}
+	for _, tt := range tests {
+		r, ok := OrdinalForLanguage(tt.lang)
+		if !ok {
+			t.Errorf("No ordinal rules for %s", tt.lang)
+			continue
+		}
+		cat := r.Category(IntOperands(tt.n))
+		if cat != tt.want {
+			t.Errorf("%s: ordinal category of %d is %s, want %s", tt.lang, tt.n, cat, tt.want)
+		}
+		if idx := r.Index(cat); idx != tt.index {
+			t.Errorf("%s: index of %s is %d, want %d", tt.lang, cat, idx, tt.index)
+		}
+	}
+
+	r, _ := OrdinalForLanguage("en")
+	if idx := r.Index(Many); idx != 3 {
+		t.Errorf("Expected categories not used to have the index of other, got %d", idx)
+	}
+}
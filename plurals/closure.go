@@ -0,0 +1,278 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+// Compiled expressions are evaluated with closures instead of walking the tree. The shapes
+// found in real plural rules, like n % 100 >= 11 && n % 100 <= 14 or chains of ternaries
+// returning constants, get a single closure each.
+
+// valueFunc evaluates a node to a number
+type valueFunc func(n uint64) uint64
+
+// condFunc evaluates a node used as a condition
+type condFunc func(n uint64) bool
+
+// ternaryCase is a condition and the constant returned when it holds
+type ternaryCase struct {
+	cond  condFunc
+	value uint64
+}
+
+func compileValue(nd node) valueFunc {
+	switch x := nd.(type) {
+	case constValue:
+		v := x.value
+		return func(uint64) uint64 { return v }
+
+	case variable:
+		return func(n uint64) uint64 { return n }
+
+	case ternary:
+		return compileTernary(x)
+
+	case add:
+		l, r := compileValue(x.left), compileValue(x.right)
+		return func(n uint64) uint64 { return l(n) + r(n) }
+
+	case sub:
+		l, r := compileValue(x.left), compileValue(x.right)
+		return func(n uint64) uint64 { return l(n) - r(n) }
+
+	case mul:
+		l, r := compileValue(x.left), compileValue(x.right)
+		return func(n uint64) uint64 { return l(n) * r(n) }
+
+	case div:
+		l, r := compileValue(x.left), compileValue(x.right)
+		return func(n uint64) uint64 {
+			d := r(n)
+			if d == 0 {
+				return 0
+			}
+			return l(n) / d
+		}
+
+	case mod:
+		// The parser rejects literal zero divisors
+		if m, ok := modulus(x); ok {
+			return func(n uint64) uint64 { return n % m }
+		}
+		l, r := compileValue(x.left), compileValue(x.right)
+		return func(n uint64) uint64 {
+			d := r(n)
+			if d == 0 {
+				return 0
+			}
+			return l(n) % d
+		}
+	}
+
+	// Comparisons and logical operators
+	cond := compileCond(nd)
+	return func(n uint64) uint64 {
+		if cond(n) {
+			return 1
+		}
+		return 0
+	}
+}
+
+// compileTernary compiles c1 ? v1 : c2 ? v2 : ... : v, looping over the conditions when all values are constants
+func compileTernary(t ternary) valueFunc {
+	var cases []ternaryCase
+	var last node = t
+	for {
+		x, ok := last.(ternary)
+		if !ok {
+			break
+		}
+		yes, ok := x.yes.(constValue)
+		if !ok {
+			break
+		}
+		cases = append(cases, ternaryCase{cond: compileCond(x.cond), value: yes.value})
+		last = x.no
+	}
+
+	if otherwise, ok := last.(constValue); ok && len(cases) > 0 {
+		v := otherwise.value
+		if len(cases) == 1 {
+			cond, yes := cases[0].cond, cases[0].value
+			return func(n uint64) uint64 {
+				if cond(n) {
+					return yes
+				}
+				return v
+			}
+		}
+		return func(n uint64) uint64 {
+			for _, c := range cases {
+				if c.cond(n) {
+					return c.value
+				}
+			}
+			return v
+		}
+	}
+
+	cond, yes, no := compileCond(t.cond), compileValue(t.yes), compileValue(t.no)
+	return func(n uint64) uint64 {
+		if cond(n) {
+			return yes(n)
+		}
+		return no(n)
+	}
+}
+
+func compileCond(nd node) condFunc {
+	switch x := nd.(type) {
+	case constValue:
+		b := x.value != 0
+		return func(uint64) bool { return b }
+
+	case variable:
+		return func(n uint64) bool { return n != 0 }
+
+	case not:
+		c := compileCond(x.operand)
+		return func(n uint64) bool { return !c(n) }
+
+	case and:
+		if c := compileRange(x); c != nil {
+			return c
+		}
+		l, r := compileCond(x.left), compileCond(x.right)
+		return func(n uint64) bool { return l(n) && r(n) }
+
+	case or:
+		l, r := compileCond(x.left), compileCond(x.right)
+		return func(n uint64) bool { return l(n) || r(n) }
+
+	case equal, notequal, lt, lte, gt, gte:
+		op, left, right := binaryParts(nd)
+		return compileComparison(op, left, right)
+	}
+
+	v := compileValue(nd)
+	return func(n uint64) bool { return v(n) != 0 }
+}
+
+// compileComparison specializes comparisons of n or n % m with a constant
+func compileComparison(op string, left, right node) condFunc {
+	c, ok := right.(constValue)
+	if !ok {
+		return compileCompare(op, compileValue(left), compileValue(right))
+	}
+	v := c.value
+
+	if _, ok := left.(variable); ok {
+		switch op {
+		case "==":
+			return func(n uint64) bool { return n == v }
+		case "!=":
+			return func(n uint64) bool { return n != v }
+		case "<":
+			return func(n uint64) bool { return n < v }
+		case "<=":
+			return func(n uint64) bool { return n <= v }
+		case ">":
+			return func(n uint64) bool { return n > v }
+		case ">=":
+			return func(n uint64) bool { return n >= v }
+		}
+	}
+
+	if x, ok := left.(mod); ok {
+		if m, ok := modulus(x); ok {
+			switch op {
+			case "==":
+				return func(n uint64) bool { return n%m == v }
+			case "!=":
+				return func(n uint64) bool { return n%m != v }
+			case "<":
+				return func(n uint64) bool { return n%m < v }
+			case "<=":
+				return func(n uint64) bool { return n%m <= v }
+			case ">":
+				return func(n uint64) bool { return n%m > v }
+			case ">=":
+				return func(n uint64) bool { return n%m >= v }
+			}
+		}
+	}
+
+	return compileCompare(op, compileValue(left), compileValue(right))
+}
+
+// compileRange specializes x >= lo && x <= hi where x is n or n % m, or nil if a isn't one
+func compileRange(a and) condFunc {
+	lower, ok := a.left.(gte)
+	if !ok {
+		return nil
+	}
+	upper, ok := a.right.(lte)
+	if !ok {
+		return nil
+	}
+	lo, ok := lower.right.(constValue)
+	if !ok {
+		return nil
+	}
+	hi, ok := upper.right.(constValue)
+	if !ok || hi.value < lo.value {
+		return nil
+	}
+	// x >= lo && x <= hi is x-lo <= hi-lo in unsigned arithmetic
+	base, width := lo.value, hi.value-lo.value
+
+	if _, ok := lower.left.(variable); ok {
+		if _, ok := upper.left.(variable); ok {
+			return func(n uint64) bool { return n-base <= width }
+		}
+	}
+
+	lm, ok := lower.left.(mod)
+	if !ok {
+		return nil
+	}
+	um, ok := upper.left.(mod)
+	if !ok {
+		return nil
+	}
+	m, ok := modulus(lm)
+	if m2, ok2 := modulus(um); !ok || !ok2 || m != m2 {
+		return nil
+	}
+	return func(n uint64) bool { return n%m-base <= width }
+}
+
+// modulus returns m if x is n % m with a constant m
+func modulus(x mod) (uint64, bool) {
+	if _, ok := x.left.(variable); !ok {
+		return 0, false
+	}
+	c, ok := x.right.(constValue)
+	if !ok || c.value == 0 {
+		return 0, false
+	}
+	return c.value, true
+}
+
+// compileCompare compiles the comparison of any two values
+func compileCompare(op string, l, r valueFunc) condFunc {
+	switch op {
+	case "==":
+		return func(n uint64) bool { return l(n) == r(n) }
+	case "!=":
+		return func(n uint64) bool { return l(n) != r(n) }
+	case "<":
+		return func(n uint64) bool { return l(n) < r(n) }
+	case "<=":
+		return func(n uint64) bool { return l(n) <= r(n) }
+	case ">":
+		return func(n uint64) bool { return l(n) > r(n) }
+	}
+	return func(n uint64) bool { return l(n) >= r(n) }
+}
@@ -9,450 +9,201 @@ Package plurals is the pluralform compiler to get the correct translation id of
 */
 package plurals
 
-import (
-	"errors"
-	"fmt"
-	"regexp"
-	"strconv"
-	"strings"
-)
-
-type match struct {
-	openPos  int
-	closePos int
+import "fmt"
+
+// Binary operators by precedence level, from lowest to highest, as in GNU gettext plural.y
+var binaryPrecedence = map[tokenKind]int{
+	tokOr:  1,
+	tokAnd: 2,
+	tokEq:  3,
+	tokNeq: 3,
+	tokLt:  4,
+	tokLte: 4,
+	tokGt:  4,
+	tokGte: 4,
+	tokAdd: 5,
+	tokSub: 5,
+	tokMul: 6,
+	tokDiv: 6,
+	tokMod: 6,
+}
+
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
 }
 
-var pat = regexp.MustCompile(`(\?|:|\|\||&&|==|!=|>=|>|<=|<|%|\d+|n|\(|\))`)
-
-type testToken interface {
-	compile(tokens []string) (test test, err error)
-}
-
-type cmpTestBuilder func(val uint32, flipped bool) test
-type logicTestBuild func(left test, right test) test
-
-var ternaryToken ternaryStruct
-
-type ternaryStruct struct{}
-
-func (ternaryStruct) compile(tokens []string) (expr Expression, err error) {
-	main, err := splitTokens(tokens, "?")
-	if err != nil {
-		return expr, err
-	}
-	test, err := compileTest(strings.Join(main.Left, ""))
-	if err != nil {
-		return expr, err
-	}
-	actions, err := splitTokens(main.Right, ":")
-	if err != nil {
-		return expr, err
-	}
-	trueAction, err := compileExpression(strings.Join(actions.Left, ""))
-	if err != nil {
-		return expr, err
-	}
-	falseAction, err := compileExpression(strings.Join(actions.Right, ""))
-	if err != nil {
+// Compile a string containing a plural form expression to a Expression object.
+// It accepts the C subset of the GNU gettext plural grammar: the variable n, unsigned integers,
+// the operators ! * / % + - < <= > >= == != && || and ?:, and parentheses.
+// Malformed expressions return a *SyntaxError.
+//
+// Compiled expressions are shared by the whole process: compiling an expression that was
+// already compiled, as written or in any form with the same normalized text, returns the same
+// Expression, which is safe for concurrent use.
+func Compile(s string) (Expression, error) {
+	if expr, ok := compiled.load(s); ok {
 		return expr, nil
 	}
-	return ternary{
-		test:      test,
-		trueExpr:  trueAction,
-		falseExpr: falseAction,
-	}, nil
-}
-
-var constToken constValStruct
-
-type constValStruct struct{}
 
-func (constValStruct) compile(tokens []string) (expr Expression, err error) {
-	if len(tokens) == 0 {
-		return expr, errors.New("got nothing instead of constant")
-	}
-	if len(tokens) != 1 {
-		return expr, fmt.Errorf("invalid constant: %s", strings.Join(tokens, ""))
-	}
-	i, err := strconv.Atoi(tokens[0])
+	tokens, err := lex(s)
 	if err != nil {
-		return expr, err
+		return nil, err
 	}
-	return constValue{value: i}, nil
-}
 
-func compileLogicTest(tokens []string, sep string, builder logicTestBuild) (test test, err error) {
-	split, err := splitTokens(tokens, sep)
+	p := &parser{src: s, tokens: tokens}
+	root, err := p.parseExpression()
 	if err != nil {
-		return test, err
-	}
-	left, err := compileTest(strings.Join(split.Left, ""))
-	if err != nil {
-		return test, err
+		return nil, err
 	}
-	right, err := compileTest(strings.Join(split.Right, ""))
-	if err != nil {
-		return test, err
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
 	}
-	return builder(left, right), nil
-}
-
-var orToken orStruct
 
-type orStruct struct{}
+	expr := &expression{root: root}
+	normalized := expr.String()
+	if shared, ok := compiled.load(normalized); ok {
+		compiled.store(s, shared)
+		return shared, nil
+	}
 
-func (orStruct) compile(tokens []string) (test test, err error) {
-	return compileLogicTest(tokens, "||", buildOr)
-}
-func buildOr(left test, right test) test {
-	return or{left: left, right: right}
+	switch root.(type) {
+	case constValue, variable:
+	default:
+		expr.eval = compileValue(root)
+	}
+	compiled.store(normalized, expr)
+	compiled.store(s, expr)
+	return expr, nil
 }
 
-var andToken andStruct
-
-type andStruct struct{}
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
 
-func (andStruct) compile(tokens []string) (test test, err error) {
-	return compileLogicTest(tokens, "&&", buildAnd)
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
 }
-func buildAnd(left test, right test) test {
-	return and{left: left, right: right}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &SyntaxError{Expr: p.src, Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
 }
 
-func compileMod(tokens []string) (math math, err error) {
-	split, err := splitTokens(tokens, "%")
+// parseExpression parses a conditional expression, which is right associative
+func (p *parser) parseExpression() (node, error) {
+	cond, err := p.parseBinary(1)
 	if err != nil {
-		return math, err
-	}
-	if len(split.Left) != 1 || split.Left[0] != "n" {
-		return math, errors.New("modulus operation requires 'n' as left operand")
-	}
-	if len(split.Right) != 1 {
-		return math, errors.New("modulus operation requires simple integer as right operand")
+		return nil, err
 	}
-	i, err := parseUint32(split.Right[0])
-	if err != nil {
-		return math, err
+	if p.peek().kind != tokQuestion {
+		return cond, nil
 	}
-	return mod{value: uint32(i)}, nil
-}
+	p.next()
 
-func subPipe(modTokens []string, actionTokens []string, builder cmpTestBuilder, flipped bool) (test test, err error) {
-	modifier, err := compileMod(modTokens)
+	yes, err := p.parseExpression()
 	if err != nil {
-		return test, err
-	}
-	if len(actionTokens) != 1 {
-		return test, errors.New("can only get modulus of integer")
+		return nil, err
 	}
-	i, err := parseUint32(actionTokens[0])
-	if err != nil {
-		return test, err
+	if tok := p.next(); tok.kind != tokColon {
+		return nil, p.errorf(tok, "expected ':' but found %s", tok)
 	}
-	action := builder(uint32(i), flipped)
-	return pipe{
-		modifier: modifier,
-		action:   action,
-	}, nil
-}
-
-func compileEquality(tokens []string, sep string, builder cmpTestBuilder) (test test, err error) {
-	split, err := splitTokens(tokens, sep)
+	no, err := p.parseExpression()
 	if err != nil {
-		return test, err
-	}
-	if contains(split.Left, "n") && !contains(split.Left, "%") && len(split.Left) == 1 {
-		i, err := parseUint32(strings.Join(split.Right, ""))
-		if err != nil {
-			// Try to compile it as a full expression if it's not a simple integer
-			// but for now we follow the existing pattern of expecting integers here
-			// unless it's a mod operation.
-			return test, err
-		}
-		return builder(i, false), nil
-	} else if contains(split.Right, "n") && !contains(split.Right, "%") && len(split.Right) == 1 {
-		i, err := parseUint32(strings.Join(split.Left, ""))
-		if err != nil {
-			return test, err
-		}
-		return builder(i, true), nil
-	} else if contains(split.Left, "n") && contains(split.Left, "%") {
-		return subPipe(split.Left, split.Right, builder, false)
-	} else if contains(split.Right, "n") && contains(split.Right, "%") {
-		return subPipe(split.Right, split.Left, builder, true)
+		return nil, err
 	}
 
-	return test, errors.New("equality test must have 'n' as one of the two tests")
-}
-
-var eqToken eqStruct
-
-type eqStruct struct{}
-
-func (eqStruct) compile(tokens []string) (test test, err error) {
-	return compileEquality(tokens, "==", buildEq)
-}
-func buildEq(val uint32, flipped bool) test {
-	return equal{value: val}
-}
-
-var neqToken neqStruct
-
-type neqStruct struct{}
-
-func (neqStruct) compile(tokens []string) (test test, err error) {
-	return compileEquality(tokens, "!=", buildNeq)
-}
-func buildNeq(val uint32, flipped bool) test {
-	return notequal{value: val}
-}
-
-var gtToken gtStruct
-
-type gtStruct struct{}
-
-func (gtStruct) compile(tokens []string) (test test, err error) {
-	return compileEquality(tokens, ">", buildGt)
-}
-func buildGt(val uint32, flipped bool) test {
-	return gt{value: val, flipped: flipped}
+	return ternary{cond: cond, yes: yes, no: no}, nil
 }
 
-var gteToken gteStruct
-
-type gteStruct struct{}
-
-func (gteStruct) compile(tokens []string) (test test, err error) {
-	return compileEquality(tokens, ">=", buildGte)
-}
-func buildGte(val uint32, flipped bool) test {
-	return gte{value: val, flipped: flipped}
-}
-
-var ltToken ltStruct
-
-type ltStruct struct{}
-
-func (ltStruct) compile(tokens []string) (test test, err error) {
-	return compileEquality(tokens, "<", buildLt)
-}
-func buildLt(val uint32, flipped bool) test {
-	return lt{value: val, flipped: flipped}
-}
-
-var lteToken lteStruct
-
-type lteStruct struct{}
-
-func (lteStruct) compile(tokens []string) (test test, err error) {
-	return compileEquality(tokens, "<=", buildLte)
-}
-func buildLte(val uint32, flipped bool) test {
-	return lte{value: val, flipped: flipped}
-}
-
-type testTokenDef struct {
-	op    string
-	token testToken
-}
-
-var precedence = []testTokenDef{
-	{op: "||", token: orToken},
-	{op: "&&", token: andToken},
-	{op: "==", token: eqToken},
-	{op: "!=", token: neqToken},
-	{op: ">=", token: gteToken},
-	{op: ">", token: gtToken},
-	{op: "<=", token: lteToken},
-	{op: "<", token: ltToken},
-}
-
-type splitted struct {
-	Left  []string
-	Right []string
-}
-
-// Find index of token in list of tokens
-func index(tokens []string, sep string) int {
-	for index, token := range tokens {
-		if token == sep {
-			return index
-		}
-	}
-	return -1
-}
-
-// Split a list of tokens by a token into a splitted struct holding the tokens
-// before and after the token to be split by.
-func splitTokens(tokens []string, sep string) (s splitted, err error) {
-	index := index(tokens, sep)
-	if index == -1 {
-		return s, fmt.Errorf("'%s' not found in ['%s']", sep, strings.Join(tokens, "','"))
+// parseBinary parses binary operations of at least minPrec precedence by precedence climbing.
+// All binary operators are left associative.
+func (p *parser) parseBinary(minPrec int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
 	}
-	return splitted{
-		Left:  tokens[:index],
-		Right: tokens[index+1:],
-	}, nil
-}
-
-// Scan a string for parenthesis
-func scan(s string) <-chan match {
-	ch := make(chan match)
-	go func() {
-		depth := 0
-		opener := 0
-		for index, char := range s {
-			switch char {
-			case '(':
-				if depth == 0 {
-					opener = index
-				}
-				depth++
-			case ')':
-				depth--
-				if depth == 0 {
-					ch <- match{
-						openPos:  opener,
-						closePos: index + 1,
-					}
-				}
-			}
 
+	for {
+		op := p.peek()
+		prec, ok := binaryPrecedence[op.kind]
+		if !ok || prec < minPrec {
+			return left, nil
 		}
-		close(ch)
-	}()
-	return ch
-}
+		p.next()
 
-// Split the string into tokens
-func split(s string) <-chan string {
-	ch := make(chan string)
-	go func() {
-		s = strings.ReplaceAll(s, " ", "")
-		if !strings.Contains(s, "(") {
-			ch <- s
-		} else {
-			last := 0
-			end := len(s)
-			for info := range scan(s) {
-				if last != info.openPos {
-					ch <- s[last:info.openPos]
-				}
-				ch <- s[info.openPos:info.closePos]
-				last = info.closePos
-			}
-			if last != end {
-				ch <- s[last:]
-			}
+		right, err := p.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
 		}
-		close(ch)
-	}()
-	return ch
-}
-
-// Tokenizes a string into a list of strings, tokens grouped by parenthesis are
-// not split! If the string starts with ( and ends in ), those are stripped.
-func tokenize(s string) ([]string, error) {
-	/*
-		TODO: Properly detect if the string starts with a ( and ends with a )
-		and that those two form a matching pair.
-
-		Eg: (foo) -> true; (foo)(bar) -> false;
-	*/
-	if len(s) == 0 {
-		return []string{}, nil
-	}
-	if s[0] == '(' && s[len(s)-1] == ')' {
-		s = s[1 : len(s)-1]
-	}
-	ret := []string{}
-	for chunk := range split(s) {
-		if len(chunk) != 0 {
-			if chunk[0] == '(' && chunk[len(chunk)-1] == ')' {
-				ret = append(ret, chunk)
-			} else {
-				// Verify all characters in chunk are matched by our pattern
-				matches := pat.FindAllStringSubmatch(chunk, -1)
-				matchedLen := 0
-				for _, token := range matches {
-					ret = append(ret, token[0])
-					matchedLen += len(token[0])
-				}
-				if matchedLen != len(chunk) {
-					return nil, fmt.Errorf("invalid character in expression chunk: %s", chunk)
-				}
-			}
-		} else {
-			fmt.Printf("Empty chunk in string '%s'\n", s)
+		if c, ok := right.(constValue); ok && c.value == 0 && (op.kind == tokDiv || op.kind == tokMod) {
+			return nil, p.errorf(op, "division by zero")
 		}
+		left = newBinary(op.kind, left, right)
 	}
-	return ret, nil
 }
 
-// Compile a string containing a plural form expression to a Expression object.
-func Compile(s string) (expr Expression, err error) {
-	if s == "0" {
-		return constValue{value: 0}, nil
-	}
-	if !strings.Contains(s, "?") {
-		s += "?1:0"
-	}
-	return compileExpression(s)
-}
+func (p *parser) parseUnary() (node, error) {
+	tok := p.next()
 
-// Check if a token is in a slice of strings
-func contains(haystack []string, needle string) bool {
-	for _, s := range haystack {
-		if s == needle {
-			return true
+	switch tok.kind {
+	case tokNot:
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
 		}
-	}
-	return false
-}
+		return not{operand: operand}, nil
 
-// Compiles an expression (ternary or constant)
-func compileExpression(s string) (expr Expression, err error) {
-	tokens, err := tokenize(s)
-	if err != nil {
-		return nil, err
-	}
-	if contains(tokens, "?") {
-		return ternaryToken.compile(tokens)
-	}
-	return constToken.compile(tokens)
-}
+	case tokNumber:
+		return constValue{value: tok.value}, nil
 
-// Compiles a test (comparison)
-func compileTest(s string) (test test, err error) {
-	tokens, err := tokenize(s)
-	if err != nil {
-		return nil, err
-	}
-	for _, tokenDef := range precedence {
-		if contains(tokens, tokenDef.op) {
-			return tokenDef.token.compile(tokens)
-		}
-	}
-	if contains(tokens, "%") {
-		m, err := compileMod(tokens)
+	case tokN:
+		return variable{}, nil
+
+	case tokLParen:
+		inner, err := p.parseExpression()
 		if err != nil {
 			return nil, err
 		}
-		return pipe{
-			modifier: m,
-			action:   equal{value: 0}, // default to testing for 0
-		}, nil
-	}
-	if len(tokens) == 1 && tokens[0] == "n" {
-		return equal{value: 1}, nil
-	}
-	return test, errors.New("cannot compile")
-}
-
-func parseUint32(s string) (ui uint32, err error) {
-	i, err := strconv.ParseUint(s, 10, 32)
-	if err != nil {
-		return ui, err
-	}
-	return uint32(i), nil
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "expected ')' to close '(' at position %d but found %s", tok.pos, closing)
+		}
+		return inner, nil
+	}
+
+	return nil, p.errorf(tok, "unexpected %s", tok)
+}
+
+func newBinary(op tokenKind, left, right node) node {
+	switch op {
+	case tokOr:
+		return or{left: left, right: right}
+	case tokAnd:
+		return and{left: left, right: right}
+	case tokEq:
+		return equal{left: left, right: right}
+	case tokNeq:
+		return notequal{left: left, right: right}
+	case tokLt:
+		return lt{left: left, right: right}
+	case tokLte:
+		return lte{left: left, right: right}
+	case tokGt:
+		return gt{left: left, right: right}
+	case tokGte:
+		return gte{left: left, right: right}
+	case tokAdd:
+		return add{left: left, right: right}
+	case tokSub:
+		return sub{left: left, right: right}
+	case tokMul:
+		return mul{left: left, right: right}
+	case tokDiv:
+		return div{left: left, right: right}
+	}
+	return mod{left: left, right: right}
 }
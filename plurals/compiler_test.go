@@ -8,6 +8,8 @@ package plurals
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"testing"
 )
@@ -37,7 +39,7 @@ func TestCompiler(t *testing.T) {
 			t.Fail()
 		} else {
 			for n, e := range data.Fixture {
-				i := expr.Eval(uint32(n))
+				i := expr.Eval(uint64(n))
 				if i != e {
 					t.Logf("'%s' with n = %d, expected %d, got %d, compiled to %s", data.PluralForm, n, e, i, expr)
 					t.Fail()
@@ -78,9 +80,11 @@ func TestCompile_EdgeCases(t *testing.T) {
 		t.Error("Expected error for unexpected EOF")
 	}
 
-	// Missing closing parenthesis - current compiler might not catch this strictly, 
-	// or it catches it in a way that doesn't return an error immediately from Compile.
-	// Let's remove it if it doesn't fail, or just focus on what DOES fail.
+	// Missing closing parenthesis
+	_, err = Compile("(n == 1 ? 0 : 1")
+	if err == nil {
+		t.Error("Expected error for missing closing parenthesis")
+	}
 }
 
 func TestCompile_LogicCoverage(t *testing.T) {
@@ -103,7 +107,7 @@ func TestEval_EdgeCases(t *testing.T) {
 	// Covering eval with different operators
 	tests := []struct {
 		expr string
-		n    uint32
+		n    uint64
 		want int
 	}{
 		{"n == 1", 1, 1},
@@ -114,8 +118,8 @@ func TestEval_EdgeCases(t *testing.T) {
 		{"n < 2", 1, 1},
 		{"n >= 1", 1, 1},
 		{"n <= 1", 1, 1},
-		{"n % 10", 3, 0}, // n % 10 == 0? 3 % 10 is 3, so false (0)
-		{"n % 10", 10, 1}, // 10 % 10 is 0, so true (1)
+		{"n % 10", 3, 3}, // the value of the expression is the form, like in C
+		{"n % 10", 10, 0},
 		{"n % 10 == 3", 3, 1},
 		{"n % 10 == 3", 13, 1},
 		{"n % 10 == 3", 4, 0},
@@ -170,3 +174,181 @@ func TestEval_EdgeCases(t *testing.T) {
 		}
 	}
 }
+
+func TestCompile_Grammar(t *testing.T) {
+	tests := []struct {
+		expr string
+		n    uint64
+		want int
+	}{
+		{"n", 7, 7},
+		{"!n", 0, 1},
+		{"!n", 3, 0},
+		{"!!n", 3, 1},
+		{"n + 1 * 2", 3, 5},
+		{"(n + 1) * 2", 3, 8},
+		{"n - 1", 0, -1}, // unsigned like in C, so it doesn't fit in an int
+		{"n - 1 + 2", 0, 1},
+		{"n", 5000000000, 5000000000},
+		{"n % 4294967296 == 1", 4294967297, 1},
+		{"n / 2 % 3", 10, 2},
+		{"10 - n - 1", 3, 6},
+		{"n / (n - 1)", 1, 0}, // runtime division by zero
+		{"n > 1 == 1", 2, 1},
+		{"n == 1 || n == 2 && 0", 2, 0},
+		{"(n == 1 ? 0 : 1) ? 2 : 3", 1, 3},
+		{"n == 1 ? n > 0 ? 4 : 5 : 6", 1, 4},
+		{"n < 2 ? 0 : n < 5 ? 1 : 2", 3, 1},
+		{"!(n % 10) && n != 0", 20, 1},
+		{" ( ( n ) ) ", 2, 2},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got := expr.Eval(tt.n); got != tt.want {
+			t.Errorf("Eval(%q, %d) = %d, want %d", tt.expr, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestCompile_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{"", 0},
+		{"(n == 1", 7},
+		{"n == 1)", 6},
+		{"(n)(n)", 3},
+		{"n ? 1", 5},
+		{"n ? 1 : ", 8},
+		{"n + @", 4},
+		{"n = 1", 2},
+		{"n % 0", 2},
+		{"- n", 0},
+		{"n 1", 2},
+		{"99999999999999999999", 0},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.expr)
+		if expr != nil {
+			t.Errorf("Compile(%q) returned an expression", tt.expr)
+		}
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("Compile(%q) = %v, want a *SyntaxError", tt.expr, err)
+			continue
+		}
+		if serr.Pos != tt.pos {
+			t.Errorf("Compile(%q) error at %d, want %d: %v", tt.expr, serr.Pos, tt.pos, err)
+		}
+	}
+}
+
+func loadFixtures(b testing.TB) []fixture {
+	f, err := os.Open("testdata/pluralforms.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	var fixtures []fixture
+	if err = json.NewDecoder(f).Decode(&fixtures); err != nil {
+		b.Fatal(err)
+	}
+	return fixtures
+}
+
+func BenchmarkEval(b *testing.B) {
+	benchmarkEval(b, func(expr *expression) Expression { return expr })
+}
+
+// treeExpression evaluates an expression walking its tree
+type treeExpression struct {
+	*expression
+}
+
+func (e treeExpression) Eval(n uint64) int {
+	return int(e.root.eval(n))
+}
+
+// BenchmarkEvalTree walks the trees of the expressions BenchmarkEval evaluates, as a baseline
+func BenchmarkEvalTree(b *testing.B) {
+	benchmarkEval(b, func(expr *expression) Expression { return treeExpression{expr} })
+}
+
+func benchmarkEval(b *testing.B, wrap func(*expression) Expression) {
+	for i, data := range loadFixtures(b) {
+		compiled, err := Compile(data.PluralForm)
+		if err != nil {
+			b.Fatal(err)
+		}
+		expr := wrap(compiled.(*expression))
+		b.Run(fmt.Sprintf("%02d", i), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for n := uint64(0); n < 200; n++ {
+					expr.Eval(n)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	fixtures := loadFixtures(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, data := range fixtures {
+			if _, err := Compile(data.PluralForm); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestCompile_ClosuresMatchTree(t *testing.T) {
+	exprs := []string{
+		"n", "0", "!n", "!!n", "n ? 5 : 7", "n ? n - 1 : n + 1", "n / (n % 3)", "n % (n - n)",
+		"n * 2 - 1", "(n % 10 >= 2) + (n % 10 <= 4)", "n >= 5 && n <= 2", "n - 3 >= 2 && n - 3 <= 4",
+		"n % 10 >= 2 && n % 100 <= 4", "n % 7 == n % 5", "2 > n", "n % 3 ? n % 3 == 1 ? 4 : 5 : 6",
+		"n > 3 || n % 2 && n < 100", "18446744073709551615 - n",
+	}
+	for _, rule := range languageRules {
+		exprs = append(exprs, rule.Plural)
+	}
+	for _, data := range loadFixtures(t) {
+		exprs = append(exprs, data.PluralForm)
+	}
+
+	for _, s := range exprs {
+		expr, err := Compile(s)
+		if err != nil {
+			t.Errorf("Compile(%q) failed: %v", s, err)
+			continue
+		}
+		root := expr.(*expression).root
+		eval := compileValue(root)
+		for _, n := range samples {
+			if got, want := eval(n), root.eval(n); got != want {
+				t.Errorf("%q with n = %d: closure gives %d, tree gives %d", s, n, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestCompile_Shared(t *testing.T) {
+	a, _ := Compile("(n != 1)")
+	b, _ := Compile("n!=1")
+	c, _ := Compile("n != 1")
+	if a != b || a != c {
+		t.Error("Expected expressions with the same normalized text to be shared")
+	}
+
+	d, _ := Compile("n > 1")
+	if a == d {
+		t.Error("Expected different expressions not to be shared")
+	}
+}
@@ -6,39 +6,75 @@
 
 package plurals
 
+import "math"
+
 // Expression is a plurals expression. Eval evaluates the expression for
 // a given n value. Use plurals.Compile to generate Expression instances.
+// Like in GNU gettext, evaluation uses unsigned 64-bit arithmetic. Results
+// that don't fit in an int evaluate to -1, which isn't a valid form.
+// String returns the expression in normalized form.
 type Expression interface {
-	Eval(n uint32) int
+	Eval(n uint64) int
+	String() string
+}
+
+// node is a compiled expression tree node. Like in C, every node evaluates to
+// an unsigned number, and comparisons and logical operators evaluate to 0 or 1.
+type node interface {
+	eval(n uint64) uint64
+}
+
+// expression is a compiled expression. The tree is kept to print the expression,
+// evaluation uses the closure compiled from it, or the tree when it's a constant or n,
+// which the closure call would only slow down.
+type expression struct {
+	root node
+	eval valueFunc
+}
+
+func (e *expression) Eval(n uint64) int {
+	var v uint64
+	if e.eval != nil {
+		v = e.eval(n)
+	} else {
+		v = e.root.eval(n)
+	}
+	if v > math.MaxInt {
+		return -1
+	}
+	return int(v)
 }
 
 type constValue struct {
-	value int
+	value uint64
 }
 
-func (c constValue) Eval(n uint32) int {
+func (c constValue) eval(n uint64) uint64 {
 	return c.value
 }
 
-type test interface {
-	test(n uint32) bool
+type variable struct{}
+
+func (variable) eval(n uint64) uint64 {
+	return n
 }
 
 type ternary struct {
-	test      test
-	trueExpr  Expression
-	falseExpr Expression
+	cond node
+	yes  node
+	no   node
 }
 
-func (t ternary) Eval(n uint32) int {
-	if t.test.test(n) {
-		if t.trueExpr == nil {
-			return -1
-		}
-		return t.trueExpr.Eval(n)
+func (t ternary) eval(n uint64) uint64 {
+	if t.cond.eval(n) != 0 {
+		return t.yes.eval(n)
 	}
-	if t.falseExpr == nil {
-		return -1
+	return t.no.eval(n)
+}
+
+func boolValue(b bool) uint64 {
+	if b {
+		return 1
 	}
-	return t.falseExpr.Eval(n)
+	return 0
 }
@@ -0,0 +1,116 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Precedence levels used to print expressions, as used by the parser
+const (
+	precTernary = iota
+	precOr
+	precAnd
+	precEquality
+	precRelational
+	precAdditive
+	precMultiplicative
+	precUnary
+	precAtom
+)
+
+// String returns the expression in normalized form: operators surrounded by spaces
+// and only the parentheses needed. Compiling it again gives an equivalent expression.
+func (e *expression) String() string {
+	var b strings.Builder
+	writeNode(&b, e.root, precTernary)
+	return b.String()
+}
+
+// writeNode writes n, in parentheses if its precedence is lower than prec
+func writeNode(b *strings.Builder, n node, prec int) {
+	p := nodePrecedence(n)
+	if p < prec {
+		b.WriteByte('(')
+		defer b.WriteByte(')')
+	}
+
+	switch x := n.(type) {
+	case constValue:
+		b.WriteString(strconv.FormatUint(x.value, 10))
+	case variable:
+		b.WriteByte('n')
+	case not:
+		b.WriteByte('!')
+		writeNode(b, x.operand, precUnary)
+	case ternary:
+		writeNode(b, x.cond, precOr)
+		b.WriteString(" ? ")
+		writeNode(b, x.yes, precTernary)
+		b.WriteString(" : ")
+		writeNode(b, x.no, precTernary)
+	default:
+		op, left, right := binaryParts(n)
+		// Left associative: same precedence on the right needs parentheses
+		writeNode(b, left, p)
+		b.WriteString(" " + op + " ")
+		writeNode(b, right, p+1)
+	}
+}
+
+func nodePrecedence(n node) int {
+	switch n.(type) {
+	case constValue, variable:
+		return precAtom
+	case not:
+		return precUnary
+	case ternary:
+		return precTernary
+	case or:
+		return precOr
+	case and:
+		return precAnd
+	case equal, notequal:
+		return precEquality
+	case lt, lte, gt, gte:
+		return precRelational
+	case add, sub:
+		return precAdditive
+	}
+	return precMultiplicative
+}
+
+func binaryParts(n node) (op string, left, right node) {
+	switch x := n.(type) {
+	case or:
+		return "||", x.left, x.right
+	case and:
+		return "&&", x.left, x.right
+	case equal:
+		return "==", x.left, x.right
+	case notequal:
+		return "!=", x.left, x.right
+	case lt:
+		return "<", x.left, x.right
+	case lte:
+		return "<=", x.left, x.right
+	case gt:
+		return ">", x.left, x.right
+	case gte:
+		return ">=", x.left, x.right
+	case add:
+		return "+", x.left, x.right
+	case sub:
+		return "-", x.left, x.right
+	case mul:
+		return "*", x.left, x.right
+	case div:
+		return "/", x.left, x.right
+	case mod:
+		return "%", x.left, x.right
+	}
+	panic("plurals: unknown node type")
+}
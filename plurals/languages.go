@@ -0,0 +1,173 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rule is the value of a Plural-Forms header: the number of plural forms and
+// the expression choosing one of them for a given n.
+type Rule struct {
+	NPlurals int
+	Plural   string
+}
+
+// String returns the rule formatted as a Plural-Forms header value.
+func (r Rule) String() string {
+	return "nplurals=" + strconv.Itoa(r.NPlurals) + "; plural=" + r.Plural + ";"
+}
+
+// ForLanguage returns the standard plural rule for a language tag such as "pl", "pt_BR",
+// "pt-BR" or "sr_RS@latin", like gettext's msginit does for new catalogs.
+// Tags with a region not found in the table use the rule of the language alone.
+func ForLanguage(tag string) (Rule, bool) {
+	lang, region, ok := normalizeTag(tag)
+	if !ok {
+		return Rule{}, false
+	}
+	if region != "" {
+		if r, ok := languageRules[lang+"_"+region]; ok {
+			return r, true
+		}
+	}
+
+	r, ok := languageRules[lang]
+	return r, ok
+}
+
+// normalizeTag splits a language tag into its lower case language and upper case region,
+// dropping codeset and modifier.
+func normalizeTag(tag string) (lang, region string, ok bool) {
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "-", "_")
+	if tag == "" {
+		return "", "", false
+	}
+
+	lang, region, _ = strings.Cut(tag, "_")
+	return strings.ToLower(lang), strings.ToUpper(region), true
+}
+
+var (
+	ruleOne    = Rule{NPlurals: 1, Plural: "0"}
+	ruleNotOne = Rule{NPlurals: 2, Plural: "(n != 1)"}
+	ruleGtOne  = Rule{NPlurals: 2, Plural: "(n > 1)"}
+	ruleSlavic = Rule{NPlurals: 3, Plural: "(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)"}
+	ruleCzech  = Rule{NPlurals: 3, Plural: "(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2"}
+)
+
+// languageRules holds the Plural-Forms of gettext's msginit table (gettext-tools/src/plural-table.c),
+// plus the integer rules of other widely translated languages, as given by CLDR. It isn't exhaustive:
+// ForLanguage reports false for the languages not listed here.
+var languageRules = map[string]Rule{
+	// Only one form
+	"ja": ruleOne,
+	"ko": ruleOne,
+	"vi": ruleOne,
+	"zh": ruleOne,
+	"th": ruleOne,
+	"id": ruleOne,
+	"ms": ruleOne,
+	"lo": ruleOne,
+	"km": ruleOne,
+	"my": ruleOne,
+
+	// Two forms, singular used for one only
+	"en": ruleNotOne,
+	"de": ruleNotOne,
+	"nl": ruleNotOne,
+	"sv": ruleNotOne,
+	"da": ruleNotOne,
+	"no": ruleNotOne,
+	"nb": ruleNotOne,
+	"nn": ruleNotOne,
+	"fo": ruleNotOne,
+	"es": ruleNotOne,
+	"pt": ruleNotOne,
+	"it": ruleNotOne,
+	"bg": ruleNotOne,
+	"el": ruleNotOne,
+	"fi": ruleNotOne,
+	"et": ruleNotOne,
+	"he": ruleNotOne,
+	"eo": ruleNotOne,
+	"hu": ruleNotOne,
+	"tr": ruleNotOne,
+	"ca": ruleNotOne,
+	"gl": ruleNotOne,
+	"eu": ruleNotOne,
+	"af": ruleNotOne,
+	"sq": ruleNotOne,
+	"hi": ruleNotOne,
+	"bn": ruleNotOne,
+	"ta": ruleNotOne,
+	"te": ruleNotOne,
+	"ml": ruleNotOne,
+	"mr": ruleNotOne,
+	"gu": ruleNotOne,
+	"kn": ruleNotOne,
+	"ur": ruleNotOne,
+	"sw": ruleNotOne,
+	"ka": ruleNotOne,
+	"az": ruleNotOne,
+	"kk": ruleNotOne,
+	"ky": ruleNotOne,
+	"uz": ruleNotOne,
+	"mn": ruleNotOne,
+	"ne": ruleNotOne,
+	"lb": ruleNotOne,
+	"ps": ruleNotOne,
+	"so": ruleNotOne,
+
+	// Two forms, singular used for zero and one
+	"pt_BR": ruleGtOne,
+	"fr":    ruleGtOne,
+	"fa":    ruleGtOne,
+	"hy":    ruleGtOne,
+
+	// Three forms, special case for zero
+	"lv": {NPlurals: 3, Plural: "(n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2)"},
+
+	// Three forms, special cases for one and two
+	"ga": {NPlurals: 3, Plural: "n==1 ? 0 : n==2 ? 1 : 2"},
+
+	// Three forms, special case for numbers ending in 00 or [2-9][0-9]
+	"ro": {NPlurals: 3, Plural: "n==1 ? 0 : (n==0 || (n%100 > 0 && n%100 < 20)) ? 1 : 2"},
+
+	// Three forms, special case for numbers ending in 1[2-9]
+	"lt": {NPlurals: 3, Plural: "(n%10==1 && n%100!=11 ? 0 : n%10>=2 && (n%100<10 || n%100>=20) ? 1 : 2)"},
+
+	// Three forms, special cases for numbers ending in 1 and 2, 3, 4, except those ending in 1[1-4]
+	"ru": ruleSlavic,
+	"uk": ruleSlavic,
+	"be": ruleSlavic,
+	"sr": ruleSlavic,
+	"hr": ruleSlavic,
+	"bs": ruleSlavic,
+
+	// Three forms, special cases for 1 and 2, 3, 4
+	"cs": ruleCzech,
+	"sk": ruleCzech,
+
+	// Three forms, special case for one and some numbers ending in 2, 3, or 4
+	"pl": {NPlurals: 3, Plural: "(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)"},
+
+	// Two forms, singular used for numbers ending in 1 except 11
+	"is": {NPlurals: 2, Plural: "(n%10!=1 || n%100==11)"},
+	"mk": {NPlurals: 2, Plural: "(n%10!=1 || n%100==11)"},
+
+	// Four forms, special case for one and all numbers ending in 02, 03, or 04
+	"sl": {NPlurals: 4, Plural: "(n%100==1 ? 0 : n%100==2 ? 1 : n%100==3 || n%100==4 ? 2 : 3)"},
+
+	// Four forms, special cases for one, two, and numbers other than 8 and 11
+	"cy": {NPlurals: 4, Plural: "(n==1) ? 0 : (n==2) ? 1 : (n != 8 && n != 11) ? 2 : 3"},
+
+	// Six forms, special cases for one, two, all numbers ending in 02, 03, … 10, all numbers ending in 11 … 99, and others
+	"ar": {NPlurals: 6, Plural: "(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5)"},
+}
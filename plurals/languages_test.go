@@ -0,0 +1,69 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+import "testing"
+
+func TestLanguageRulesCompile(t *testing.T) {
+	for lang, rule := range languageRules {
+		expr, err := Compile(rule.Plural)
+		if err != nil {
+			t.Errorf("%s: %v", lang, err)
+			continue
+		}
+
+		// Every form must be reachable and none out of range
+		seen := make([]bool, rule.NPlurals)
+		for n := uint64(0); n <= 1000; n++ {
+			form := expr.Eval(n)
+			if form < 0 || form >= rule.NPlurals {
+				t.Errorf("%s: form %d for n = %d is out of range for nplurals=%d", lang, form, n, rule.NPlurals)
+				break
+			}
+			seen[form] = true
+		}
+		for form, ok := range seen {
+			if !ok {
+				t.Errorf("%s: form %d is never used", lang, form)
+			}
+		}
+	}
+}
+
+func TestForLanguage(t *testing.T) {
+	tests := []struct {
+		tag      string
+		nplurals int
+		ok       bool
+	}{
+		{"pl", 3, true},
+		{"pl_PL", 3, true},
+		{"pl-PL.UTF-8", 3, true},
+		{"sr_RS@latin", 3, true},
+		{"PT_br", 2, true},
+		{"ar", 6, true},
+		{"ja_JP", 1, true},
+		{"fa_IR", 2, true},
+		{"mk", 2, true},
+		{"xx", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		r, ok := ForLanguage(tt.tag)
+		if ok != tt.ok || r.NPlurals != tt.nplurals {
+			t.Errorf("ForLanguage(%q) = %v, %v; want nplurals=%d, %v", tt.tag, r, ok, tt.nplurals, tt.ok)
+		}
+	}
+
+	if r, _ := ForLanguage("pt_BR"); r.Plural != "(n > 1)" {
+		t.Errorf("Expected pt_BR to use the French rule, got %s", r)
+	}
+	if r, _ := ForLanguage("pt_PT"); r.Plural != "(n != 1)" {
+		t.Errorf("Expected pt_PT to use the Portuguese rule, got %s", r)
+	}
+	if s := ruleNotOne.String(); s != "nplurals=2; plural=(n != 1);" {
+		t.Errorf("Unexpected Plural-Forms value %q", s)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2018-present gotext maintainers (https://github.com/leonelquinteros/gotext)
+//
+// Licensed under the 3-Clause BSD License. See LICENSE in the project root for license information.
+
+package plurals
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokN
+	tokQuestion
+	tokColon
+	tokOr
+	tokAnd
+	tokEq
+	tokNeq
+	tokLt
+	tokLte
+	tokGt
+	tokGte
+	tokAdd
+	tokSub
+	tokMul
+	tokDiv
+	tokMod
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+var tokenNames = [...]string{
+	tokEOF:      "end of expression",
+	tokNumber:   "number",
+	tokN:        "'n'",
+	tokQuestion: "'?'",
+	tokColon:    "':'",
+	tokOr:       "'||'",
+	tokAnd:      "'&&'",
+	tokEq:       "'=='",
+	tokNeq:      "'!='",
+	tokLt:       "'<'",
+	tokLte:      "'<='",
+	tokGt:       "'>'",
+	tokGte:      "'>='",
+	tokAdd:      "'+'",
+	tokSub:      "'-'",
+	tokMul:      "'*'",
+	tokDiv:      "'/'",
+	tokMod:      "'%'",
+	tokNot:      "'!'",
+	tokLParen:   "'('",
+	tokRParen:   "')'",
+}
+
+func (k tokenKind) String() string {
+	return tokenNames[k]
+}
+
+type token struct {
+	kind  tokenKind
+	pos   int
+	value uint64
+}
+
+func (t token) String() string {
+	if t.kind == tokNumber {
+		return strconv.FormatUint(uint64(t.value), 10)
+	}
+	return t.kind.String()
+}
+
+// SyntaxError is returned by Compile for malformed expressions.
+// Pos is the byte offset in Expr where the error was found.
+type SyntaxError struct {
+	Expr string
+	Pos  int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("plural expression %q: position %d: %s", e.Expr, e.Pos, e.Msg)
+}
+
+// lex splits a plural expression into tokens, following the lexer of GNU gettext plural.y
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+				i++
+			}
+			v, err := strconv.ParseUint(s[start:i], 10, 64)
+			if err != nil {
+				return nil, &SyntaxError{Expr: s, Pos: start, Msg: "number out of range: " + s[start:i]}
+			}
+			tokens = append(tokens, token{kind: tokNumber, pos: start, value: v})
+			continue
+
+		case c == 'n':
+			tokens = append(tokens, token{kind: tokN, pos: i})
+			i++
+			continue
+		}
+
+		// Operators, with two character ones first
+		var next byte
+		if i+1 < len(s) {
+			next = s[i+1]
+		}
+		kind, size := tokEOF, 1
+		switch c {
+		case '|':
+			if next == '|' {
+				kind, size = tokOr, 2
+			}
+		case '&':
+			if next == '&' {
+				kind, size = tokAnd, 2
+			}
+		case '=':
+			if next == '=' {
+				kind, size = tokEq, 2
+			}
+		case '!':
+			kind = tokNot
+			if next == '=' {
+				kind, size = tokNeq, 2
+			}
+		case '<':
+			kind = tokLt
+			if next == '=' {
+				kind, size = tokLte, 2
+			}
+		case '>':
+			kind = tokGt
+			if next == '=' {
+				kind, size = tokGte, 2
+			}
+		case '?':
+			kind = tokQuestion
+		case ':':
+			kind = tokColon
+		case '+':
+			kind = tokAdd
+		case '-':
+			kind = tokSub
+		case '*':
+			kind = tokMul
+		case '/':
+			kind = tokDiv
+		case '%':
+			kind = tokMod
+		case '(':
+			kind = tokLParen
+		case ')':
+			kind = tokRParen
+		}
+		if kind == tokEOF {
+			return nil, &SyntaxError{Expr: s, Pos: i, Msg: fmt.Sprintf("invalid character %q", c)}
+		}
+
+		tokens = append(tokens, token{kind: kind, pos: i})
+		i += size
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(s)}), nil
+}
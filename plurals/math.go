@@ -6,14 +6,58 @@
 
 package plurals
 
-type math interface {
-	calc(n uint32) uint32
+// Arithmetic is unsigned, so subtraction wraps around like in C.
+// Division by a zero value computed at runtime evaluates to 0.
+
+type add struct {
+	left  node
+	right node
+}
+
+func (e add) eval(n uint64) uint64 {
+	return e.left.eval(n) + e.right.eval(n)
+}
+
+type sub struct {
+	left  node
+	right node
+}
+
+func (e sub) eval(n uint64) uint64 {
+	return e.left.eval(n) - e.right.eval(n)
+}
+
+type mul struct {
+	left  node
+	right node
+}
+
+func (e mul) eval(n uint64) uint64 {
+	return e.left.eval(n) * e.right.eval(n)
+}
+
+type div struct {
+	left  node
+	right node
+}
+
+func (e div) eval(n uint64) uint64 {
+	r := e.right.eval(n)
+	if r == 0 {
+		return 0
+	}
+	return e.left.eval(n) / r
 }
 
 type mod struct {
-	value uint32
+	left  node
+	right node
 }
 
-func (m mod) calc(n uint32) uint32 {
-	return n % m.value
+func (e mod) eval(n uint64) uint64 {
+	r := e.right.eval(n)
+	if r == 0 {
+		return 0
+	}
+	return e.left.eval(n) % r
 }
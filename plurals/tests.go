@@ -7,99 +7,87 @@
 package plurals
 
 type equal struct {
-	value uint32
+	left  node
+	right node
 }
 
-func (e equal) test(n uint32) bool {
-	return n == e.value
+func (e equal) eval(n uint64) uint64 {
+	return boolValue(e.left.eval(n) == e.right.eval(n))
 }
 
 type notequal struct {
-	value uint32
+	left  node
+	right node
 }
 
-func (e notequal) test(n uint32) bool {
-	return n != e.value
+func (e notequal) eval(n uint64) uint64 {
+	return boolValue(e.left.eval(n) != e.right.eval(n))
 }
 
 type gt struct {
-	value   uint32
-	flipped bool
+	left  node
+	right node
 }
 
-func (e gt) test(n uint32) bool {
-	if e.flipped {
-		return e.value > n
-	}
-
-	return n > e.value
+func (e gt) eval(n uint64) uint64 {
+	return boolValue(e.left.eval(n) > e.right.eval(n))
 }
 
 type lt struct {
-	value   uint32
-	flipped bool
+	left  node
+	right node
 }
 
-func (e lt) test(n uint32) bool {
-	if e.flipped {
-		return e.value < n
-	}
-	return n < e.value
+func (e lt) eval(n uint64) uint64 {
+	return boolValue(e.left.eval(n) < e.right.eval(n))
 }
 
 type gte struct {
-	value   uint32
-	flipped bool
+	left  node
+	right node
 }
 
-func (e gte) test(n uint32) bool {
-	if e.flipped {
-		return e.value >= n
-	}
-	return n >= e.value
+func (e gte) eval(n uint64) uint64 {
+	return boolValue(e.left.eval(n) >= e.right.eval(n))
 }
 
 type lte struct {
-	value   uint32
-	flipped bool
+	left  node
+	right node
 }
 
-func (e lte) test(n uint32) bool {
-	if e.flipped {
-		return e.value <= n
-	}
-	return n <= e.value
+func (e lte) eval(n uint64) uint64 {
+	return boolValue(e.left.eval(n) <= e.right.eval(n))
 }
 
 type and struct {
-	left  test
-	right test
+	left  node
+	right node
 }
 
-func (e and) test(n uint32) bool {
-	if !e.left.test(n) {
-		return false
+func (e and) eval(n uint64) uint64 {
+	if e.left.eval(n) == 0 {
+		return 0
 	}
-	return e.right.test(n)
+	return boolValue(e.right.eval(n) != 0)
 }
 
 type or struct {
-	left  test
-	right test
+	left  node
+	right node
 }
 
-func (e or) test(n uint32) bool {
-	if e.left.test(n) {
-		return true
+func (e or) eval(n uint64) uint64 {
+	if e.left.eval(n) != 0 {
+		return 1
 	}
-	return e.right.test(n)
+	return boolValue(e.right.eval(n) != 0)
 }
 
-type pipe struct {
-	modifier math
-	action   test
+type not struct {
+	operand node
 }
 
-func (e pipe) test(n uint32) bool {
-	return e.action.test(e.modifier.calc(n))
+func (e not) eval(n uint64) uint64 {
+	return boolValue(e.operand.eval(n) == 0)
 }
@@ -6,6 +6,8 @@
 package gotext
 
 import (
+	"errors"
+	"fmt"
 	"io/fs"
 	"strconv"
 	"strings"
@@ -94,7 +96,7 @@ func (po *Po) GetRefs(str string) []string {
 
 // SetPluralResolver sets the plural resolver function
 func (po *Po) SetPluralResolver(f func(int) int) {
-	po.domain.customPluralResolver = f
+	po.domain.SetPluralResolver(f)
 }
 
 // Set translation
@@ -112,6 +114,11 @@ func (po *Po) Append(b []byte, str string, vars ...interface{}) []byte {
 	return po.domain.Append(b, str, vars...)
 }
 
+// Lookup gets the translation, reporting whether it was found
+func (po *Po) Lookup(str string) (string, bool) {
+	return po.domain.Lookup(str)
+}
+
 // SetN sets the plural translation
 func (po *Po) SetN(id, plural string, n int, str string) {
 	po.domain.SetN(id, plural, n, str)
@@ -127,6 +134,47 @@ func (po *Po) AppendN(b []byte, str, plural string, n int, vars ...interface{})
 	return po.domain.AppendN(b, str, plural, n, vars...)
 }
 
+// LookupN gets the plural translation, reporting the plural form used and whether it was found
+func (po *Po) LookupN(str, plural string, n int) (string, int, bool) {
+	return po.domain.LookupN(str, plural, n)
+}
+
+// GetNDecimal gets the plural translation for a number formatted as a decimal, like "1.5"
+func (po *Po) GetNDecimal(str, plural, n string, vars ...interface{}) string {
+	return po.domain.GetNDecimal(str, plural, n, vars...)
+}
+
+// GetNFloat gets the plural translation for a float64
+func (po *Po) GetNFloat(str, plural string, n float64, vars ...interface{}) string {
+	return po.domain.GetNFloat(str, plural, n, vars...)
+}
+
+// GetO gets the ordinal translation for the given string matching n
+func (po *Po) GetO(str string, n int, vars ...interface{}) string {
+	return po.domain.GetO(str, n, vars...)
+}
+
+// GetOC gets the ordinal translation for the given string matching n in the given context
+func (po *Po) GetOC(str string, n int, ctx string, vars ...interface{}) string {
+	return po.domain.GetOC(str, n, ctx, vars...)
+}
+
+// LookupO gets the ordinal translation, reporting the form used and whether it was found
+func (po *Po) LookupO(str string, n int) (string, int, bool) {
+	return po.domain.LookupO(str, n)
+}
+
+// LookupOC gets the ordinal translation in the given context, reporting the form used and whether it was found
+func (po *Po) LookupOC(str string, n int, ctx string) (string, int, bool) {
+	return po.domain.LookupOC(str, n, ctx)
+}
+
+// LookupNDecimal gets the plural translation for a number formatted as a decimal,
+// reporting the plural form used and whether it was found
+func (po *Po) LookupNDecimal(str, plural, n string) (string, int, bool) {
+	return po.domain.LookupNDecimal(str, plural, n)
+}
+
 // SetC sets the translation for a given context
 func (po *Po) SetC(id, ctx, str string) {
 	po.domain.SetC(id, ctx, str)
@@ -142,6 +190,11 @@ func (po *Po) AppendC(b []byte, str, ctx string, vars ...interface{}) []byte {
 	return po.domain.AppendC(b, str, ctx, vars...)
 }
 
+// LookupC gets the translation for a given context, reporting whether it was found
+func (po *Po) LookupC(str, ctx string) (string, bool) {
+	return po.domain.LookupC(str, ctx)
+}
+
 // SetNC sets the plural translation for a given context
 func (po *Po) SetNC(id, plural, ctx string, n int, str string) {
 	po.domain.SetNC(id, plural, ctx, n, str)
@@ -157,6 +210,11 @@ func (po *Po) AppendNC(b []byte, str, plural string, n int, ctx string, vars ...
 	return po.domain.AppendNC(b, str, plural, n, ctx, vars...)
 }
 
+// LookupNC gets the plural translation for a given context, reporting the plural form used and whether it was found
+func (po *Po) LookupNC(str, plural string, n int, ctx string) (string, int, bool) {
+	return po.domain.LookupNC(str, plural, n, ctx)
+}
+
 // IsTranslated checks if the given string is translated
 func (po *Po) IsTranslated(str string) bool {
 	return po.domain.IsTranslated(str)
@@ -204,96 +262,130 @@ func (po *Po) ParseFile(f string) {
 
 // Parse loads the translations specified in the provided byte slice (buf)
 func (po *Po) Parse(buf []byte) {
+	_ = po.parse(buf)
+}
+
+// parse loads the translations from buf, same as Parse, and reports the first malformed line found.
+// Malformed lines are skipped like Parse always did, so the returned error is informative only.
+func (po *Po) parse(buf []byte) error {
 	if po.domain == nil {
 		panic("NewPo() was not used to instantiate this object")
 	}
 
-	// Lock while parsing
-	po.domain.trMutex.Lock()
-	po.domain.pluralMutex.Lock()
-	defer po.domain.trMutex.Unlock()
-	defer po.domain.pluralMutex.Unlock()
+	// Lock while parsing, lookups keep using the current contents until the new ones are published
+	po.domain.writeMutex.Lock()
+	defer po.domain.writeMutex.Unlock()
 
 	// Get lines
 	lines := strings.Split(string(buf), "\n")
 
-	// Init buffer
+	// Init buffer. Obsolete and repeated entries are the ones of buf only, so parsing again doesn't add them twice.
+	po.domain.dataBuffer = po.domain.load().clone()
+	po.domain.dataBuffer.obsolete = nil
+	po.domain.dataBuffer.duplicates = nil
+	po.domain.headerBuffer = nil
 	po.domain.trBuffer = NewTranslation()
 	po.domain.ctxBuffer = ""
 	po.domain.refBuffer = ""
-
+	po.domain.flagBuffer = nil
+	po.domain.commentBuffer = nil
+	po.domain.seenBuffer = make(map[string]bool)
+	po.domain.previousBuffer = Translation{}
+	po.domain.previousState = head
+	po.domain.obsoleteState = head
+	po.domain.obsoleteCtx = ""
+
+	var parseErr error
 	state := head
-	for _, l := range lines {
+	for i, l := range lines {
+		var err error
+
 		// Trim spaces
 		l = strings.TrimSpace(l)
 
+		switch {
 		// Skip invalid lines
-		if !po.isValidLine(l) {
+		case !po.isValidLine(l):
 			po.parseComment(l, state)
-			continue
-		}
 
 		// Buffer context and continue
-		if strings.HasPrefix(l, "msgctxt") {
-			po.parseContext(l)
+		case strings.HasPrefix(l, "msgctxt"):
+			err = po.parseContext(l)
 			state = msgCtxt
-			continue
-		}
 
 		// Buffer msgid and continue
-		if strings.HasPrefix(l, "msgid") && !strings.HasPrefix(l, "msgid_plural") {
-			po.parseID(l)
+		case strings.HasPrefix(l, "msgid") && !strings.HasPrefix(l, "msgid_plural"):
+			err = po.parseID(l)
+			po.domain.trBuffer.line = i + 1
 			state = msgID
-			continue
-		}
 
 		// Check for plural form
-		if strings.HasPrefix(l, "msgid_plural") {
-			po.parsePluralID(l)
-			po.domain.pluralTranslations[po.domain.trBuffer.PluralID] = po.domain.trBuffer
+		case strings.HasPrefix(l, "msgid_plural"):
+			err = po.parsePluralID(l)
+			po.domain.dataBuffer.pluralTranslations[po.domain.trBuffer.PluralID] = po.domain.trBuffer
 			state = msgIDPlural
-			continue
-		}
 
 		// Save Translation
-		if strings.HasPrefix(l, "msgstr") {
-			po.parseMessage(l)
+		case strings.HasPrefix(l, "msgstr"):
+			err = po.parseMessage(l)
 			state = msgStr
-			continue
-		}
 
 		// Multi line strings and headers
-		if strings.HasPrefix(l, "\"") && strings.HasSuffix(l, "\"") {
-			po.parseString(l, state)
-			continue
+		case strings.HasPrefix(l, "\"") && strings.HasSuffix(l, "\""):
+			err = po.parseString(l, state)
+
+		default:
+			err = errors.New("unterminated string")
+		}
+
+		if err != nil && parseErr == nil {
+			parseErr = fmt.Errorf("line %d: %v", i+1, err)
 		}
 	}
 
 	// Save last Translation buffer.
 	po.saveBuffer()
 
-	// Parse headers
+	// Parse headers, the comments before them replace the previous ones
 	po.domain.parseHeaders()
+	if po.domain.headerBuffer != nil {
+		po.domain.headerComments = po.domain.headerBuffer
+		po.domain.headerBuffer = nil
+	}
+
+	// Publish parsed contents
+	po.domain.dataBuffer.ownedContexts = nil
+	po.domain.data.Store(po.domain.dataBuffer)
+	po.domain.dataBuffer = nil
+	po.domain.seenBuffer = nil
 
 	// set values on this struct
 	// this is for backwards compatibility
 	po.Language = po.domain.Language
 	po.PluralForms = po.domain.PluralForms
 	po.Headers = po.domain.Headers
+
+	return parseErr
 }
 
 // saveBuffer takes the context and Translation buffers
 // and saves it on the translations collection
 func (po *Po) saveBuffer() {
+	// Remember repeated entries, the last one wins
+	if tr := po.domain.trBuffer; tr.ID != "" || tr.line > 0 {
+		key := po.domain.ctxBuffer + "\x04" + tr.ID
+		if po.domain.seenBuffer[key] {
+			po.domain.dataBuffer.duplicates = append(po.domain.dataBuffer.duplicates, duplicateEntry{ctx: po.domain.ctxBuffer, id: tr.ID, line: tr.line})
+		}
+		po.domain.seenBuffer[key] = true
+	}
+
 	// With no context...
 	if po.domain.ctxBuffer == "" {
-		po.domain.translations[po.domain.trBuffer.ID] = po.domain.trBuffer
+		po.domain.dataBuffer.translations[po.domain.trBuffer.ID] = po.domain.trBuffer
 	} else {
 		// With context...
-		if _, ok := po.domain.contextTranslations[po.domain.ctxBuffer]; !ok {
-			po.domain.contextTranslations[po.domain.ctxBuffer] = make(map[string]*Translation)
-		}
-		po.domain.contextTranslations[po.domain.ctxBuffer][po.domain.trBuffer.ID] = po.domain.trBuffer
+		po.domain.dataBuffer.context(po.domain.ctxBuffer)[po.domain.trBuffer.ID] = po.domain.trBuffer
 
 		// Cleanup current context buffer if needed
 		if po.domain.trBuffer.ID != "" {
@@ -302,57 +394,206 @@ func (po *Po) saveBuffer() {
 	}
 
 	// Flush Translation buffer
-	if po.domain.refBuffer == "" {
-		po.domain.trBuffer = NewTranslation()
-	} else {
-		po.domain.trBuffer = NewTranslationWithRefs(strings.Split(po.domain.refBuffer, " "))
-	}
+	po.domain.trBuffer = NewTranslation()
 }
 
 // Either preserves comments before the first "msgid", for later round-trip.
 // Or preserves source references for a given translation.
 func (po *Po) parseComment(l string, state parseState) {
 	if len(l) > 0 && l[0] == '#' {
-		if state == head {
-			po.domain.headerComments = append(po.domain.headerComments, l)
+		if state != head && strings.HasPrefix(l, "#~") {
+			po.parseObsolete(strings.TrimSpace(l[2:]))
+		} else if state == head {
+			po.domain.headerBuffer = append(po.domain.headerBuffer, l)
 		} else if len(l) > 1 {
 			switch l[1] {
 			case ':':
 				if len(l) > 2 {
 					po.domain.refBuffer = strings.TrimSpace(l[2:])
 				}
+			case ',':
+				for _, flag := range strings.Split(l[2:], ",") {
+					if flag = strings.TrimSpace(flag); flag != "" {
+						po.domain.flagBuffer = append(po.domain.flagBuffer, flag)
+					}
+				}
+			case '.':
+				po.domain.commentBuffer = append(po.domain.commentBuffer, strings.TrimSpace(l[2:]))
+			case '|':
+				po.parsePrevious(strings.TrimSpace(l[2:]))
+			}
+		}
+	}
+}
+
+// parsePrevious buffers the previous msgctxt, msgid and msgid_plural of a fuzzy entry, from lines starting with "#|".
+func (po *Po) parsePrevious(l string) {
+	prev := &po.domain.previousBuffer
+	var field *string
+	switch {
+	case strings.HasPrefix(l, "msgctxt"):
+		po.domain.previousState = msgCtxt
+		field, l = &prev.PreviousContext, strings.TrimPrefix(l, "msgctxt")
+	case strings.HasPrefix(l, "msgid_plural"):
+		po.domain.previousState = msgIDPlural
+		field, l = &prev.PreviousPluralID, strings.TrimPrefix(l, "msgid_plural")
+	case strings.HasPrefix(l, "msgid"):
+		po.domain.previousState = msgID
+		field, l = &prev.PreviousID, strings.TrimPrefix(l, "msgid")
+	case strings.HasPrefix(l, "\""):
+		switch po.domain.previousState {
+		case msgCtxt:
+			field = &prev.PreviousContext
+		case msgID:
+			field = &prev.PreviousID
+		case msgIDPlural:
+			field = &prev.PreviousPluralID
+		}
+	}
+	if field == nil {
+		return
+	}
+	if clean, err := strconv.Unquote(strings.TrimSpace(l)); err == nil {
+		*field += clean
+	}
+}
+
+// parseObsolete keeps the obsolete entries, from lines starting with "#~".
+// Obsolete entries aren't used for lookups.
+func (po *Po) parseObsolete(l string) {
+	obsolete := po.domain.dataBuffer.obsolete
+	state := po.domain.obsoleteState
+
+	// Entry being parsed, the ones of the previous contents are left alone
+	var last *Translation
+	if len(obsolete) > 0 && state != head && state != msgCtxt {
+		last = obsolete[len(obsolete)-1].trans
+	}
+
+	switch {
+	case strings.HasPrefix(l, "msgctxt"):
+		po.domain.obsoleteCtx, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgctxt")))
+		po.domain.obsoleteState = msgCtxt
+
+	case strings.HasPrefix(l, "msgid_plural"):
+		if state == msgID {
+			last.PluralID, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid_plural")))
+		}
+		po.domain.obsoleteState = msgIDPlural
+
+	case strings.HasPrefix(l, "|"):
+		po.parsePrevious(strings.TrimSpace(l[1:]))
+		po.domain.obsoleteState = head
+
+	case strings.HasPrefix(l, "msgid"):
+		// Comments, flags and previous msgid lines before it belong to the obsolete entry
+		trans := NewTranslation()
+		if po.domain.refBuffer != "" {
+			trans.Refs = strings.Split(po.domain.refBuffer, " ")
+		}
+		trans.Flags = po.domain.flagBuffer
+		trans.ExtractedComments = po.domain.commentBuffer
+		prev := &po.domain.previousBuffer
+		trans.PreviousContext = prev.PreviousContext
+		trans.PreviousID = prev.PreviousID
+		trans.PreviousPluralID = prev.PreviousPluralID
+		*prev = Translation{}
+		po.domain.previousState = head
+		po.domain.refBuffer = ""
+		po.domain.flagBuffer = nil
+		po.domain.commentBuffer = nil
+
+		trans.ID, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid")))
+		po.domain.dataBuffer.obsolete = append(obsolete, obsoleteEntry{ctx: po.domain.obsoleteCtx, trans: trans})
+		po.domain.obsoleteCtx = ""
+		po.domain.obsoleteState = msgID
+
+	case strings.HasPrefix(l, "msgstr"):
+		po.domain.obsoleteState = head
+		if last == nil {
+			return
+		}
+		l = strings.TrimSpace(strings.TrimPrefix(l, "msgstr"))
+		form := 0
+		if strings.HasPrefix(l, "[") {
+			idx := strings.Index(l, "]")
+			if idx == -1 {
+				return
 			}
+			var err error
+			if form, err = strconv.Atoi(l[1:idx]); err != nil {
+				return
+			}
+			l = strings.TrimSpace(l[idx+1:])
+		}
+		last.Trs[form], _ = strconv.Unquote(l)
+		po.domain.obsoleteForm = form
+		po.domain.obsoleteState = msgStr
+
+	case strings.HasPrefix(l, "\""):
+		clean, _ := strconv.Unquote(l)
+		switch state {
+		case msgCtxt:
+			po.domain.obsoleteCtx += clean
+		case msgID:
+			last.ID += clean
+		case msgIDPlural:
+			last.PluralID += clean
+		case msgStr:
+			last.Trs[po.domain.obsoleteForm] += clean
 		}
+
+	default:
+		po.domain.obsoleteState = head
 	}
 }
 
 // parseContext takes a line starting with "msgctxt",
 // saves the current Translation buffer and creates a new context.
-func (po *Po) parseContext(l string) {
+func (po *Po) parseContext(l string) (err error) {
 	// Save current Translation buffer.
 	po.saveBuffer()
 
 	// Buffer context
-	po.domain.ctxBuffer, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgctxt")))
+	po.domain.ctxBuffer, err = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgctxt")))
+	return err
 }
 
 // parseID takes a line starting with "msgid",
 // saves the current Translation and creates a new msgid buffer.
-func (po *Po) parseID(l string) {
+func (po *Po) parseID(l string) (err error) {
 	// Save current Translation buffer.
 	po.saveBuffer()
 
+	// References, flags and comments precede the entry they apply to
+	if po.domain.refBuffer != "" {
+		po.domain.trBuffer.Refs = strings.Split(po.domain.refBuffer, " ")
+		po.domain.refBuffer = ""
+	}
+	po.domain.trBuffer.Flags = po.domain.flagBuffer
+	po.domain.flagBuffer = nil
+	po.domain.trBuffer.ExtractedComments = po.domain.commentBuffer
+	po.domain.commentBuffer = nil
+	prev := &po.domain.previousBuffer
+	po.domain.trBuffer.PreviousContext = prev.PreviousContext
+	po.domain.trBuffer.PreviousID = prev.PreviousID
+	po.domain.trBuffer.PreviousPluralID = prev.PreviousPluralID
+	*prev = Translation{}
+	po.domain.previousState = head
+
 	// Set id
-	po.domain.trBuffer.ID, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid")))
+	po.domain.trBuffer.ID, err = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid")))
+	return err
 }
 
 // parsePluralID saves the plural id buffer from a line starting with "msgid_plural"
-func (po *Po) parsePluralID(l string) {
-	po.domain.trBuffer.PluralID, _ = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid_plural")))
+func (po *Po) parsePluralID(l string) (err error) {
+	po.domain.trBuffer.PluralID, err = strconv.Unquote(strings.TrimSpace(strings.TrimPrefix(l, "msgid_plural")))
+	return err
 }
 
 // parseMessage takes a line starting with "msgstr" and saves it into the current buffer.
-func (po *Po) parseMessage(l string) {
+func (po *Po) parseMessage(l string) (err error) {
 	l = strings.TrimSpace(strings.TrimPrefix(l, "msgstr"))
 
 	// Check for indexed Translation forms
@@ -360,31 +601,32 @@ func (po *Po) parseMessage(l string) {
 		idx := strings.Index(l, "]")
 		if idx == -1 {
 			// Skip wrong index formatting
-			return
+			return errors.New("missing closing bracket in msgstr index")
 		}
 
 		// Parse index
 		i, err := strconv.Atoi(l[1:idx])
 		if err != nil {
 			// Skip wrong index formatting
-			return
+			return fmt.Errorf("invalid msgstr index: %v", err)
 		}
 
 		// Parse Translation string
-		po.domain.trBuffer.Trs[i], _ = strconv.Unquote(strings.TrimSpace(l[idx+1:]))
+		po.domain.trBuffer.Trs[i], err = strconv.Unquote(strings.TrimSpace(l[idx+1:]))
 
 		// Loop
-		return
+		return err
 	}
 
 	// Save single Translation form under 0 index
-	po.domain.trBuffer.Trs[0], _ = strconv.Unquote(l)
+	po.domain.trBuffer.Trs[0], err = strconv.Unquote(l)
+	return err
 }
 
 // parseString takes a well formatted string without prefix
 // and creates headers or attach multi-line strings when corresponding
-func (po *Po) parseString(l string, state parseState) {
-	clean, _ := strconv.Unquote(l)
+func (po *Po) parseString(l string, state parseState) error {
+	clean, err := strconv.Unquote(l)
 
 	switch state {
 	case msgStr:
@@ -404,6 +646,8 @@ func (po *Po) parseString(l string, state parseState) {
 		po.domain.ctxBuffer += clean
 
 	}
+
+	return err
 }
 
 // isValidLine checks for line prefixes to detect valid syntax.
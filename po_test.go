@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 	"testing"
 )
 
@@ -797,3 +798,111 @@ func TestPo_MissingWrappers(t *testing.T) {
 	}
 	}
 
+
+func TestPoFlags(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+
+#: main.go:10
+#, fuzzy, c-format
+msgid "Hello %s"
+msgstr "Hola %s"
+
+msgid "Bye"
+msgstr "Adiós"
+`))
+
+	text, err := po.MarshalText()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if !strings.Contains(string(text), "#: main.go:10\n#, fuzzy, c-format\nmsgid \"Hello %s\"") {
+		t.Errorf("Expected flags to be written after references, got:\n%s", text)
+	}
+
+	po2 := NewPo()
+	po2.Parse(text)
+	if flags := po2.GetDomain().load().translations["Hello %s"].Flags; len(flags) != 2 || flags[0] != "fuzzy" || flags[1] != "c-format" {
+		t.Errorf("Expected flags [fuzzy c-format] but got %v", flags)
+	}
+	if flags := po2.GetDomain().load().translations["Bye"].Flags; len(flags) != 0 {
+		t.Errorf("Expected no flags but got %v", flags)
+	}
+}
+
+func TestParseCatalogFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		file := path.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	tr, err := ParseCatalogFile(write("ok.po", "msgid \"My text\"\nmsgstr \"Translated text\"\n"))
+	if err != nil || tr.Get("My text") != "Translated text" {
+		t.Errorf("Expected the catalog to be parsed, got error %v", err)
+	}
+
+	// Malformed lines are reported, the other entries still loaded
+	tr, err = ParseCatalogFile(write("bad.po", "msgid \"My text\"\nmsgstr \"Translated text\"\n\nmsgid \"Other\"\nmsgstr \"unterminated\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 5") {
+		t.Errorf("Expected an error on line 5, got %v", err)
+	}
+	if tr == nil || tr.Get("My text") != "Translated text" {
+		t.Error("Expected the entries before the malformed line to be loaded")
+	}
+
+	if _, err := ParseCatalogFile(write("bad.mo", "garbage")); err == nil {
+		t.Error("Expected an error for an invalid MO file")
+	}
+	if _, err := ParseCatalogFile(path.Join(dir, "missing.po")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestPo_ParseTwice(t *testing.T) {
+	text := []byte(`# Header comment
+msgid ""
+msgstr ""
+"Language: es\n"
+
+msgid "Hello"
+msgstr "Hola"
+
+msgid "Hello"
+msgstr "Hola otra vez"
+
+#~ msgid "Bye"
+#~ msgstr "Adiós"
+`)
+
+	po := NewPo()
+	po.Parse(text)
+	once, err := po.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := len(po.GetDomain().Lint())
+
+	// Reloading the same file
+	po.Parse(text)
+	twice, err := po.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(twice) != string(once) {
+		t.Errorf("Parsing twice changed the contents from:\n%s\n\nto:\n%s", once, twice)
+	}
+	if got := strings.Count(string(twice), "#~ msgid \"Bye\""); got != 1 {
+		t.Errorf("Expected the obsolete entry once, got %d times", got)
+	}
+	if got := len(po.GetDomain().Lint()); got != findings {
+		t.Errorf("Expected %d lint findings, got %d", findings, got)
+	}
+	if got := po.GetDomain().Stats().Obsolete.Entries; got != 1 {
+		t.Errorf("Expected 1 obsolete entry, got %d", got)
+	}
+}
@@ -0,0 +1,105 @@
+package gotext
+
+// MissBehavior tells what lookups return for strings without a translation.
+type MissBehavior int
+
+const (
+	// MissReturnMsgID returns the untranslated string received, formatted with the variables given. It's the default.
+	MissReturnMsgID MissBehavior = iota
+
+	// MissReturnEmpty returns an empty string.
+	MissReturnEmpty
+
+	// MissReturnSentinel returns the LookupPolicy Sentinel string, without formatting it.
+	MissReturnSentinel
+)
+
+/*
+LookupPolicy configures how a Domain resolves lookups that don't find a usable translation.
+The zero value keeps the default behaviour: context lookups don't fall back, fuzzy entries are used,
+misses return the msgid and untranslated plurals use the English rule.
+
+Example:
+
+	po.GetDomain().SetLookupPolicy(gotext.LookupPolicy{
+		ContextFallback: true,
+		SkipFuzzy:       true,
+		Miss:            gotext.MissReturnSentinel,
+		Sentinel:        "???",
+	})
+*/
+type LookupPolicy struct {
+	// ContextFallback makes context lookups (GetC, GetNC...) use the entry without context
+	// for the same msgid when the context one isn't translated.
+	ContextFallback bool
+
+	// SkipFuzzy ignores translations flagged as fuzzy, like msgfmt does.
+	SkipFuzzy bool
+
+	// Miss sets what's returned for strings without translation.
+	Miss MissBehavior
+
+	// Sentinel is returned for strings without translation when Miss is MissReturnSentinel.
+	Sentinel string
+
+	// SourcePluralForm chooses between the singular (0) and plural (1) strings received for untranslated plural messages,
+	// following the plural rule of the source language. When nil, n == 1 and n == -1 are singular.
+	SourcePluralForm func(n int) int
+
+	// CheckFormat makes Get* lookups called with variables return the source string, formatted, instead of
+	// translations the variables can't be formatted with (see CheckArgs), which would print "%!d(MISSING)" or similar.
+	CheckFormat bool
+
+	// OnFormatMismatch, when set, is called with the msgid and translation rejected by CheckFormat, and why.
+	OnFormatMismatch func(msgid, translation string, err error)
+}
+
+// miss returns the string to use when str has no translation
+func (p *LookupPolicy) miss(str string) string {
+	switch p.Miss {
+	case MissReturnEmpty:
+		return ""
+	case MissReturnSentinel:
+		return p.Sentinel
+	}
+	return str
+}
+
+// missN returns the string to use when a plural message has no translation.
+func (p *LookupPolicy) missN(str, plural string, n int) string {
+	return p.miss(p.sourceN(str, plural, n))
+}
+
+// sourceN returns the singular or plural string received for n, following the plural rule of the source
+// language and never the one of the catalog.
+func (p *LookupPolicy) sourceN(str, plural string, n int) string {
+	form := englishPluralForm(n)
+	if p.SourcePluralForm != nil {
+		form = p.SourcePluralForm(n)
+	}
+	if form == 0 {
+		return str
+	}
+	return plural
+}
+
+// formatMiss reports whether the string returned for a miss must be formatted with the variables given
+func (p *LookupPolicy) formatMiss() bool {
+	return p.Miss == MissReturnMsgID
+}
+
+// missFormat formats the string returned for a miss with vars, when the policy says so
+func (p *LookupPolicy) missFormat(str string, vars []interface{}) string {
+	if !p.formatMiss() {
+		return str
+	}
+	return FormatString(str, vars...)
+}
+
+// englishPluralForm is the plural rule used when nothing better is known
+func englishPluralForm(n int) int {
+	if n == 1 || n == -1 {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,204 @@
+package gotext
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// StatsCount counts catalog entries, and the words and characters of their msgid and msgid_plural.
+type StatsCount struct {
+	Entries int `json:"entries"`
+
+	// Entries with a msgid_plural, included in Entries
+	Plurals int `json:"plurals"`
+
+	Words int `json:"words"`
+	Chars int `json:"chars"`
+}
+
+func (c *StatsCount) add(o StatsCount) {
+	c.Entries += o.Entries
+	c.Plurals += o.Plurals
+	c.Words += o.Words
+	c.Chars += o.Chars
+}
+
+// count adds the entry of trans
+func (c *StatsCount) count(trans *Translation) {
+	c.Entries++
+	if trans.PluralID != "" {
+		c.Plurals++
+	}
+	for _, s := range []string{trans.ID, trans.PluralID} {
+		c.Words += len(strings.Fields(s))
+		c.Chars += utf8.RuneCountInString(s)
+	}
+}
+
+// Stats tells how complete a catalog is. Entries are fuzzy when flagged so and translated,
+// translated when they have every form, and untranslated otherwise. Obsolete entries,
+// the "#~" ones of PO files, aren't part of the other counts.
+type Stats struct {
+	Translated   StatsCount `json:"translated"`
+	Fuzzy        StatsCount `json:"fuzzy"`
+	Untranslated StatsCount `json:"untranslated"`
+	Obsolete     StatsCount `json:"obsolete"`
+}
+
+// Total returns the counts of translated, fuzzy and untranslated entries together.
+func (s Stats) Total() StatsCount {
+	var t StatsCount
+	t.add(s.Translated)
+	t.add(s.Fuzzy)
+	t.add(s.Untranslated)
+	return t
+}
+
+// Completion returns the percentage of entries translated, 0 when there are none.
+func (s Stats) Completion() float64 {
+	total := s.Total().Entries
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Translated.Entries) * 100 / float64(total)
+}
+
+// Add adds the counts of o to s.
+func (s *Stats) Add(o Stats) {
+	s.Translated.add(o.Translated)
+	s.Fuzzy.add(o.Fuzzy)
+	s.Untranslated.add(o.Untranslated)
+	s.Obsolete.add(o.Obsolete)
+}
+
+// Stats counts the entries of the domain, excluding the header.
+func (do *Domain) Stats() Stats {
+	d := do.load()
+
+	var s Stats
+	count := func(trans *Translation) {
+		// Header, and empty entries left by msgctxt lines
+		if trans.ID == "" {
+			return
+		}
+
+		complete, partial := true, false
+		forms := 1
+		if trans.PluralID != "" {
+			forms = d.numForms(trans)
+		}
+		for form := 0; form < forms; form++ {
+			if trans.Trs[form] == "" {
+				complete = false
+			} else {
+				partial = true
+			}
+		}
+
+		switch {
+		case trans.IsFuzzy() && partial:
+			s.Fuzzy.count(trans)
+		case complete:
+			s.Translated.count(trans)
+		default:
+			s.Untranslated.count(trans)
+		}
+	}
+
+	for _, trans := range d.translations {
+		count(trans)
+	}
+	for _, translations := range d.contextTranslations {
+		for _, trans := range translations {
+			count(trans)
+		}
+	}
+	for _, entry := range d.obsolete {
+		s.Obsolete.count(entry.trans)
+	}
+	return s
+}
+
+// Stats adds up the stats of the domains of the locale.
+func (l *Locale) Stats() Stats {
+	l.RLock()
+	defer l.RUnlock()
+
+	var s Stats
+	for _, tr := range l.Domains {
+		if d := tr.GetDomain(); d != nil {
+			s.Add(d.Stats())
+		}
+	}
+	return s
+}
+
+// LibraryStats returns the stats of every catalog in a library directory, by language and domain,
+// like the Locale objects of each language would load them: from lib/<lang>/LC_MESSAGES/<domain>.{po,mo}
+// or lib/<lang>/<domain>.{po,mo}, the .po file being used when both exist.
+// Catalogs that can't be read or have malformed lines are reported in the error, joining one error per file
+// named relative to lib, along with the stats of the entries that could be parsed.
+func LibraryStats(lib string) (map[string]map[string]Stats, error) {
+	return libraryStats(os.DirFS(lib))
+}
+
+func libraryStats(fsys fs.FS) (map[string]map[string]Stats, error) {
+	langs, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]map[string]Stats)
+	var errs []error
+	for _, lang := range langs {
+		if !lang.IsDir() || strings.HasPrefix(lang.Name(), ".") {
+			continue
+		}
+		for _, dir := range []string{path.Join(lang.Name(), "LC_MESSAGES"), lang.Name()} {
+			files, err := fs.ReadDir(fsys, dir)
+			if err != nil {
+				continue
+			}
+			for _, f := range files {
+				ext := path.Ext(f.Name())
+				dom := strings.TrimSuffix(f.Name(), ext)
+				if f.IsDir() || ext != ".po" && ext != ".mo" {
+					continue
+				}
+				if _, ok := stats[lang.Name()][dom]; ok {
+					continue
+				}
+				if ext == ".mo" && fileExists(fsys, path.Join(dir, dom+".po")) {
+					continue
+				}
+
+				name := path.Join(dir, f.Name())
+				data, err := fs.ReadFile(fsys, name)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				tr, err := parseCatalog(name, data, fsys)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %v", name, err))
+				}
+
+				if stats[lang.Name()] == nil {
+					stats[lang.Name()] = make(map[string]Stats)
+				}
+				stats[lang.Name()][dom] = tr.GetDomain().Stats()
+			}
+		}
+	}
+	return stats, errors.Join(errs...)
+}
+
+func fileExists(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
@@ -0,0 +1,136 @@
+package gotext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const statsPo = `msgid ""
+msgstr ""
+"Language: pl\n"
+"Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "Open file"
+msgstr "Otwórz plik"
+
+#, fuzzy
+msgid "Save"
+msgstr "Zapisz"
+
+msgid "Close"
+msgstr ""
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Jeden plik"
+msgstr[1] "%d pliki"
+msgstr[2] "%d plików"
+
+msgid "One dir"
+msgid_plural "%d dirs"
+msgstr[0] "Jeden katalog"
+msgstr[1] "%d katalogi"
+
+msgctxt "menu"
+msgid "Quit"
+msgstr ""
+
+#~ msgid "Old one"
+#~ msgstr "Stary"
+
+#~ msgid ""
+#~ "Old "
+#~ "plural"
+#~ msgid_plural "Old plurals"
+#~ msgstr[0] "Stary"
+`
+
+func TestDomain_Stats(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(statsPo))
+
+	s := po.GetDomain().Stats()
+	want := Stats{
+		Translated:   StatsCount{Entries: 2, Plurals: 1, Words: 6, Chars: 25},
+		Fuzzy:        StatsCount{Entries: 1, Words: 1, Chars: 4},
+		Untranslated: StatsCount{Entries: 3, Plurals: 1, Words: 6, Chars: 23},
+		Obsolete:     StatsCount{Entries: 2, Plurals: 1, Words: 6, Chars: 28},
+	}
+	if s != want {
+		t.Errorf("Expected %+v, got %+v", want, s)
+	}
+
+	if total := s.Total(); total.Entries != 6 || total.Plurals != 2 {
+		t.Errorf("Unexpected total %+v", total)
+	}
+	if c := s.Completion(); c < 33.3 || c > 33.4 {
+		t.Errorf("Expected 33.3%% completion, got %f", c)
+	}
+	if c := (Stats{}).Completion(); c != 0 {
+		t.Errorf("Expected empty catalogs to be untranslated, got %f", c)
+	}
+}
+
+func TestLocale_Stats(t *testing.T) {
+	l := NewLocale("fixtures/", "de_DE")
+	l.AddDomain("default")
+
+	po := NewPo()
+	po.ParseFile("fixtures/de_DE/LC_MESSAGES/default.po")
+	want := po.GetDomain().Stats()
+	if s := l.Stats(); s != want || s.Translated.Entries == 0 {
+		t.Errorf("Expected %+v, got %+v", want, s)
+	}
+}
+
+func TestLibraryStats(t *testing.T) {
+	stats, err := LibraryStats("fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lang := range []string{"ar", "de", "de_DE", "en_AU", "en_GB", "en_US", "fr"} {
+		if _, ok := stats[lang]; !ok {
+			t.Errorf("Expected stats for %s", lang)
+		}
+	}
+	if _, ok := stats["ar"]["categories"]; !ok {
+		t.Errorf("Expected stats for the categories domain of ar, got %v", stats["ar"])
+	}
+
+	// The .po file is preferred, it has the fuzzy entries
+	po := NewPo()
+	po.ParseFile("fixtures/de/default.po")
+	if s := stats["de"]["default"]; s != po.GetDomain().Stats() {
+		t.Errorf("Expected the stats of default.po, got %+v", s)
+	}
+	if s := stats["en_GB"]["default"]; s.Translated.Entries == 0 {
+		t.Errorf("Expected the stats of default.mo, got %+v", s)
+	}
+
+	if _, err = LibraryStats("fixtures/missing"); err == nil {
+		t.Error("Expected missing directories to fail")
+	}
+}
+
+func TestLibraryStats_Malformed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"es/LC_MESSAGES/default.po": {Data: []byte("msgid \"Hello\"\nmsgstr \"Hola\"\n\nmsgid \"Bye\n")},
+		"fr/LC_MESSAGES/default.po": {Data: []byte("msgid \"Hello\"\nmsgstr \"Bonjour\"\n")},
+	}
+	stats, err := libraryStats(fsys)
+	if err == nil || !strings.Contains(err.Error(), "es/LC_MESSAGES/default.po") {
+		t.Errorf("Expected an error for es/LC_MESSAGES/default.po, got %v", err)
+	}
+	if strings.Contains(fmt.Sprint(err), "fr/") {
+		t.Errorf("Unexpected error for fr/LC_MESSAGES/default.po: %v", err)
+	}
+	if s := stats["es"]["default"]; s.Translated.Entries != 1 {
+		t.Errorf("Expected the stats of the entries parsed, got %+v", s)
+	}
+	if s := stats["fr"]["default"]; s.Translated.Entries != 1 {
+		t.Errorf("Expected the stats of fr, got %+v", s)
+	}
+}
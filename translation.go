@@ -11,8 +11,20 @@ type Translation struct {
 	PluralID string
 	Trs      map[int]string
 	Refs     []string
+	Flags    []string
+
+	// Comments for translators extracted from the sources, the "#." lines of PO files
+	ExtractedComments []string
+
+	// Context, msgid and msgid_plural of the entry a fuzzy translation was made for, the "#|" lines of PO files
+	PreviousContext  string
+	PreviousID       string
+	PreviousPluralID string
 
 	dirty bool
+
+	// Line of the msgid in the parsed PO file, 0 if unknown
+	line int
 }
 
 // NewTranslation returns the Translation object and initialized it.
@@ -30,6 +42,49 @@ func NewTranslationWithRefs(refs []string) *Translation {
 	}
 }
 
+// clone returns a deep copy of the translation
+func (t *Translation) clone() *Translation {
+	c := NewTranslation()
+	c.ID = t.ID
+	c.PluralID = t.PluralID
+	c.PreviousContext = t.PreviousContext
+	c.PreviousID = t.PreviousID
+	c.PreviousPluralID = t.PreviousPluralID
+	c.dirty = t.dirty
+	c.line = t.line
+	if len(t.Refs) > 0 {
+		c.Refs = make([]string, len(t.Refs))
+		copy(c.Refs, t.Refs)
+	}
+	if len(t.Flags) > 0 {
+		c.Flags = make([]string, len(t.Flags))
+		copy(c.Flags, t.Flags)
+	}
+	if len(t.ExtractedComments) > 0 {
+		c.ExtractedComments = make([]string, len(t.ExtractedComments))
+		copy(c.ExtractedComments, t.ExtractedComments)
+	}
+	for k, v := range t.Trs {
+		c.Trs[k] = v
+	}
+	return c
+}
+
+// HasFlag reports whether the translation has the given flag, like "fuzzy" or "c-format"
+func (t *Translation) HasFlag(flag string) bool {
+	for _, f := range t.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFuzzy reports whether the translation is flagged as fuzzy
+func (t *Translation) IsFuzzy() bool {
+	return t.HasFlag("fuzzy")
+}
+
 // IsStale returns whether the translation is stale or not
 func (t *Translation) IsStale() bool {
 	return !t.dirty
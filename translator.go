@@ -9,6 +9,7 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"strings"
 )
 
 // Translator interface is used by Locale and Po objects.Translator
@@ -37,6 +38,17 @@ type AppendTranslator interface {
 	AppendNC(b []byte, str, plural string, n int, ctx string, vars ...interface{}) []byte
 }
 
+// LookupTranslator interface is implemented by Translators able to report whether a translation was found,
+// instead of returning the untranslated string. Po and Mo objects implement it.
+// Lookups return unformatted strings, and plural lookups also return the plural form index used.
+type LookupTranslator interface {
+	Translator
+	Lookup(str string) (string, bool)
+	LookupN(str, plural string, n int) (string, int, bool)
+	LookupC(str, ctx string) (string, bool)
+	LookupNC(str, plural string, n int, ctx string) (string, int, bool)
+}
+
 // TranslatorEncoding is used as intermediary storage to encode Translator objects to Gob.
 type TranslatorEncoding struct {
 	// Headers storage
@@ -67,14 +79,38 @@ func (te *TranslatorEncoding) GetTranslator() Translator {
 	po.domain.Headers = te.Headers
 	po.domain.Language = te.Language
 	po.domain.PluralForms = te.PluralForms
-	po.domain.nplurals = te.Nplurals
-	po.domain.plural = te.Plural
-	po.domain.translations = te.Translations
-	po.domain.contextTranslations = te.Contexts
+	po.domain.setData(te.Nplurals, te.Plural, te.Translations, te.Contexts)
 
 	return po
 }
 
+// ParseCatalogFile parses the .po or .mo file at path into a new Translator, choosing the format by extension.
+// Unlike ParseFile, it reports files that can't be read or loaded, and the first malformed line of a .po file,
+// whose other entries are loaded anyway.
+func ParseCatalogFile(path string) (Translator, error) {
+	data, err := getFileData(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseCatalog(path, data, nil)
+}
+
+// ParseCatalog parses data, the contents of the .po or .mo file at path, into a new Translator,
+// reporting errors like ParseCatalogFile does. The path only chooses the format, by extension.
+func ParseCatalog(path string, data []byte) (Translator, error) {
+	return parseCatalog(path, data, nil)
+}
+
+// parseCatalog parses the contents of the catalog file at path, choosing the format by extension.
+func parseCatalog(path string, data []byte, filesystem fs.FS) (Translator, error) {
+	if strings.HasSuffix(path, ".mo") {
+		mo := NewMoFS(filesystem)
+		return mo, mo.parse(data)
+	}
+	po := NewPoFS(filesystem)
+	return po, po.parse(data)
+}
+
 // getFileData reads a file and returns the byte slice after doing some basic sanity checking
 func getFileData(f string, filesystem fs.FS) ([]byte, error) {
 	if filesystem != nil {
@@ -0,0 +1,366 @@
+package gotext
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatVerb is a verb of a fmt.Printf format string, with the argument it formats, counting from 1.
+// Arguments used for width or precision, as in "%*d", have the verb '*'.
+type FormatVerb struct {
+	Arg  int
+	Verb rune
+}
+
+// ParseFormat returns the verbs of a fmt.Printf format string, in order, following the rules of fmt
+// for explicit argument indexes like "%[2]s". Flags, width and precision aren't kept, and "%%" isn't a verb.
+// Malformed verbs, which fmt would print as "%!(NOVERB)" or "%!d(BADINDEX)", return an error.
+func ParseFormat(format string) ([]FormatVerb, error) {
+	verbs, _, err := parseFormat(format)
+	return verbs, err
+}
+
+// parseFormat is ParseFormat, also reporting whether explicit argument indexes were used
+func parseFormat(format string) (verbs []FormatVerb, reordered bool, err error) {
+	argNum := 0
+	end := len(format)
+
+	for i := 0; i < end; {
+		if format[i] != '%' {
+			i++
+			continue
+		}
+		start := i
+		i++
+
+		// Flags
+		for i < end && strings.IndexByte("#0+- ", format[i]) >= 0 {
+			i++
+		}
+
+		// Argument index, then width
+		if i, argNum, err = parseArgIndex(format, start, i, argNum); err != nil {
+			return nil, false, err
+		}
+		if i < end && format[i] == '*' {
+			verbs = append(verbs, FormatVerb{Arg: argNum + 1, Verb: '*'})
+			argNum++
+			i++
+		} else {
+			for i < end && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+
+		// Precision, which may have its own argument index
+		if i < end && format[i] == '.' {
+			i++
+			if i, argNum, err = parseArgIndex(format, start, i, argNum); err != nil {
+				return nil, false, err
+			}
+			if i < end && format[i] == '*' {
+				verbs = append(verbs, FormatVerb{Arg: argNum + 1, Verb: '*'})
+				argNum++
+				i++
+			} else {
+				for i < end && format[i] >= '0' && format[i] <= '9' {
+					i++
+				}
+			}
+		}
+
+		// Argument index of the verb
+		if i, argNum, err = parseArgIndex(format, start, i, argNum); err != nil {
+			return nil, false, err
+		}
+		if strings.IndexByte(format[start:i], '[') >= 0 {
+			reordered = true
+		}
+
+		if i >= end {
+			return nil, false, fmt.Errorf("%q at %d has no verb", format[start:], start)
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		if verb == '%' {
+			continue
+		}
+		verbs = append(verbs, FormatVerb{Arg: argNum + 1, Verb: verb})
+		argNum++
+	}
+	return verbs, reordered, nil
+}
+
+// parseArgIndex parses an explicit argument index like "[2]" at i, returning the position after it
+// and the argument to use next, counting from 0
+func parseArgIndex(format string, start, i, argNum int) (int, int, error) {
+	if i >= len(format) || format[i] != '[' {
+		return i, argNum, nil
+	}
+	closing := strings.IndexByte(format[i:], ']')
+	if closing < 0 {
+		return 0, 0, fmt.Errorf("%q at %d has an unclosed argument index", format[start:], start)
+	}
+	n, err := strconv.Atoi(format[i+1 : i+closing])
+	if err != nil || n < 1 {
+		return 0, 0, fmt.Errorf("%q at %d has a bad argument index", format[start:i+closing+1], start)
+	}
+	return i + closing + 1, n - 1, nil
+}
+
+// formatArgs returns the verbs used for each argument, sorted
+func formatArgs(verbs []FormatVerb) map[int]string {
+	args := make(map[int]string)
+	for _, v := range verbs {
+		if !strings.ContainsRune(args[v.Arg], v.Verb) {
+			args[v.Arg] += string(v.Verb)
+		}
+	}
+	for arg, vs := range args {
+		r := []rune(vs)
+		sort.Slice(r, func(i, j int) bool { return r[i] < r[j] })
+		args[arg] = string(r)
+	}
+	return args
+}
+
+// CheckFormat checks that the translation msgstr of msgid formats the same arguments with the same verbs,
+// ignoring flags, width, precision and order. A translation may use %v for any verb of msgid.
+func CheckFormat(msgid, msgstr string) error {
+	idVerbs, _, err := parseFormat(msgid)
+	if err != nil {
+		return fmt.Errorf("msgid: %w", err)
+	}
+	strVerbs, _, err := parseFormat(msgstr)
+	if err != nil {
+		return err
+	}
+
+	want, got := formatArgs(idVerbs), formatArgs(strVerbs)
+	args := make([]int, 0, len(want)+len(got))
+	for arg := range want {
+		args = append(args, arg)
+	}
+	for arg := range got {
+		if _, ok := want[arg]; !ok {
+			args = append(args, arg)
+		}
+	}
+	sort.Ints(args)
+
+	for _, arg := range args {
+		w, g := want[arg], got[arg]
+		switch {
+		case w == "":
+			return fmt.Errorf("argument %d isn't used by msgid", arg)
+		case g == "":
+			return fmt.Errorf("argument %d isn't used", arg)
+		case g != w && !(g == "v" && !strings.ContainsRune(w, '*')):
+			return fmt.Errorf("argument %d formatted with %s instead of %s", arg, verbList(g), verbList(w))
+		}
+	}
+	return nil
+}
+
+func verbList(verbs string) string {
+	l := make([]string, 0, len(verbs))
+	for _, v := range verbs {
+		l = append(l, "%"+string(v))
+	}
+	return strings.Join(l, ", ")
+}
+
+// CheckArgs checks that format can be formatted with args by fmt.Sprintf without errors like
+// "%!d(MISSING)", "%!(EXTRA int=1)" or "%!d(string=x)". Arguments that are slices, arrays, maps
+// or structs, or implement fmt.Formatter, are accepted with any verb.
+func CheckArgs(format string, args ...interface{}) error {
+	verbs, reordered, err := parseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	used := 0
+	for _, v := range verbs {
+		if v.Arg > len(args) {
+			return fmt.Errorf("%%%c has no argument %d", v.Verb, v.Arg)
+		}
+		if !verbAccepts(v.Verb, args[v.Arg-1]) {
+			return fmt.Errorf("%%%c can't format argument %d of type %T", v.Verb, v.Arg, args[v.Arg-1])
+		}
+		used = v.Arg
+	}
+	if !reordered && used < len(args) {
+		return errors.New("extra arguments")
+	}
+	return nil
+}
+
+// verbAccepts reports whether fmt formats arg with verb without an error
+func verbAccepts(verb rune, arg interface{}) bool {
+	if verb == 'v' || verb == 'T' {
+		return true
+	}
+	if arg == nil {
+		return false
+	}
+
+	if verb == '*' {
+		switch reflect.ValueOf(arg).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return true
+		}
+		return false
+	}
+
+	switch arg.(type) {
+	case fmt.Formatter:
+		return true
+	case error, fmt.Stringer:
+		if strings.ContainsRune("sqxX", verb) {
+			return true
+		}
+	}
+
+	var accepted string
+	switch reflect.ValueOf(arg).Kind() {
+	case reflect.Bool:
+		accepted = "t"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		accepted = "bcdoOqxXU"
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		accepted = "beEfFgGxX"
+	case reflect.String:
+		accepted = "sqxX"
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Chan, reflect.Func:
+		accepted = "pbdoxX"
+	default:
+		// Formatted element by element
+		return true
+	}
+	return strings.ContainsRune(accepted, verb)
+}
+
+// FormatError is a translation whose format verbs don't match the ones of its msgid, as found by Domain.CheckFormats.
+type FormatError struct {
+	Context string
+	MsgID   string
+
+	// Plural form of the translation, 0 for singular entries
+	Form   int
+	MsgStr string
+
+	Err error
+}
+
+func (e *FormatError) Error() string {
+	s := fmt.Sprintf("msgid %q", e.MsgID)
+	if e.Context != "" {
+		s = fmt.Sprintf("msgctxt %q %s", e.Context, s)
+	}
+	return fmt.Sprintf("%s: msgstr[%d] %q: %v", s, e.Form, e.MsgStr, e.Err)
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// CheckFormats checks the translations of the domain with CheckFormat. Plural forms may match
+// the msgid or the msgid_plural. Entries flagged "no-c-format" or "no-go-format" are skipped,
+// and so are entries without verbs in their msgid unless flagged "c-format" or "go-format".
+// Errors are sorted by context and msgid.
+func (do *Domain) CheckFormats() []*FormatError {
+	d := do.load()
+
+	var errs []*FormatError
+	errs = appendFormatErrors(errs, "", d.translations)
+
+	for _, ctx := range sortedContexts(d.contextTranslations) {
+		errs = appendFormatErrors(errs, ctx, d.contextTranslations[ctx])
+	}
+	return errs
+}
+
+func appendFormatErrors(errs []*FormatError, ctx string, translations map[string]*Translation) []*FormatError {
+	for _, id := range sortedIDs(translations) {
+		if id != "" {
+			errs = append(errs, translationFormatErrors(ctx, translations[id])...)
+		}
+	}
+	return errs
+}
+
+// translationFormatErrors checks the forms of trans with CheckFormat, as CheckFormats does
+func translationFormatErrors(ctx string, trans *Translation) []*FormatError {
+	if !isFormatString(trans) {
+		return nil
+	}
+
+	var errs []*FormatError
+	for _, form := range sortedForms(trans) {
+		str := trans.Trs[form]
+		if str == "" {
+			continue
+		}
+		err := CheckFormat(trans.ID, str)
+		if err != nil && trans.PluralID != "" && CheckFormat(trans.PluralID, str) == nil {
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, &FormatError{Context: ctx, MsgID: trans.ID, Form: form, MsgStr: str, Err: err})
+		}
+	}
+	return errs
+}
+
+// isFormatString reports whether the translation is formatted with fmt.Printf, according to its flags or msgid
+func isFormatString(trans *Translation) bool {
+	if trans.HasFlag("no-c-format") || trans.HasFlag("no-go-format") {
+		return false
+	}
+	if trans.HasFlag("c-format") || trans.HasFlag("go-format") {
+		return true
+	}
+	for _, id := range []string{trans.ID, trans.PluralID} {
+		if verbs, err := ParseFormat(id); err == nil && len(verbs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedIDs returns the msgids of translations, sorted
+func sortedIDs(translations map[string]*Translation) []string {
+	ids := make([]string, 0, len(translations))
+	for id := range translations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sortedContexts returns the contexts of contextTranslations, sorted
+func sortedContexts(contextTranslations map[string]map[string]*Translation) []string {
+	contexts := make([]string, 0, len(contextTranslations))
+	for ctx := range contextTranslations {
+		contexts = append(contexts, ctx)
+	}
+	sort.Strings(contexts)
+	return contexts
+}
+
+// sortedForms returns the plural forms of trans, sorted
+func sortedForms(trans *Translation) []int {
+	forms := make([]int, 0, len(trans.Trs))
+	for form := range trans.Trs {
+		forms = append(forms, form)
+	}
+	sort.Ints(forms)
+	return forms
+}
@@ -0,0 +1,158 @@
+package gotext
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		verbs  []FormatVerb
+	}{
+		{"no verbs", nil},
+		{"100%% done", nil},
+		{"%d files in %s", []FormatVerb{{1, 'd'}, {2, 's'}}},
+		{"%-10.3f|%+#x|% d", []FormatVerb{{1, 'f'}, {2, 'x'}, {3, 'd'}}},
+		{"%[2]s %[1]d %s", []FormatVerb{{2, 's'}, {1, 'd'}, {2, 's'}}},
+		{"%*d %.*f", []FormatVerb{{1, '*'}, {2, 'd'}, {3, '*'}, {4, 'f'}}},
+		{"%[3]*.[2]*[1]f", []FormatVerb{{3, '*'}, {2, '*'}, {1, 'f'}}},
+		{"%s – %ä", []FormatVerb{{1, 's'}, {2, 'ä'}}},
+	}
+	for _, tt := range tests {
+		verbs, err := ParseFormat(tt.format)
+		if err != nil {
+			t.Errorf("ParseFormat(%q) failed: %v", tt.format, err)
+			continue
+		}
+		if !reflect.DeepEqual(verbs, tt.verbs) {
+			t.Errorf("ParseFormat(%q) = %v, want %v", tt.format, verbs, tt.verbs)
+		}
+	}
+
+	for _, format := range []string{"100%", "%-", "%[1d", "%[0]d", "%[x]d"} {
+		if _, err := ParseFormat(format); err == nil {
+			t.Errorf("ParseFormat(%q) didn't fail", format)
+		}
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	tests := []struct {
+		msgid, msgstr string
+		ok            bool
+	}{
+		{"Hello", "Hola", true},
+		{"Hello %s", "Hola %s", true},
+		{"%d files in %s", "%[2]s: %[1]d archivos", true},
+		{"%d files", "%5d archivos", true},
+		{"%d files", "%v archivos", true},
+		{"%d files", "%s archivos", false},
+		{"%d files in %s", "%d archivos", false},
+		{"%d files", "%d archivos en %s", false},
+		{"%d files", "archivos", false},
+		{"%d files", "%d archivos al 100%", false},
+		{"%*d", "%v", false},
+	}
+	for _, tt := range tests {
+		err := CheckFormat(tt.msgid, tt.msgstr)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckFormat(%q, %q) = %v", tt.msgid, tt.msgstr, err)
+		}
+	}
+}
+
+type formatterArg struct{}
+
+func (formatterArg) Format(f fmt.State, verb rune) {}
+
+func TestCheckArgs(t *testing.T) {
+	var nilPtr *int
+	tests := []struct {
+		format string
+		args   []interface{}
+		ok     bool
+	}{
+		{"Hello", nil, true},
+		{"%d files", []interface{}{3}, true},
+		{"%d files", []interface{}{"3"}, false},
+		{"%d files", nil, false},
+		{"files", []interface{}{3}, false},
+		{"%[1]d files", []interface{}{3, 4}, true},
+		{"%s %s", []interface{}{"a"}, false},
+		{"%s", []interface{}{errors.New("x")}, true},
+		{"%s", []interface{}{nil}, false},
+		{"%v", []interface{}{nil}, true},
+		{"%x", []interface{}{[]byte("x")}, true},
+		{"%d", []interface{}{[]int{1}}, true},
+		{"%.2f", []interface{}{1.5}, true},
+		{"%*d", []interface{}{5, 3}, true},
+		{"%*d", []interface{}{"5", 3}, false},
+		{"%p", []interface{}{nilPtr}, true},
+		{"%t", []interface{}{true}, true},
+		{"%d", []interface{}{formatterArg{}}, true},
+	}
+	for _, tt := range tests {
+		err := CheckArgs(tt.format, tt.args...)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckArgs(%q, %v) = %v", tt.format, tt.args, err)
+		}
+		// Sprintf agrees
+		if !tt.ok || len(tt.args) == 0 {
+			continue
+		}
+		if out := fmt.Sprintf(tt.format, tt.args...); strings.Contains(out, "%!") {
+			t.Errorf("CheckArgs(%q, %v) accepted %q", tt.format, tt.args, out)
+		}
+	}
+}
+
+func TestDomain_CheckFormats(t *testing.T) {
+	po := NewPo()
+	po.Parse([]byte(`msgid ""
+msgstr ""
+"Language: es\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "Hello %s"
+msgstr "Hola %d"
+
+msgid "100% sure"
+msgstr "100% seguro"
+
+#, no-go-format
+msgid "%d%% done"
+msgstr "%d %"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "Un archivo"
+msgstr[1] "%d archivos"
+
+msgid "%d item"
+msgid_plural "%d items"
+msgstr[0] "%d elemento"
+msgstr[1] "elementos"
+
+msgctxt "menu"
+msgid "Open %s"
+msgstr "Abrir"
+`))
+
+	errs := po.GetDomain().CheckFormats()
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %v", errs)
+	}
+	if errs[0].MsgID != "%d item" || errs[0].Form != 1 {
+		t.Errorf("Unexpected error %v", errs[0])
+	}
+	if errs[1].MsgID != "Hello %s" || errs[1].MsgStr != "Hola %d" {
+		t.Errorf("Unexpected error %v", errs[1])
+	}
+	if errs[2].Context != "menu" || errs[2].Error() != `msgctxt "menu" msgid "Open %s": msgstr[0] "Abrir": argument 1 isn't used` {
+		t.Errorf("Unexpected error %v", errs[2])
+	}
+}
@@ -0,0 +1,251 @@
+package gotext
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReloadEvent describes the outcome of reloading the catalog file of a domain.
+// When Err is not nil the file couldn't be parsed and the previous version of the domain is kept.
+type ReloadEvent struct {
+	Domain string
+	File   string
+	Err    error
+}
+
+/*
+Watcher polls the catalog files loaded by a Locale and reloads the ones that changed.
+Changed files are parsed in the background and swapped into the Locale atomically,
+so lookups never see a partially loaded domain.
+
+Example:
+
+	l := gotext.NewLocale("/path/to/i18n/dir", "en_US")
+	l.AddDomain("default")
+
+	w := l.Watch(5 * time.Second)
+	defer w.Stop()
+
+	w.OnReload(func(e gotext.ReloadEvent) {
+		if e.Err != nil {
+			log.Printf("keeping old %s: %v", e.Domain, e.Err)
+			return
+		}
+		cache.Purge()
+	})
+*/
+type Watcher struct {
+	locale   *Locale
+	interval time.Duration
+
+	// Last seen state of each watched file
+	stamps map[string]fileStamp
+
+	// Subscribers
+	callbacks []func(ReloadEvent)
+	channels  []chan ReloadEvent
+
+	// Sync Mutex
+	mu sync.Mutex
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// DefaultWatchInterval is the polling interval of Watch when the one given isn't positive
+const DefaultWatchInterval = 5 * time.Second
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch starts polling the files of every domain loaded with AddDomain, every interval,
+// or every DefaultWatchInterval if interval isn't positive.
+// Domains added after Watch is called are watched too.
+// Call Stop on the returned Watcher to release its goroutine.
+func (l *Locale) Watch(interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	w := &Watcher{
+		locale:   l,
+		interval: interval,
+		stamps:   make(map[string]fileStamp),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	// Files already loaded are the baseline
+	for _, file := range l.watchedFiles() {
+		if st, err := l.statFile(file); err == nil {
+			w.stamps[file] = st
+		}
+	}
+
+	go w.run()
+
+	return w
+}
+
+// OnReload registers a callback called after each reload attempt.
+// Callbacks are called from the watcher goroutine, one at a time, and must not call back into the Watcher.
+func (w *Watcher) OnReload(f func(ReloadEvent)) {
+	w.mu.Lock()
+	w.callbacks = append(w.callbacks, f)
+	w.mu.Unlock()
+}
+
+// Subscribe returns a channel receiving an event for each reload attempt.
+// Events are dropped when the channel buffer is full, so slow readers never block reloads.
+// The channel is closed by Stop.
+func (w *Watcher) Subscribe() <-chan ReloadEvent {
+	ch := make(chan ReloadEvent, 16)
+
+	w.mu.Lock()
+	w.channels = append(w.channels, ch)
+	w.mu.Unlock()
+
+	return ch
+}
+
+// Check polls the watched files once, reloading the changed ones before returning.
+func (w *Watcher) Check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for dom, file := range w.locale.watchedFiles() {
+		st, err := w.locale.statFile(file)
+		if err != nil {
+			// Probably being replaced, try again next time
+			continue
+		}
+
+		old, seen := w.stamps[file]
+		if seen && old == st {
+			continue
+		}
+		w.stamps[file] = st
+
+		// Added after Watch was called, so just loaded
+		if !seen {
+			continue
+		}
+
+		w.notify(ReloadEvent{
+			Domain: dom,
+			File:   file,
+			Err:    w.locale.reloadDomain(dom, file),
+		})
+	}
+}
+
+// Stop stops polling and closes the channels returned by Subscribe.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+
+		w.mu.Lock()
+		for _, ch := range w.channels {
+			close(ch)
+		}
+		w.channels = nil
+		w.mu.Unlock()
+	})
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// notify must be called with w.mu held
+func (w *Watcher) notify(e ReloadEvent) {
+	for _, f := range w.callbacks {
+		f(e)
+	}
+	for _, ch := range w.channels {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// watchedFiles returns a copy of the domain to file mapping of the Locale.
+func (l *Locale) watchedFiles() map[string]string {
+	l.RLock()
+	defer l.RUnlock()
+
+	files := make(map[string]string, len(l.files))
+	for dom, file := range l.files {
+		files[dom] = file
+	}
+	return files
+}
+
+func (l *Locale) statFile(filename string) (fileStamp, error) {
+	var info fs.FileInfo
+	var err error
+	if l.fs != nil {
+		info, err = fs.Stat(l.fs, filename)
+	} else {
+		info, err = os.Stat(filename)
+	}
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// reloadDomain parses file and replaces the domain with it, only if it parsed cleanly.
+func (l *Locale) reloadDomain(dom, file string) error {
+	tr, err := l.parseFile(file)
+	if err != nil {
+		return err
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	// Replaced by AddTranslator or AddDomain in the meantime
+	if l.files[dom] != file {
+		return nil
+	}
+	if d := tr.GetDomain(); d != nil {
+		d.SetLookupPolicy(l.policy)
+		d.setDefaultLanguage(l.lang)
+	}
+	l.Domains[dom] = tr
+
+	return nil
+}
+
+// parseFile parses a catalog file into a new Translator, choosing the format by extension.
+func (l *Locale) parseFile(file string) (Translator, error) {
+	data, err := getFileData(file, l.fs)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty catalog file")
+	}
+
+	return parseCatalog(file, data, l.fs)
+}
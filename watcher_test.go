@@ -0,0 +1,131 @@
+package gotext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeWatchedPo(t *testing.T, filename, translation string, mtime time.Time) {
+	t.Helper()
+
+	str := `
+msgid ""
+msgstr ""
+"Language: en\n"
+
+msgid "My text"
+msgstr "` + translation + `"
+`
+	if err := os.WriteFile(filename, []byte(str), 0644); err != nil {
+		t.Fatalf("Can't write test file: %s", err)
+	}
+	if err := os.Chtimes(filename, mtime, mtime); err != nil {
+		t.Fatalf("Can't touch test file: %s", err)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	dirname := filepath.Join(dir, "en", "LC_MESSAGES")
+	if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+		t.Fatalf("Can't create test directory: %s", err)
+	}
+	filename := filepath.Join(dirname, "watched.po")
+
+	now := time.Now()
+	writeWatchedPo(t, filename, "First version", now.Add(-time.Hour))
+
+	l := NewLocale(dir, "en_US")
+	l.AddDomain("watched")
+
+	w := l.Watch(time.Hour)
+	defer w.Stop()
+
+	var events []ReloadEvent
+	w.OnReload(func(e ReloadEvent) {
+		events = append(events, e)
+	})
+	ch := w.Subscribe()
+
+	// Nothing changed
+	w.Check()
+	if len(events) != 0 {
+		t.Fatalf("Expected no reload, got %v", events)
+	}
+
+	writeWatchedPo(t, filename, "Second version", now)
+	w.Check()
+
+	if len(events) != 1 || events[0].Domain != "watched" || events[0].Err != nil {
+		t.Fatalf("Expected one successful reload, got %v", events)
+	}
+	if e := <-ch; e.File != filename {
+		t.Errorf("Expected event for '%s' but got '%s'", filename, e.File)
+	}
+	if tr := l.GetD("watched", "My text"); tr != "Second version" {
+		t.Errorf("Expected 'Second version' but got '%s'", tr)
+	}
+
+	// Broken file keeps the previous version
+	if err := os.WriteFile(filename, []byte("msgid \"My text\nmsgstr \"Broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filename, now.Add(time.Hour), now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	w.Check()
+
+	if len(events) != 2 || events[1].Err == nil {
+		t.Fatalf("Expected a failed reload, got %v", events)
+	}
+	if tr := l.GetD("watched", "My text"); tr != "Second version" {
+		t.Errorf("Expected 'Second version' but got '%s'", tr)
+	}
+	if e := <-ch; e.Err == nil {
+		t.Error("Expected failed event on subscription channel")
+	}
+
+	w.Stop()
+	if _, ok := <-ch; ok {
+		t.Error("Expected subscription channel to be closed")
+	}
+}
+
+func TestWatcherIgnoresTranslators(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "en", "watched.po")
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	writeWatchedPo(t, filename, "From file", time.Now().Add(-time.Hour))
+
+	l := NewLocale(dir, "en")
+	l.AddDomain("watched")
+
+	w := l.Watch(time.Millisecond)
+	defer w.Stop()
+
+	po := NewPo()
+	po.Set("My text", "From memory")
+	l.AddTranslator("watched", po)
+
+	writeWatchedPo(t, filename, "From file again", time.Now())
+	w.Check()
+
+	if tr := l.GetD("watched", "My text"); tr != "From memory" {
+		t.Errorf("Expected 'From memory' but got '%s'", tr)
+	}
+}
+
+func TestWatcherDefaultInterval(t *testing.T) {
+	l := NewLocale(t.TempDir(), "en")
+	for _, interval := range []time.Duration{0, -time.Second} {
+		w := l.Watch(interval)
+		if w.interval != DefaultWatchInterval {
+			t.Errorf("Expected the default interval for %v, got %v", interval, w.interval)
+		}
+		w.Stop()
+	}
+}